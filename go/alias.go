@@ -0,0 +1,54 @@
+package totoon
+
+// WithColumnAliases shows short labels in table headers in place of long
+// field names, such as "qty" for "quantity_ordered_units". Only headers
+// change; options that refer to fields, like WithColumnMaxWidth, keep
+// using the original names. Fields without an alias are shown as is.
+func WithColumnAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		if o.aliases == nil {
+			o.aliases = make(map[string]string, len(aliases))
+		}
+		for field, alias := range aliases {
+			o.aliases[field] = alias
+		}
+	}
+}
+
+// columnLabel returns the header label of field
+func (e *encoder) columnLabel(field string) string {
+	if alias, ok := e.aliases[field]; ok {
+		field = alias
+	}
+	return e.specKey(field)
+}
+
+// columnLabels returns the header labels of fields
+func (e *encoder) columnLabels(fields []string) []string {
+	if len(e.aliases) == 0 && !e.strict() {
+		return fields
+	}
+	labels := make([]string, len(fields))
+	for i, field := range fields {
+		labels[i] = e.columnLabel(field)
+	}
+	return labels
+}
+
+// fieldNames maps the header labels read back from a table to the fields
+// they stand for
+func (e *encoder) fieldNames(labels []string) []string {
+	if len(e.aliases) == 0 {
+		return labels
+	}
+	fields := make(map[string]string, len(e.aliases))
+	for field, alias := range e.aliases {
+		fields[alias] = field
+	}
+	for i, label := range labels {
+		if field, ok := fields[label]; ok {
+			labels[i] = field
+		}
+	}
+	return labels
+}
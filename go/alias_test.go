@@ -0,0 +1,44 @@
+package totoon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithColumnAliases(t *testing.T) {
+	data := map[string]interface{}{
+		"orders": []interface{}{
+			map[string]interface{}{"id": 1, "quantity_ordered_units": 5, "sku": "A-1"},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(),
+		WithColumnAliases(map[string]string{"quantity_ordered_units": "qty"}),
+		WithColumnMaxWidth(map[string]int{"sku": 2}))
+	expected := "orders[1]{id,qty,sku}:\n  1,5,A…"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithColumnAliases_Writers(t *testing.T) {
+	aliases := WithColumnAliases(map[string]string{"identifier": "id"})
+
+	var buf bytes.Buffer
+	tw, err := NewTableWriter(&buf, "rows", []string{"identifier", "name"}, aliases)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw.WriteRow(1, "a")
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "rows[]{id,name}:\n  1,a"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+
+	rows := []interface{}{map[string]interface{}{"identifier": 1}}
+	result := ToToonWithOptions(rows, WithTranspose(), aliases)
+	if expected := "[1]{field,0}:\n  id,1"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,114 @@
+package totoon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AppendRows adds rows to the table stored under tableKey in an encoded TOON
+// document and updates the row count in its header, without re-encoding the
+// rest of the document. This allows log or export files to grow
+// incrementally.
+//
+// The document is parsed as FromToonWithOptions parses it, and the first
+// table with the given key is used, at any nesting level. The new rows go
+// after its last row and the child tables of WithSubTables, ahead of any
+// footer. Rows are matched to the existing columns by name; a row containing
+// a field the table does not have is an error, since adding a column would
+// require rewriting every existing row. The new rows are encoded with opts,
+// which should be the options the document was written with, and use the
+// delimiter the header declares.
+func AppendRows(doc []byte, tableKey string, rows []map[string]interface{}, opts ...Option) ([]byte, error) {
+	e := newEncoder(opts)
+	d := newDecoder(string(doc), e)
+
+	var header entryLine
+	var headerLine docLine
+	var tablePath string
+	count, end := 0, -1
+	d.tableEnd = func(t entryLine, l docLine, rows, rowsEnd int) {
+		if end < 0 && t.key == tableKey && !t.keyless {
+			header, headerLine, tablePath, count, end = t, l, d.path, rows, rowsEnd
+		}
+	}
+	if _, err := d.document(); err != nil {
+		return nil, err
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("totoon: no table %q found", tableKey)
+	}
+
+	fieldSet := make(map[string]bool, len(header.fields))
+	for _, f := range header.fields {
+		fieldSet[f] = true
+	}
+	for i, row := range rows {
+		for k := range row {
+			if !fieldSet[k] {
+				return nil, fmt.Errorf("totoon: row %d has field %q not present in table %q", i, k, tableKey)
+			}
+		}
+	}
+
+	switch header.delim {
+	case '\t':
+		e.delimiter = TabDelimiter
+	case '|':
+		e.delimiter = PipeDelimiter
+	default:
+		e.delimiter = CommaDelimiter
+	}
+	// the lines of doc are the lines of d, so end is also the index in
+	// lines of the line after the table
+	rowPrefix := e.rowPrefix(headerLine.indent / max(e.indent, 1))
+	firstRow := headerLine.num
+	if header.fieldsBelow {
+		firstRow++
+	}
+	if firstRow < end {
+		rowPrefix = strings.Repeat(" ", d.lines[firstRow].indent)
+	}
+
+	var newLines []string
+	for i, row := range rows {
+		cells := make([]string, len(header.fields))
+		var subTables []string
+		for j, f := range header.fields {
+			cells[j] = e.missingCell()
+			v, exists := row[f]
+			if !exists {
+				continue
+			}
+			e.path = joinPath(indexPath(tablePath, count+i), f)
+			v = e.format(v)
+			if sub, ok := e.subTableRows(v); ok {
+				cells[j] = fmt.Sprintf("[%d]", len(sub))
+				subTables = append(subTables, e.subTableToToon(sub, rowPrefix))
+			} else {
+				cells[j] = e.columnCellToToon(f, v)
+			}
+		}
+		newLines = append(newLines, rowPrefix+strings.Join(cells, e.delimiterString()))
+		newLines = append(newLines, subTables...)
+	}
+
+	lines := strings.Split(string(doc), "\n")
+	if header.countAt > 0 {
+		// the header may follow the dash of a list item
+		line := lines[headerLine.num-1]
+		at := len(line) - len(headerLine.text)
+		if text := headerLine.text; isItem(text) {
+			at += len(text) - len(strings.TrimPrefix(strings.TrimPrefix(text, "-"), " "))
+		}
+		at += header.countAt
+		digits := at
+		for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+			digits++
+		}
+		lines[headerLine.num-1] = line[:at] + strconv.Itoa(header.count+len(rows)) + line[digits:]
+	}
+
+	out := append(lines[:end:end], newLines...)
+	return []byte(strings.Join(append(out, lines[end:]...), "\n")), nil
+}
@@ -0,0 +1,97 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppendRows(t *testing.T) {
+	doc := []byte("title: Log\nevents[2]{id,msg}:\n  1,start\n  2,run\nfooter: end")
+	result, err := AppendRows(doc, "events", []map[string]interface{}{
+		{"id": 3, "msg": "stop, done"},
+		{"id": 4},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "title: Log\nevents[4]{id,msg}:\n  1,start\n  2,run\n  3,\"stop, done\"\n  4,\nfooter: end"
+	if string(result) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(result))
+	}
+}
+
+func TestAppendRows_MatchesEncoder(t *testing.T) {
+	first := []interface{}{map[string]interface{}{"id": 1}}
+	all := []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}}
+	doc := []byte(ToToon(map[string]interface{}{"rows": first}))
+	result, err := AppendRows(doc, "rows", []map[string]interface{}{{"id": 2}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result) != ToToon(map[string]interface{}{"rows": all}) {
+		t.Errorf("Expected appended document to match direct encoding, got: %q", string(result))
+	}
+}
+
+func TestAppendRows_Errors(t *testing.T) {
+	doc := []byte("events[1]{id}:\n  1")
+	if _, err := AppendRows(doc, "missing", nil); err == nil {
+		t.Errorf("Expected error for unknown table")
+	}
+	if _, err := AppendRows(doc, "events", []map[string]interface{}{{"other": 1}}); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+	if _, err := AppendRows([]byte("events[3]{id}:\n  1"), "events", nil); err == nil {
+		t.Errorf("Expected error for truncated table")
+	}
+}
+
+func TestAppendRows_MatchesEncoderWithOptions(t *testing.T) {
+	order := map[string]interface{}{"sku": "x"}
+	for _, tc := range []struct {
+		opts   []Option
+		nested bool // the rows hold a list of orders, which DialectV2 cannot put in a table
+	}{
+		{[]Option{WithSubTables()}, true},
+		{[]Option{WithHeaderStyle(TwoLineHeader)}, true},
+		{[]Option{WithHeaderStyle(ParenHeader)}, true},
+		{[]Option{WithDelimiter(PipeDelimiter)}, true},
+		{[]Option{WithLengthMarkers(false)}, true},
+		{[]Option{WithColumnAliases(map[string]string{"note": "n"})}, true},
+		{[]Option{WithDialect(DialectV2), WithIndent(4)}, false},
+	} {
+		row := func(id int, orders ...interface{}) map[string]interface{} {
+			r := map[string]interface{}{"id": id, "note": "a|b, c"}
+			if tc.nested {
+				r["orders"] = orders
+			}
+			return r
+		}
+		opts := append(tc.opts, withSortedKeys())
+		first := map[string]interface{}{"log events": []interface{}{row(1, order)}, "z": 1}
+		all := map[string]interface{}{"log events": []interface{}{row(1, order), row(2, order, order)}, "z": 1}
+		doc := []byte(ToToonWithOptions(first, opts...))
+		result, err := AppendRows(doc, "log events", []map[string]interface{}{row(2, order, order)}, opts...)
+		if err != nil {
+			t.Fatalf("AppendRows(%q): %v", doc, err)
+		}
+		if expected := ToToonWithOptions(all, opts...); string(result) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+		}
+	}
+}
+
+func TestAppendRows_BeforeSummary(t *testing.T) {
+	opts := []Option{WithSummaryRow(Sum("n"))}
+	doc := []byte(ToToonWithOptions(map[string]interface{}{"rows": []interface{}{map[string]interface{}{"n": 1}}}, opts...))
+	result, err := AppendRows(doc, "rows", []map[string]interface{}{{"n": 2}}, opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(result), "rows[2]{n}:\n  1\n  2\n  = ") {
+		t.Errorf("Expected the new row above the footer, got: %q", result)
+	}
+	if _, err := FromToonWithOptions(string(result), opts...); err != nil {
+		t.Errorf("Expected the result to decode, got: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package totoon
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Array is a list section of a document with typed accessors. Unlike the
+// lenient Value accessors, each accessor checks every element and returns an
+// error naming the first one of the wrong type, so lists can be consumed
+// without repetitive type assertions.
+type Array []interface{}
+
+// Array returns the value as an Array, or an error if it is not a list
+func (v *Value) Array() (Array, error) {
+	list, ok := v.Interface().([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("totoon: value of type %T is not a list", v.Interface())
+	}
+	return Array(list), nil
+}
+
+// Strings returns the elements as strings
+func (a Array) Strings() ([]string, error) {
+	out := make([]string, len(a))
+	for i, item := range a {
+		s, ok := item.(string)
+		if !ok {
+			return nil, a.typeError(i, "a string")
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Ints returns the elements as ints. Floats are accepted only when they hold
+// an integral value that fits in an int.
+func (a Array) Ints() ([]int, error) {
+	out := make([]int, len(a))
+	for i, item := range a {
+		n, ok := arrayInt(item)
+		if !ok {
+			return nil, a.typeError(i, "an integer")
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// Objects returns the elements as objects
+func (a Array) Objects() ([]map[string]interface{}, error) {
+	out := make([]map[string]interface{}, len(a))
+	for i, item := range a {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, a.typeError(i, "an object")
+		}
+		out[i] = obj
+	}
+	return out, nil
+}
+
+func (a Array) typeError(i int, want string) error {
+	return fmt.Errorf("totoon: element %d is %s, not %s", i, describeKind(a[i]), want)
+}
+
+// describeKind names the kind of a generic value for error messages
+func describeKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a bool"
+	case string:
+		return "a string"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	}
+	if _, ok := toFloat(v); ok {
+		return "a number"
+	}
+	return fmt.Sprintf("a %T", v)
+}
+
+func arrayInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8, int16, int32, int64:
+		i := reflect.ValueOf(n).Int()
+		return int(i), i >= math.MinInt && i <= math.MaxInt
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(n).Uint()
+		return int(u), u <= math.MaxInt
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil && i >= math.MinInt && i <= math.MaxInt
+	case float32, float64:
+		f, _ := toFloat(n)
+		return int(f), f == math.Trunc(f) && f >= math.MinInt && f < math.MaxInt
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package totoon
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestArray_Accessors(t *testing.T) {
+	doc := NewValue(map[string]interface{}{
+		"tags":  []interface{}{"a", "b"},
+		"ids":   []interface{}{1, int64(2), 3.0, json.Number("4")},
+		"users": []interface{}{map[string]interface{}{"name": "Alice"}},
+	})
+
+	tags, err := doc.Get("tags").Array()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	strs, err := tags.Strings()
+	if err != nil || strings.Join(strs, ",") != "a,b" {
+		t.Errorf("Expected [a b], got: %v (%v)", strs, err)
+	}
+
+	ids, _ := doc.Get("ids").Array()
+	ints, err := ids.Ints()
+	if err != nil || len(ints) != 4 || ints[3] != 4 {
+		t.Errorf("Expected [1 2 3 4], got: %v (%v)", ints, err)
+	}
+
+	users, _ := doc.Get("users").Array()
+	objs, err := users.Objects()
+	if err != nil || objs[0]["name"] != "Alice" {
+		t.Errorf("Expected one user object, got: %v (%v)", objs, err)
+	}
+}
+
+func TestArray_ValidationErrors(t *testing.T) {
+	a := Array{"a", 2.5, nil}
+	if _, err := a.Strings(); err == nil || err.Error() != "totoon: element 1 is a number, not a string" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := a.Ints(); err == nil || err.Error() != "totoon: element 0 is a string, not an integer" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := (Array{1.0, 2.5}).Ints(); err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("Expected fractional float to be rejected, got: %v", err)
+	}
+	if _, err := (Array{map[string]interface{}{}, nil}).Objects(); err == nil || !strings.Contains(err.Error(), "is null") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestValue_ArrayNotList(t *testing.T) {
+	if _, err := NewValue(map[string]interface{}{"a": 1}).Get("a").Array(); err == nil {
+		t.Errorf("Expected error for non-list value")
+	}
+	var missing *Value
+	if _, err := missing.Array(); err == nil {
+		t.Errorf("Expected error for missing value")
+	}
+}
@@ -0,0 +1,41 @@
+package totoon
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+)
+
+// bigNumber converts math/big integers and floats to json.Numbers, which
+// are written digit for digit in objects and table cells alike. Pointers
+// and values are both accepted, as struct fields often hold big.Int values
+// whose methods are only on the pointer. An infinite big.Float becomes a
+// float64 infinity.
+func bigNumber(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		if n == nil {
+			return nil, true
+		}
+		return json.Number(n.String()), true
+	case big.Int:
+		return json.Number(n.String()), true
+	case *big.Float:
+		if n == nil {
+			return nil, true
+		}
+		return bigFloat(n), true
+	case big.Float:
+		return bigFloat(&n), true
+	}
+	return nil, false
+}
+
+// bigFloat writes f in plain decimal notation with the fewest digits that
+// identify it at its precision, as 'g' would switch to exponents
+func bigFloat(f *big.Float) interface{} {
+	if f.IsInf() {
+		return math.Inf(f.Sign())
+	}
+	return json.Number(f.Text('f', -1))
+}
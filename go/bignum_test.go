@@ -0,0 +1,80 @@
+package totoon
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigNumbers(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	price, _ := new(big.Float).SetPrec(200).SetString("19.99")
+	type payment struct {
+		Amount big.Int    `toon:"amount"`
+		Fee    *big.Float `toon:"fee"`
+	}
+	data := map[string]interface{}{
+		"supply": huge,
+		"price":  price,
+		"none":   (*big.Int)(nil),
+		"payments": []payment{
+			{Amount: *big.NewInt(-5), Fee: big.NewFloat(0.25)},
+			{Amount: *huge, Fee: new(big.Float).SetInf(false)},
+		},
+	}
+	result := ToToon(data)
+	expected := "none: null\npayments[2]{amount,fee}:\n  -5,0.25\n  123456789012345678901234567890,+Inf\n" +
+		"price: 19.99\nsupply: 123456789012345678901234567890"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBigNumbers_V2(t *testing.T) {
+	tiny, _ := new(big.Float).SetPrec(100).SetString("1.25e-30")
+	big1e30, _ := new(big.Float).SetString("1e30")
+	data := map[string]interface{}{"tiny": tiny, "large": big1e30}
+	result := ToToonWithOptions(data, WithDialect(DialectV2))
+	expected := "large: 1000000000000000000000000000000\ntiny: 0.00000000000000000000000000000125"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBigNumbers_PlainDecimals(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{"1e30", "1000000000000000000000000000000"},
+		{"1.25e-30", "0.00000000000000000000000000000125"},
+		{"-6.02214076e23", "-602214076000000000000000"},
+		{"123456.789", "123456.789"},
+	}
+	for _, tt := range tests {
+		f, _ := new(big.Float).SetPrec(100).SetString(tt.in)
+		if result := ToToon(map[string]interface{}{"n": f}); result != "n: "+tt.out {
+			t.Errorf("%s: Expected %q, got: %q", tt.in, "n: "+tt.out, result)
+		}
+	}
+}
+
+func TestDecimalText(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{"0", "0"},
+		{"-0", "0"},
+		{"-0.0e5", "0"},
+		{"12345678901234567890", "12345678901234567890"},
+		{"1.50", "1.5"},
+		{"1.5e3", "1500"},
+		{"1.5E+1", "15"},
+		{"-25e-3", "-0.025"},
+		{"100e-2", "1"},
+	}
+	for _, tt := range tests {
+		if got, ok := decimalText(tt.in); !ok || got != tt.out {
+			t.Errorf("decimalText(%q): expected %q, got: %q", tt.in, tt.out, got)
+		}
+	}
+	for _, in := range []string{"1e999999", "abc", "01"} {
+		if _, ok := decimalText(in); ok {
+			t.Errorf("decimalText(%q): expected false", in)
+		}
+	}
+}
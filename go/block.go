@@ -0,0 +1,110 @@
+package totoon
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WithLiteralBlocks writes multiline strings held by object keys or list
+// items as YAML-style literal blocks: a "|" marker followed by the text,
+// unescaped and indented one level below the key. Stack traces and code
+// snippets stay readable instead of becoming one long escaped line. A
+// trailing newline is kept by "|" and absent with "|-". Strings with
+// carriage returns or several trailing newlines, strings whose first line
+// is indented or that have lines of only whitespace, which a block cannot
+// hold, and strings inside table cells keep the quoted form.
+func WithLiteralBlocks() Option {
+	return func(o *options) {
+		o.literal = true
+	}
+}
+
+// WithFoldedBlocks wraps long single-line prose held by object keys or list
+// items across indented lines, YAML-style: a ">-" marker followed by the
+// words, each line at most width characters where possible. A reader joins
+// the lines with single spaces, so only strings whose words are separated by
+// single spaces are folded; others, and strings inside table cells, are
+// written as usual.
+func WithFoldedBlocks(width int) Option {
+	return func(o *options) {
+		o.foldWidth = width
+	}
+}
+
+// useBlock reports whether s is written as a literal or folded block
+func (e *encoder) useBlock(s string) bool {
+	return !e.strict() && (e.useLiteral(s) || e.useFolded(s))
+}
+
+func (e *encoder) useLiteral(s string) bool {
+	if !e.literal || !strings.Contains(s, "\n") || strings.Contains(s, "\r") {
+		return false
+	}
+	if strings.HasSuffix(s, "\n\n") {
+		return false
+	}
+	// readers take the indentation of the first line for the block's own,
+	// and whitespace-only lines for blank ones
+	first := true
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "" || (first && line[0] == ' ') {
+			return false
+		}
+		first = false
+	}
+	return true
+}
+
+func (e *encoder) useFolded(s string) bool {
+	if e.foldWidth < 1 || utf8.RuneCountInString(s) <= e.foldWidth {
+		return false
+	}
+	if strings.ContainsAny(s, "\n\r\t") || strings.Contains(s, "  ") {
+		return false
+	}
+	return strings.Contains(s, " ") && strings.TrimSpace(s) == s
+}
+
+// blockToToon renders s as a block whose lines are indented to level
+func (e *encoder) blockToToon(s string, level int) string {
+	prefix := strings.Repeat(" ", e.indent*level)
+	if !e.useLiteral(s) {
+		return ">-\n" + prefix + strings.Join(foldWords(s, e.foldWidth), "\n"+prefix)
+	}
+
+	marker := "|-"
+	if strings.HasSuffix(s, "\n") {
+		marker = "|"
+		s = s[:len(s)-1]
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return marker + "\n" + strings.Join(lines, "\n")
+}
+
+// foldWords splits s at spaces into lines of at most width characters; a
+// word longer than width gets a line of its own
+func foldWords(s string, width int) []string {
+	var lines []string
+	line, n := "", 0
+	for _, word := range strings.Split(s, " ") {
+		w := utf8.RuneCountInString(word)
+		switch {
+		case line == "":
+			line, n = word, w
+		case n+1+w <= width:
+			line, n = line+" "+word, n+1+w
+		default:
+			lines = append(lines, line)
+			line, n = word, w
+		}
+	}
+	return append(lines, line)
+}
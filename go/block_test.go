@@ -0,0 +1,100 @@
+package totoon
+
+import "testing"
+
+func TestWithLiteralBlocks_ObjectValue(t *testing.T) {
+	data := map[string]interface{}{
+		"error": map[string]interface{}{
+			"trace": "panic: boom\n\ngoroutine 1:\n\tmain.go:12\n",
+		},
+	}
+	result := ToToonWithOptions(data, WithLiteralBlocks())
+	expected := "error:\n  trace: |\n    panic: boom\n\n    goroutine 1:\n    \tmain.go:12"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithLiteralBlocks_StripChomping(t *testing.T) {
+	data := map[string]interface{}{"code": "a := 1\nb := 2"}
+	expected := "code: |-\n  a := 1\n  b := 2"
+	if result := ToToonWithOptions(data, WithLiteralBlocks()); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithLiteralBlocks_ListItem(t *testing.T) {
+	data := map[string]interface{}{"snippets": []interface{}{"x\ny", "z"}}
+	expected := "snippets:\n  - |-\n    x\n    y\n  - z"
+	if result := ToToonWithOptions(data, WithLiteralBlocks()); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithLiteralBlocks_Fallbacks(t *testing.T) {
+	data := map[string]interface{}{
+		"crlf": "a\r\nb",
+		"rows": []interface{}{map[string]interface{}{"text": "a\nb"}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithLiteralBlocks())
+	expected := "crlf: \"a\\r\\nb\"\nrows[1]{text}:\n  \"a\\nb\""
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithLiteralBlocks_RoundTrip(t *testing.T) {
+	values := []string{
+		"  func() {\n    x\n  }\n",
+		"\n  indented after a blank line",
+		"a\n  \nb\n",
+		"func() {\n    x\n}\n",
+		"panic: boom\n\n\tmain.go:12",
+	}
+	for _, v := range values {
+		data := map[string]interface{}{"code": v}
+		doc := ToToonWithOptions(data, WithLiteralBlocks())
+		decoded, err := FromToon(doc)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %q: %v", doc, err)
+		}
+		if got := decoded.(map[string]interface{})["code"]; got != v {
+			t.Errorf("Expected %q, got: %q (from %q)", v, got, doc)
+		}
+	}
+	if doc := ToToonWithOptions(map[string]interface{}{"code": values[0]}, WithLiteralBlocks()); doc != `code: "  func() {\n    x\n  }\n"` {
+		t.Errorf("Expected the quoted form, got: %q", doc)
+	}
+}
+
+func TestWithFoldedBlocks(t *testing.T) {
+	data := map[string]interface{}{
+		"summary": "the quick brown fox jumps over the lazy dog",
+		"short":   "fits on one line",
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithFoldedBlocks(16))
+	expected := "short: fits on one line\nsummary: >-\n  the quick brown\n  fox jumps over\n  the lazy dog"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithFoldedBlocks_KeepsUnfoldableStrings(t *testing.T) {
+	data := map[string]interface{}{
+		"spaced": "two  spaces between words here",
+		"word":   "supercalifragilisticexpialidocious",
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithFoldedBlocks(10))
+	expected := "spaced: two  spaces between words here\nword: supercalifragilisticexpialidocious"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithFoldedBlocks_LongWord(t *testing.T) {
+	data := []interface{}{"a verylongword b"}
+	expected := "- >-\n  a\n  verylongword\n  b"
+	if result := ToToonWithOptions(data, WithFoldedBlocks(5)); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,58 @@
+package totoon
+
+// BoolStyle selects how booleans are written in table cells
+type BoolStyle int
+
+const (
+	// TrueFalseBools writes true and false (the default)
+	TrueFalseBools BoolStyle = iota
+	// YesNoBools writes yes and no
+	YesNoBools
+	// YNBools writes Y and N
+	YNBools
+	// OneZeroBools writes 1 and 0, which do not stand out from the
+	// numbers 1 and 0
+	OneZeroBools
+)
+
+// WithBoolStyle selects the words used for booleans in table cells, for
+// prompts that read better with natural-language values. Booleans outside
+// tables keep true and false. String cells that read as one of the chosen
+// words are quoted so they stay distinguishable from booleans.
+func WithBoolStyle(style BoolStyle) Option {
+	return func(o *options) {
+		o.boolStyle = style
+	}
+}
+
+// boolLabels returns the cell text of true and false
+func (e *encoder) boolLabels() (string, string) {
+	switch e.boolStyle {
+	case YesNoBools:
+		return "yes", "no"
+	case YNBools:
+		return "Y", "N"
+	case OneZeroBools:
+		return "1", "0"
+	}
+	return "true", "false"
+}
+
+// boolCell renders a boolean table cell
+func (e *encoder) boolCell(b bool) string {
+	t, f := e.boolLabels()
+	if b {
+		return t
+	}
+	return f
+}
+
+// readsAsBool reports whether the string cell s would read as a boolean
+// under a non-default BoolStyle
+func (e *encoder) readsAsBool(s string) bool {
+	if e.boolStyle == TrueFalseBools {
+		return false
+	}
+	t, f := e.boolLabels()
+	return s == t || s == f
+}
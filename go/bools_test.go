@@ -0,0 +1,38 @@
+package totoon
+
+import "testing"
+
+func TestWithBoolStyle(t *testing.T) {
+	data := map[string]interface{}{
+		"active": true,
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "admin": true},
+			map[string]interface{}{"name": "yes", "admin": false},
+		},
+	}
+	tests := map[BoolStyle]string{
+		TrueFalseBools: "active: true\nusers[2]{admin,name}:\n  true,Alice\n  false,yes",
+		YesNoBools:     "active: true\nusers[2]{admin,name}:\n  yes,Alice\n  no,\"yes\"",
+		YNBools:        "active: true\nusers[2]{admin,name}:\n  Y,Alice\n  N,yes",
+		OneZeroBools:   "active: true\nusers[2]{admin,name}:\n  1,Alice\n  0,yes",
+	}
+	for style, expected := range tests {
+		result := ToToonWithOptions(data, withSortedKeys(), WithBoolStyle(style))
+		if result != expected {
+			t.Errorf("style %d: Expected %q, got: %q", style, expected, result)
+		}
+	}
+}
+
+func TestWithBoolStyle_NestedTables(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"flags": []interface{}{
+			map[string]interface{}{"k": "Y", "on": true},
+		}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithBoolStyle(YNBools))
+	expected := "[1]{flags}:\n  [1]{k,on}:\"Y\",Y"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,144 @@
+package totoon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectBuilder constructs a TOON object programmatically. Entries are
+// rendered in the order they were first set, and nothing is encoded until
+// Render is called on the document.
+//
+//	doc := totoon.NewDocument()
+//	doc.Object("meta").Set("count", 2)
+//	doc.Table("users", []string{"name", "age"}).
+//		AddRow("Alice", 30).
+//		AddRow("Bob", 25)
+//	out, err := doc.Render()
+type ObjectBuilder struct {
+	entries []builderEntry
+	index   map[string]int
+	errs    *[]error
+}
+
+// TableBuilder collects rows for a table created with ObjectBuilder.Table
+type TableBuilder struct {
+	fields []string
+	rows   [][]interface{}
+	errs   *[]error
+}
+
+type builderEntry struct {
+	key   string
+	value interface{}
+}
+
+// NewDocument returns an empty builder for a top-level object
+func NewDocument() *ObjectBuilder {
+	return newObjectBuilder(new([]error))
+}
+
+func newObjectBuilder(errs *[]error) *ObjectBuilder {
+	return &ObjectBuilder{index: make(map[string]int), errs: errs}
+}
+
+// Set stores a value under key, replacing any earlier entry with that key
+// while keeping its position. Values are encoded as ToToon would encode them.
+func (b *ObjectBuilder) Set(key string, value interface{}) *ObjectBuilder {
+	b.put(key, value)
+	return b
+}
+
+// Object returns the builder for the nested object under key, creating it if
+// needed
+func (b *ObjectBuilder) Object(key string) *ObjectBuilder {
+	if i, exists := b.index[key]; exists {
+		if child, ok := b.entries[i].value.(*ObjectBuilder); ok {
+			return child
+		}
+	}
+	child := newObjectBuilder(b.errs)
+	b.put(key, child)
+	return child
+}
+
+// Table starts a table under key with the given columns, replacing any
+// earlier entry with that key
+func (b *ObjectBuilder) Table(key string, fields []string) *TableBuilder {
+	table := &TableBuilder{fields: fields, errs: b.errs}
+	if len(fields) == 0 {
+		*b.errs = append(*b.errs, fmt.Errorf("totoon: table %q has no fields", key))
+	}
+	b.put(key, table)
+	return table
+}
+
+// AddRow appends a row whose values match the table fields by position
+func (t *TableBuilder) AddRow(values ...interface{}) *TableBuilder {
+	if len(values) != len(t.fields) {
+		*t.errs = append(*t.errs, fmt.Errorf("totoon: row has %d values, table has %d fields", len(values), len(t.fields)))
+		return t
+	}
+	t.rows = append(t.rows, values)
+	return t
+}
+
+// Render encodes the document. It returns the first error recorded while
+// building, such as a row with the wrong number of values.
+func (b *ObjectBuilder) Render(opts ...Option) (string, error) {
+	if len(*b.errs) > 0 {
+		return "", (*b.errs)[0]
+	}
+	e := newEncoder(opts)
+	out := e.builderToToon(b, 0)
+	if e.err != nil {
+		return "", e.err
+	}
+	e.encoded(len(out))
+	return out, nil
+}
+
+func (b *ObjectBuilder) put(key string, value interface{}) {
+	if i, exists := b.index[key]; exists {
+		b.entries[i].value = value
+		return
+	}
+	b.index[key] = len(b.entries)
+	b.entries = append(b.entries, builderEntry{key: key, value: value})
+}
+
+func (e *encoder) builderToToon(b *ObjectBuilder, level int) string {
+	if len(b.entries) == 0 {
+		return "{}"
+	}
+
+	var lines []string
+	prefix := strings.Repeat(" ", e.indent*level)
+	for _, entry := range b.entries {
+		switch val := entry.value.(type) {
+		case *ObjectBuilder:
+			if len(val.entries) == 0 {
+				lines = append(lines, fmt.Sprintf("%s%s: {}", prefix, entry.key))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s%s:", prefix, entry.key))
+			lines = append(lines, e.builderToToon(val, level+1))
+		case *TableBuilder:
+			if len(val.rows) == 0 {
+				lines = append(lines, fmt.Sprintf("%s%s: []", prefix, entry.key))
+				continue
+			}
+			lines = append(lines, prefix+e.tableHeader(entry.key, len(val.rows), val.fields))
+			for _, row := range val.rows {
+				cells := make([]string, len(row))
+				for i, v := range row {
+					cells[i] = e.columnCellToToon(val.fields[i], e.unorder(v))
+				}
+				lines = append(lines, e.rowPrefix(level)+strings.Join(cells, e.delimiterString()))
+			}
+		default:
+			lines = append(lines, e.dictToToon(map[string]interface{}{entry.key: e.unorder(val)}, level))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
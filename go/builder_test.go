@@ -0,0 +1,73 @@
+package totoon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDocument_Render(t *testing.T) {
+	doc := NewDocument()
+	doc.Set("title", "Report")
+	doc.Object("meta").Set("count", 2).Set("source", "db")
+	doc.Table("users", []string{"name", "age"}).
+		AddRow("Alice", 30).
+		AddRow("Bob", 25)
+	doc.Set("tags", []interface{}{"a", "b"})
+
+	result, err := doc.Render()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "title: Report\nmeta:\n  count: 2\n  source: db\nusers[2]{name,age}:\n  Alice,30\n  Bob,25\ntags:\n  - a\n  - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestDocument_ReplaceKeepsPosition(t *testing.T) {
+	doc := NewDocument()
+	doc.Set("a", 1).Set("b", 2).Set("a", 3)
+	doc.Object("meta").Set("x", 1)
+	doc.Object("meta").Set("y", 2)
+	result, _ := doc.Render()
+	expected := "a: 3\nb: 2\nmeta:\n  x: 1\n  y: 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestDocument_RowError(t *testing.T) {
+	doc := NewDocument()
+	doc.Table("users", []string{"name", "age"}).AddRow("Alice")
+	if _, err := doc.Render(); err == nil {
+		t.Errorf("Expected error for short row")
+	}
+}
+
+func TestDocument_Empty(t *testing.T) {
+	result, err := NewDocument().Render()
+	if err != nil || result != "{}" {
+		t.Errorf("Expected '{}', got: %q (%v)", result, err)
+	}
+}
+
+func TestDocument_ConvertsValues(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc := NewDocument()
+	doc.Set("meta", NewOrderedMap().Set("z", 1).Set("a", 2))
+	doc.Set("origin", point{X: 1, Y: 2})
+	doc.Table("events", []string{"at", "where"}).AddRow(at, point{X: 3, Y: 4})
+
+	result, err := doc.Render()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "meta:\n  z: 1\n  a: 2\norigin:\n  x: 1\n  y: 2\nevents[1]{at,where}:\n  \"2024-01-02T03:04:05Z\",{x:3,y:4}"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,158 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CanonicalBytes returns the canonical TOON encoding of v, intended for
+// signing (for example with HMAC) and for content addressing.
+//
+// The canonical form is defined as follows and is guaranteed to produce the
+// same bytes for the same input across all future versions of this package:
+//
+//   - v is first normalized through encoding/json, so struct fields follow
+//     their json tags; numbers keep their value, not their Go type, so 2
+//     and 2.0 encode alike while integers beyond 2^53 keep every digit
+//   - object keys and table fields are sorted by byte-wise comparison
+//   - nesting is indented with two spaces, lines end in a single "\n" and
+//     there is no trailing newline
+//   - integers are written with all their digits; other numbers use the
+//     shortest representation that round-trips as a float64, in plain
+//     decimal notation for magnitudes in [1e-6, 1e21) and exponent notation
+//     such as 1e+21 or 5e-7 otherwise
+//   - strings use the default quoting and escaping rules of ToToon, and are
+//     also quoted when they are empty, start with "[" or "{", or would
+//     otherwise read as a number, a bool, null or a quoted string, so that
+//     no string shares its bytes with a value of another type
+//   - table cells of fields a row does not have are empty, and empty
+//     strings in cells are quoted
+//
+// Encoder options never influence the canonical form. Any change to the
+// encoder that alters these bytes is a breaking change and is caught by the
+// pinned test vectors in canonical_test.go.
+func CanonicalBytes(v interface{}) ([]byte, error) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeJSON(bytes.NewReader(jsonBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// built directly so that SetDefaults cannot change the canonical form
+	e := &encoder{options: defaultOptions()}
+	e.sortKeys = true
+	e.canonical = true
+	e.missingSet = true
+	return []byte(e.encode(data)), nil
+}
+
+// readsAsLiteral reports whether the string s, written bare, would not read
+// back as the same string, or could be taken for a nested list or object
+// in a table cell
+func readsAsLiteral(s string) bool {
+	if s == "" || s[0] == '[' || s[0] == '{' {
+		return true
+	}
+	str, ok := parseScalar(s).(string)
+	return !ok || str != s
+}
+
+// formatNumber renders any Go numeric value
+func (e *encoder) formatNumber(v interface{}) string {
+	e.checkPrecision(v)
+	if s, ok := e.nonFiniteNumber(v); ok {
+		return s
+	}
+	if e.strict() {
+		return specNumber(v)
+	}
+	if e.canonical {
+		if n, ok := v.(json.Number); ok {
+			return canonicalNumber(n)
+		}
+		if f, ok := toFloat(v); ok {
+			return formatCanonicalFloat(f)
+		}
+	}
+	return formatPlainNumber(v)
+}
+
+// formatPlainNumber formats a number with strconv only, so the output is
+// fully determined by the value: it never depends on the locale or on fmt
+// verb handling. Integers are written in base 10; floats use the shortest
+// representation that parses back to the same value at their own bit size,
+// switching to exponent form (1e+06, 1e-05) for exponents below -4 or of 6
+// and above. NaN and infinities are written as NaN, +Inf and -Inf, and a
+// json.Number as it is written.
+func formatPlainNumber(v interface{}) string {
+	switch n := v.(type) {
+	case int:
+		return strconv.FormatInt(int64(n), 10)
+	case int8:
+		return strconv.FormatInt(int64(n), 10)
+	case int16:
+		return strconv.FormatInt(int64(n), 10)
+	case int32:
+		return strconv.FormatInt(int64(n), 10)
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case uint:
+		return strconv.FormatUint(uint64(n), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(n), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(n), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(n), 10)
+	case uint64:
+		return strconv.FormatUint(n, 10)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case json.Number:
+		return string(n)
+	}
+	return fmt.Sprint(v)
+}
+
+// canonicalNumber writes a number of the canonical form: integers keep
+// all their digits, as encoding/json writes them, and other numbers are
+// written as the float64 they parse to, so 1.5e3 reads 1500
+func canonicalNumber(n json.Number) string {
+	if !strings.ContainsAny(string(n), ".eE") {
+		return string(n)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return string(n)
+	}
+	return formatCanonicalFloat(f)
+}
+
+// formatCanonicalFloat matches the ES6 number formatting also used by
+// encoding/json, which is fully determined by the value
+func formatCanonicalFloat(f float64) string {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(nil, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return string(b)
+}
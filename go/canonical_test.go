@@ -0,0 +1,245 @@
+package totoon
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func canonicalSample() map[string]interface{} {
+	return map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "id": 1, "score": 0.5},
+			map[string]interface{}{"name": "Bob", "id": 2, "score": 12345678},
+			map[string]interface{}{"name": "", "id": 3},
+		},
+		"meta": map[string]interface{}{
+			"tiny": 0.0000001,
+			"huge": 1e21,
+			"note": "multi\nline",
+			"tags": []interface{}{"b", "a"},
+			"code": "42",
+			"flag": "true",
+		},
+		"active": true,
+		"parent": nil,
+	}
+}
+
+func TestCanonicalBytes_PinnedVector(t *testing.T) {
+	got, err := CanonicalBytes(canonicalSample())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "active: true\nmeta:\n  code: \"42\"\n  flag: \"true\"\n  huge: 1e+21\n  note: \"multi\\nline\"\n  tags:\n    - b\n    - a\n  tiny: 1e-7\nparent: null\nusers[3]{id,name,score}:\n  1,Alice,0.5\n  2,Bob,12345678\n  3,\"\","
+	if string(got) != expected {
+		t.Errorf("Canonical form changed.\nExpected: %q\nGot:      %q", expected, string(got))
+	}
+}
+
+func TestHash_PinnedVector(t *testing.T) {
+	got, err := Hash(canonicalSample())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "5a91b75b2775fd0376075f750a166703b06223a8457948f267b3e85ed63b4c22" {
+		t.Errorf("Canonical hash changed, got: %s", got)
+	}
+}
+
+func TestCanonicalBytes_DistinguishesTypes(t *testing.T) {
+	pairs := [][2]interface{}{
+		{"42", 42},
+		{"true", true},
+		{"null", nil},
+		{"", map[string]interface{}{}},
+		{"[1]", []interface{}{1}},
+		{"{}", map[string]interface{}{}},
+		{`"x"`, "x"},
+	}
+	for _, pair := range pairs {
+		for _, wrap := range []func(interface{}) interface{}{
+			func(v interface{}) interface{} { return map[string]interface{}{"v": v} },
+			func(v interface{}) interface{} { return []interface{}{v} },
+			func(v interface{}) interface{} {
+				return []interface{}{map[string]interface{}{"a": v, "b": 1}}
+			},
+		} {
+			a, err := CanonicalBytes(wrap(pair[0]))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			b, err := CanonicalBytes(wrap(pair[1]))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(a) == string(b) {
+				t.Errorf("%#v and %#v both give %q", pair[0], pair[1], string(a))
+			}
+		}
+	}
+}
+
+func TestCanonicalBytes_MissingCells(t *testing.T) {
+	empty := []interface{}{
+		map[string]interface{}{"a": "", "b": 1},
+		map[string]interface{}{"a": "", "b": 2},
+	}
+	absent := []interface{}{
+		map[string]interface{}{"b": 1},
+		map[string]interface{}{"a": "x", "b": 2},
+	}
+	got, err := CanonicalBytes(empty)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "[2]{a,b}:\n  \"\",1\n  \"\",2"; string(got) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(got))
+	}
+	got, err = CanonicalBytes(absent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "[2]{a,b}:\n  ,1\n  x,2"; string(got) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(got))
+	}
+}
+
+func TestCanonicalBytes_IgnoresOptions(t *testing.T) {
+	data := map[string]interface{}{"b": 1, "a": 2}
+	got, err := CanonicalBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != "a: 2\nb: 1" {
+		t.Errorf("Expected sorted keys, got: %q", string(got))
+	}
+}
+
+func TestFormatCanonicalFloat(t *testing.T) {
+	cases := map[float64]string{
+		0:                "0",
+		-1.5:             "-1.5",
+		123456789:        "123456789",
+		1e20:             "100000000000000000000",
+		1e21:             "1e+21",
+		0.000001:         "0.000001",
+		0.0000005:        "5e-7",
+		1.0 / 3.0:        "0.3333333333333333",
+		-2.5e-10:         "-2.5e-10",
+		9007199254740993: "9007199254740992",
+	}
+	for in, expected := range cases {
+		if got := formatCanonicalFloat(in); got != expected {
+			t.Errorf("formatCanonicalFloat(%v): expected %s, got: %s", in, expected, got)
+		}
+	}
+}
+
+func TestFormatPlainNumber_IntegerBoundaries(t *testing.T) {
+	cases := map[interface{}]string{
+		int8(math.MinInt8):     "-128",
+		int16(math.MaxInt16):   "32767",
+		int32(math.MinInt32):   "-2147483648",
+		int64(math.MinInt64):   "-9223372036854775808",
+		uint8(math.MaxUint8):   "255",
+		uint32(math.MaxUint32): "4294967295",
+		uint64(math.MaxUint64): "18446744073709551615",
+		0:                      "0",
+	}
+	for v, expected := range cases {
+		if got := formatPlainNumber(v); got != expected {
+			t.Errorf("%T %v: expected %s, got: %s", v, v, expected, got)
+		}
+	}
+}
+
+func TestFormatPlainNumber_FloatEdgeCases(t *testing.T) {
+	cases := []struct {
+		v        interface{}
+		expected string
+	}{
+		{0.0, "0"},
+		{math.Copysign(0, -1), "-0"},
+		{999999.0, "999999"},
+		{1234567.0, "1.234567e+06"},
+		{1e20, "1e+20"},
+		{1e21, "1e+21"},
+		{0.0001, "0.0001"},
+		{0.00001, "1e-05"},
+		{math.MaxFloat64, "1.7976931348623157e+308"},
+		{math.SmallestNonzeroFloat64, "5e-324"},
+		{float32(0.1), "0.1"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, c := range cases {
+		if got := formatPlainNumber(c.v); got != c.expected {
+			t.Errorf("%v: expected %s, got: %s", c.v, c.expected, got)
+		}
+	}
+}
+
+// TestFormatPlainNumber_Properties checks random bit patterns: every finite
+// float64 and float32 parses back to itself, uses only number characters and
+// matches the output of earlier releases
+func TestFormatPlainNumber_Properties(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		f := math.Float64frombits(r.Uint64())
+		got := formatPlainNumber(f)
+		if got != fmt.Sprintf("%v", f) {
+			t.Fatalf("%b: expected %v, got: %s", math.Float64bits(f), f, got)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		if back, err := strconv.ParseFloat(got, 64); err != nil || back != f {
+			t.Fatalf("%s does not round-trip: %v (%v)", got, back, err)
+		}
+		for _, c := range got {
+			if !(c >= '0' && c <= '9') && c != '.' && c != '-' && c != '+' && c != 'e' {
+				t.Fatalf("%s contains %q", got, c)
+			}
+		}
+
+		f32 := math.Float32frombits(r.Uint32())
+		if math.IsNaN(float64(f32)) || math.IsInf(float64(f32), 0) {
+			continue
+		}
+		if back, err := strconv.ParseFloat(formatPlainNumber(f32), 32); err != nil || float32(back) != f32 {
+			t.Fatalf("float32 %v does not round-trip: %v (%v)", f32, back, err)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		n := int64(r.Uint64())
+		if got := formatPlainNumber(n); got != fmt.Sprintf("%v", n) {
+			t.Fatalf("%d: got %s", n, got)
+		}
+	}
+}
+
+func TestCanonicalBytes_LargeIntegers(t *testing.T) {
+	got, err := CanonicalBytes(map[string]interface{}{
+		"id":    int64(9007199254740993),
+		"max":   uint64(18446744073709551615),
+		"float": json.Number("1.5e3"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "float: 1500\nid: 9007199254740993\nmax: 18446744073709551615"
+	if string(got) != expected {
+		t.Errorf("Canonical form changed.\nExpected: %q\nGot:      %q", expected, string(got))
+	}
+
+	a, _ := Hash(map[string]interface{}{"id": int64(9007199254740993)})
+	b, _ := Hash(map[string]interface{}{"id": int64(9007199254740992)})
+	if a == b {
+		t.Errorf("Expected distinct integers above 2^53 to hash differently")
+	}
+}
@@ -0,0 +1,164 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Chunk converts v to TOON and splits the result into standalone documents
+// that each stay within maxTokensPerChunk tokens. Splits happen only between
+// top-level keys, between nested object keys and between list or table rows,
+// so every chunk is a valid TOON document on its own; table headers are
+// repeated with the row count of their chunk.
+//
+// Token counts are estimated at four bytes per token, which is close to what
+// common LLM tokenizers produce for TOON output. An error is returned when a
+// single row or scalar cannot fit within the limit on its own.
+func Chunk(v interface{}, maxTokensPerChunk int, opts ...Option) ([]string, error) {
+	if maxTokensPerChunk <= 0 {
+		return nil, fmt.Errorf("totoon: chunk limit must be positive, got %d", maxTokensPerChunk)
+	}
+
+	e := newEncoder(opts)
+	data := e.unorder(v)
+	if e.err != nil {
+		return nil, e.err
+	}
+	// estimateTokens rounds up a quarter of the byte length, so a piece
+	// fits exactly when it renders to at most four bytes per token.
+	size := func(piece interface{}) int {
+		return len(e.toToon(piece, 0))
+	}
+
+	pieces, err := e.splitValue(data, "", size, 4*maxTokensPerChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]string, len(pieces))
+	for i, piece := range pieces {
+		chunks[i] = e.encode(piece)
+	}
+	return chunks, nil
+}
+
+// splitValue breaks v into pieces whose rendered size, as reported by size,
+// is at most limit. Objects are split by key, in output order, and lists by
+// item; anything else that does not fit is an error.
+func (e *encoder) splitValue(v interface{}, path string, size func(interface{}) int, limit int) ([]interface{}, error) {
+	if size(v) <= limit {
+		return []interface{}{v}, nil
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		var pieces []interface{}
+		current := map[string]interface{}{}
+		var currentKeys []string
+		for _, k := range e.objectKeys(val) {
+			k := k
+			entries, err := e.splitValue(val[k], joinPath(path, k), func(sub interface{}) int {
+				return size(map[string]interface{}{k: sub})
+			}, limit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, entry := range entries {
+				candidate := make(map[string]interface{}, len(current)+1)
+				for ck, cv := range current {
+					candidate[ck] = cv
+				}
+				candidate[k] = entry
+				candidateKeys := append(append([]string(nil), currentKeys...), k)
+				e.recordPart(candidate, val, candidateKeys)
+
+				_, taken := current[k]
+				if len(current) > 0 && (taken || size(candidate) > limit) {
+					pieces = append(pieces, current)
+					candidate = map[string]interface{}{k: entry}
+					candidateKeys = []string{k}
+					e.recordPart(candidate, val, candidateKeys)
+				}
+				current, currentKeys = candidate, candidateKeys
+			}
+		}
+		if len(current) > 0 {
+			pieces = append(pieces, current)
+		}
+		return pieces, nil
+
+	case []interface{}:
+		return e.splitList(val, path, size, limit)
+	}
+
+	if path == "" {
+		path = "(root)"
+	}
+	return nil, fmt.Errorf("totoon: value at %s does not fit in a single chunk", path)
+}
+
+// splitList breaks a list into runs of items that each fit within limit.
+// Every item is rendered on its own once; a run grows by each item's size
+// less the header and wrapping that a one-item list repeats, so filling a
+// chunk costs a sum rather than a render. The estimate ignores a header
+// that widens as the run grows, so each finished run is rendered once and
+// trimmed from the end until it fits.
+func (e *encoder) splitList(val []interface{}, path string, size func(interface{}) int, limit int) ([]interface{}, error) {
+	sizes := make([]int, len(val))
+	for i, item := range val {
+		sizes[i] = size([]interface{}{item})
+		if sizes[i] > limit {
+			return nil, fmt.Errorf("totoon: item %s does not fit in a single chunk", joinPath(path, fmt.Sprint(i)))
+		}
+	}
+
+	overhead := 0
+	if len(val) > 1 {
+		overhead = max(0, sizes[0]+sizes[1]-size(val[:2:2]))
+	}
+
+	var pieces []interface{}
+	for start := 0; start < len(val); {
+		end, total := start+1, sizes[start]
+		for end < len(val) && total+sizes[end]-overhead <= limit {
+			total += sizes[end] - overhead
+			end++
+		}
+		for end > start+1 && size(val[start:end:end]) > limit {
+			end--
+		}
+		pieces = append(pieces, append([]interface{}(nil), val[start:end]...))
+		start = end
+	}
+	return pieces, nil
+}
+
+// estimateTokens approximates the LLM token count of s
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// normalize converts v into the generic map/slice form produced by
+// encoding/json so that its structure can be inspected. Numbers are decoded
+// as json.Numbers, so integers beyond 2^53 keep their digits.
+func normalize(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case nil, bool, string, float64, json.Number, map[string]interface{}, []interface{}:
+		return v, nil
+	}
+
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(bytes.NewReader(jsonBytes))
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
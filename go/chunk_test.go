@@ -0,0 +1,146 @@
+package totoon
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestChunk_FitsInOne(t *testing.T) {
+	data := map[string]interface{}{"name": "Alice"}
+	chunks, err := Chunk(data, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0] != "name: Alice" {
+		t.Errorf("Expected a single chunk, got: %v", chunks)
+	}
+}
+
+func TestChunk_SplitsTableRows(t *testing.T) {
+	var users []interface{}
+	for i := 0; i < 20; i++ {
+		users = append(users, map[string]interface{}{"name": "user-name", "id": i})
+	}
+	data := map[string]interface{}{"users": users}
+	chunks, err := Chunk(data, 30)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got: %d", len(chunks))
+	}
+	rows := 0
+	for _, chunk := range chunks {
+		if estimateTokens(chunk) > 30 {
+			t.Errorf("Chunk exceeds limit: %s", chunk)
+		}
+		if !strings.HasPrefix(chunk, "users[") {
+			t.Errorf("Expected each chunk to repeat the table header, got: %s", chunk)
+		}
+		rows += strings.Count(chunk, "user-name")
+	}
+	if rows != 20 {
+		t.Errorf("Expected 20 rows across chunks, got: %d", rows)
+	}
+}
+
+func TestChunk_ManyRows(t *testing.T) {
+	var users []interface{}
+	for i := 0; i < 2000; i++ {
+		users = append(users, map[string]interface{}{"name": fmt.Sprintf("user-%d", i), "id": i})
+	}
+	chunks, err := Chunk(map[string]interface{}{"users": users}, 500)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rows := 0
+	for i, chunk := range chunks {
+		tokens := estimateTokens(chunk)
+		if tokens > 500 {
+			t.Errorf("Chunk %d exceeds limit: %d tokens", i, tokens)
+		}
+		if i < len(chunks)-1 && tokens < 450 {
+			t.Errorf("Expected chunk %d to be nearly full, got: %d tokens", i, tokens)
+		}
+		rows += strings.Count(chunk, "user-")
+	}
+	if rows != 2000 {
+		t.Errorf("Expected 2000 rows across chunks, got: %d", rows)
+	}
+}
+
+func TestChunk_SparseRows(t *testing.T) {
+	var items []interface{}
+	for i := 0; i < 40; i++ {
+		items = append(items, map[string]interface{}{fmt.Sprintf("field%d", i): i})
+	}
+	chunks, err := Chunk(map[string]interface{}{"items": items}, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fields := 0
+	for _, chunk := range chunks {
+		if estimateTokens(chunk) > 60 {
+			t.Errorf("Chunk exceeds limit: %s", chunk)
+		}
+		fields += strings.Count(chunk, "field")
+	}
+	if fields != 40 {
+		t.Errorf("Expected 40 fields across chunks, got: %d", fields)
+	}
+}
+
+func TestChunk_SplitsTopLevelKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"a": strings.Repeat("x", 40),
+		"b": strings.Repeat("y", 40),
+	}
+	chunks, err := Chunk(data, 15)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 2 || !strings.HasPrefix(chunks[0], "a: ") || !strings.HasPrefix(chunks[1], "b: ") {
+		t.Errorf("Expected one chunk per key, got: %v", chunks)
+	}
+}
+
+func TestChunk_ValueTooLarge(t *testing.T) {
+	data := map[string]interface{}{"text": strings.Repeat("x", 100)}
+	_, err := Chunk(data, 10)
+	if err == nil || !strings.Contains(err.Error(), "text") {
+		t.Errorf("Expected error naming the oversized key, got: %v", err)
+	}
+}
+
+func TestChunk_InvalidLimit(t *testing.T) {
+	if _, err := Chunk("x", 0); err == nil {
+		t.Errorf("Expected error for non-positive limit")
+	}
+}
+
+func TestChunk_ConvertsValues(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		ID   int    `json:"id"`
+	}
+	id, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	data := NewOrderedMap().
+		Set("z", strings.Repeat("x", 40)).
+		Set("id", id).
+		Set("owner", user{Name: "Alice", ID: 1})
+	chunks, err := Chunk(data, 15)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"z: " + strings.Repeat("x", 40), "id: 123456789012345678901234567890", "owner:\n  name: Alice\n  id: 1"}
+	if len(chunks) != len(expected) {
+		t.Fatalf("Expected %q, got: %q", expected, chunks)
+	}
+	for i := range expected {
+		if chunks[i] != expected[i] {
+			t.Errorf("Expected %q, got: %q", expected[i], chunks[i])
+		}
+	}
+}
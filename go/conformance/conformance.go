@@ -0,0 +1,121 @@
+// Package conformance checks that an encoder and decoder pair round-trips a
+// bundled corpus of tricky documents, so integrators can verify that their
+// options still produce TOON that reads back as the original value.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EncoderFunc encodes a value as a TOON document, for example a call to
+// totoon.ToToonWithOptions with the options under test
+type EncoderFunc func(v interface{}) (string, error)
+
+// DecoderFunc decodes a TOON document back into a value
+type DecoderFunc func(doc string) (interface{}, error)
+
+// Result is the outcome of one corpus case
+type Result struct {
+	Name    string
+	Encoded string // the document produced by the encoder, if any
+	Err     error  // nil when the case round-tripped
+}
+
+// Report holds the results of Run, in corpus order
+type Report struct {
+	Results []Result
+}
+
+// Passed reports whether every case round-tripped
+func (r Report) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the results of the cases that did not round-trip
+func (r Report) Failures() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// String summarizes the report, listing each failed case
+func (r Report) String() string {
+	failed := r.Failures()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d cases passed", len(r.Results)-len(failed), len(r.Results))
+	for _, res := range failed {
+		fmt.Fprintf(&b, "\n%s: %v", res.Name, res.Err)
+	}
+	return b.String()
+}
+
+// Run encodes every corpus document with enc, decodes the result with dec
+// and compares it with the original. Both values are normalized through
+// encoding/json, with numbers kept as json.Number, and compared with
+// reflect.DeepEqual, so map order and Go numeric types do not matter but
+// the string "42" and the number 42, or an empty string and a missing
+// field, differ.
+func Run(enc EncoderFunc, dec DecoderFunc) Report {
+	var report Report
+	for _, c := range corpus {
+		report.Results = append(report.Results, runCase(c, enc, dec))
+	}
+	return report
+}
+
+func runCase(c corpusCase, enc EncoderFunc, dec DecoderFunc) Result {
+	res := Result{Name: c.name}
+	var want interface{}
+	if err := json.Unmarshal([]byte(c.json), &want); err != nil {
+		// the corpus is fixed, so this only happens if it is edited badly
+		panic(fmt.Sprintf("conformance: case %s: %v", c.name, err))
+	}
+
+	doc, err := enc(want)
+	if err != nil {
+		res.Err = fmt.Errorf("encode: %w", err)
+		return res
+	}
+	res.Encoded = doc
+
+	got, err := dec(doc)
+	if err != nil {
+		res.Err = fmt.Errorf("decode: %w", err)
+		return res
+	}
+
+	wantValue, wantJSON, _ := normalize(want)
+	gotValue, gotJSON, err := normalize(got)
+	if err != nil {
+		res.Err = fmt.Errorf("cannot compare decoded value: %w", err)
+		return res
+	}
+	if !reflect.DeepEqual(wantValue, gotValue) {
+		res.Err = fmt.Errorf("decoded value differs: want %s, got %s", wantJSON, gotJSON)
+	}
+	return res
+}
+
+// normalize converts v to the generic form encoding/json decodes, keeping
+// numbers as json.Number, and returns it along with its JSON
+func normalize(v interface{}) (interface{}, []byte, error) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, nil, err
+	}
+	return out, jsonBytes, nil
+}
@@ -0,0 +1,99 @@
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+)
+
+// jsonPair stands in for a TOON codec that round-trips everything
+func jsonPair() (EncoderFunc, DecoderFunc) {
+	enc := func(v interface{}) (string, error) {
+		doc, err := json.Marshal(v)
+		return string(doc), err
+	}
+	dec := func(doc string) (interface{}, error) {
+		var v interface{}
+		err := json.Unmarshal([]byte(doc), &v)
+		return v, err
+	}
+	return enc, dec
+}
+
+func TestRun_Passes(t *testing.T) {
+	report := Run(jsonPair())
+	if !report.Passed() {
+		t.Fatalf("Expected every case to pass, got: %s", report)
+	}
+	if len(report.Results) != len(corpus) {
+		t.Errorf("Expected %d results, got: %d", len(corpus), len(report.Results))
+	}
+	expected := "17 of 17 cases passed"
+	if report.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, report.String())
+	}
+}
+
+func TestRun_ReportsLossyDecoder(t *testing.T) {
+	enc, dec := jsonPair()
+	lossy := func(doc string) (interface{}, error) {
+		return dec(strings.ReplaceAll(doc, "a,b", "a"))
+	}
+	report := Run(enc, lossy)
+	failed := report.Failures()
+	if len(failed) != 2 {
+		t.Fatalf("Expected 2 failures, got: %s", report)
+	}
+	if failed[0].Name != "delimiters in values" || failed[1].Name != "delimiters in tables" {
+		t.Errorf("Unexpected failures: %s", report)
+	}
+	if failed[0].Encoded == "" || !strings.Contains(failed[0].Err.Error(), "decoded value differs") {
+		t.Errorf("Unexpected result: %+v", failed[0])
+	}
+}
+
+func TestRun_ReportsTypeChanges(t *testing.T) {
+	enc, dec := jsonPair()
+	coercing := func(doc string) (interface{}, error) {
+		// a decoder that reads "42" as a number and "" as a missing field
+		doc = strings.ReplaceAll(doc, `"42"`, `42`)
+		return dec(strings.ReplaceAll(doc, `,""`, ``))
+	}
+	report := Run(enc, coercing)
+	failed := report.Failures()
+	if len(failed) != 1 || failed[0].Name != "ambiguous strings" {
+		t.Fatalf("Expected the ambiguous strings case to fail, got: %s", report)
+	}
+}
+
+func TestRun_ReportsErrors(t *testing.T) {
+	boom := errors.New("boom")
+	enc, dec := jsonPair()
+	report := Run(func(v interface{}) (string, error) { return "", boom }, dec)
+	if report.Passed() || !errors.Is(report.Results[0].Err, boom) {
+		t.Fatalf("Expected encode errors, got: %s", report)
+	}
+	if !strings.HasPrefix(report.Results[0].Err.Error(), "encode: ") {
+		t.Errorf("Expected %q prefix, got: %q", "encode: ", report.Results[0].Err)
+	}
+
+	report = Run(enc, func(doc string) (interface{}, error) { return nil, boom })
+	if !strings.HasPrefix(report.Results[0].Err.Error(), "decode: ") {
+		t.Errorf("Expected %q prefix, got: %q", "decode: ", report.Results[0].Err)
+	}
+	if !strings.HasPrefix(report.String(), "0 of 17 cases passed\nunicode: decode: boom") {
+		t.Errorf("Unexpected report: %q", report.String())
+	}
+}
+
+func TestRun_FromToon(t *testing.T) {
+	enc := func(v interface{}) (string, error) {
+		return totoon.ToToonWithOptions(v, totoon.WithDialect(totoon.DialectV2)), nil
+	}
+	if report := Run(enc, totoon.FromToon); !report.Passed() {
+		t.Errorf("Expected DialectV2 to round-trip, got: %s", report)
+	}
+}
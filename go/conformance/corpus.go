@@ -0,0 +1,52 @@
+package conformance
+
+import "strings"
+
+type corpusCase struct {
+	name string
+	json string
+}
+
+// corpus lists the documents Run checks; they are JSON so the expected
+// value does not depend on the encoder under test
+var corpus = []corpusCase{
+	{"unicode", `{"name": "Zoë", "city": "東京", "greeting": "שלום", "emoji": "🎉", "combining": "e\u0301", "zero_width": "a\u200bb"}`},
+	{"delimiters in values", `{"values": ["a,b", "c:d", "e|f", "g\th", "[x]", "{y}", "#z", "- item", " padded ", "\"quoted\"", "it's", "back\\slash", "line\nbreak", "cr\rlf"]}`},
+	{"delimiters in tables", `{"rows": [{"id": 1, "text": "a,b"}, {"id": 2, "text": "c: d"}, {"id": 3, "text": "\"q\""}]}`},
+	{"ambiguous strings", `{"values": ["true", "false", "null", "42", "-1.5", "1e3", "05", "", " ", "-", "[]", "{}"]}`},
+	{"marker strings", `{"block": "|", "strip": "|-", "fold": ">-", "values": ["|", "|-", ">-"], "rows": [{"x": "|"}, {"x": ">-"}]}`},
+	{"raw marker strings", `{"a": "<<EOF", "t": [{"x": "EOF"}, {"x": "y"}], "values": ["<<EOF", "EOF"]}`},
+	{"numbers", `{"values": [0, -0.5, 1.25, 1e21, 1e-7, 123456789, 9007199254740991]}`},
+	{"keys", `{"my key": 1, "a:b": 2, "": 3, "123": 4, "-dash": 5, "a.b": 6, "[x]": 7, "quote\"d": 8}`},
+	{"sparse table", `{"rows": [{"id": 1, "name": "a"}, {"id": 2}, {"id": 3, "name": null, "extra": true}]}`},
+	{"table with nested values", `{"users": [{"id": 1, "tags": ["a", "b"], "meta": {"x": 1}}, {"id": 2, "tags": [], "meta": {}}]}`},
+	{"empty containers", `{"object": {}, "list": [], "nested": {"lists": [[]], "objects": [{}]}}`},
+	{"mixed list", `[1, "two", {"three": 3}, [4, 5], null, true]`},
+	{"nested lists", `{"matrix": [[1, 2], [3, 4]], "ragged": [[1], [], [2, [3]]]}`},
+	{"root list of objects", `[{"a": 1, "b": "x"}, {"a": 2, "b": "y"}]`},
+	{"root string", `"a: b"`},
+	{"root number", `42`},
+	{"deep nesting", deepNesting(32)},
+}
+
+// deepNesting returns depth objects nested in each other, alternating with
+// single-item lists
+func deepNesting(depth int) string {
+	var b strings.Builder
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			b.WriteString(`{"level": [`)
+		} else {
+			b.WriteString(`{"next": `)
+		}
+	}
+	b.WriteString(`"bottom"`)
+	for i := depth - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			b.WriteString(`]}`)
+		} else {
+			b.WriteString(`}`)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCorpus_IsValidJSON(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range corpus {
+		if seen[c.name] {
+			t.Errorf("duplicate case %q", c.name)
+		}
+		seen[c.name] = true
+		if !json.Valid([]byte(c.json)) {
+			t.Errorf("case %q is not valid JSON", c.name)
+		}
+	}
+}
+
+func TestDeepNesting(t *testing.T) {
+	expected := `{"level": [{"next": {"level": ["bottom"]}}]}`
+	if result := deepNesting(3); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
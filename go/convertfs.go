@@ -0,0 +1,140 @@
+package totoon
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WriterFS is the destination of ConvertFS. Names are slash-separated paths
+// relative to the root of the destination, as in io/fs.
+type WriterFS interface {
+	WriteFile(name string, data []byte) error
+}
+
+// FileConverter converts the contents of one file to TOON. YAMLToToon from
+// the toonyaml package has this signature.
+type FileConverter func(src []byte, opts ...Option) (string, error)
+
+// WithFileConverter makes ConvertFS convert files with the given extension
+// (such as ".yaml") using fn. JSON files are converted without one.
+func WithFileConverter(ext string, fn FileConverter) Option {
+	return func(o *options) {
+		if o.converters == nil {
+			o.converters = make(map[string]FileConverter)
+		}
+		o.converters[strings.ToLower(ext)] = fn
+	}
+}
+
+// ConvertFS walks src and writes a ".toon" file to dst for every ".json"
+// file and every file with a converter registered by WithFileConverter,
+// keeping the directory layout; other files are skipped. JSON keys keep
+// their source order.
+//
+// A failing file does not stop the walk. Once every file has been tried,
+// the failures are returned together in a *ConvertFSError.
+func ConvertFS(src fs.FS, dst WriterFS, opts ...Option) error {
+	e := newEncoder(opts)
+	var failures []FileError
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			failures = append(failures, FileError{Path: name, Err: err})
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		ext := strings.ToLower(path.Ext(name))
+		convert, ok := e.converters[ext]
+		if !ok && ext != ".json" {
+			return nil
+		}
+		if err := convertFile(src, dst, name, convert, opts); err != nil {
+			failures = append(failures, FileError{Path: name, Err: err})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return &ConvertFSError{Files: failures}
+	}
+	return nil
+}
+
+func convertFile(src fs.FS, dst WriterFS, name string, convert FileConverter, opts []Option) error {
+	data, err := fs.ReadFile(src, name)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	if convert != nil {
+		out, err = convert(data, opts...)
+	} else {
+		var buf bytes.Buffer
+		err = newEncoder(opts).convertJSONStream(bytes.NewReader(data), &buf)
+		out = buf.String()
+	}
+	if err != nil {
+		return err
+	}
+
+	target := strings.TrimSuffix(name, path.Ext(name)) + ".toon"
+	return dst.WriteFile(target, []byte(out+"\n"))
+}
+
+// FileError is the failure of a single file in ConvertFS
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (f FileError) String() string {
+	return f.Path + ": " + f.Err.Error()
+}
+
+// ConvertFSError lists the files that ConvertFS could not convert
+type ConvertFSError struct {
+	Files []FileError
+}
+
+func (err *ConvertFSError) Error() string {
+	parts := make([]string, len(err.Files))
+	for i, f := range err.Files {
+		parts[i] = f.String()
+	}
+	if len(parts) == 1 {
+		return "totoon: 1 file failed to convert: " + parts[0]
+	}
+	return fmt.Sprintf("totoon: %d files failed to convert: %s", len(parts), strings.Join(parts, "; "))
+}
+
+// DirWriterFS returns a WriterFS that writes below the directory dir,
+// creating subdirectories as needed
+func DirWriterFS(dir string) WriterFS {
+	return dirWriterFS(dir)
+}
+
+type dirWriterFS string
+
+func (dir dirWriterFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	target := filepath.Join(string(dir), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
@@ -0,0 +1,90 @@
+package totoon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type mapWriterFS map[string]string
+
+func (m mapWriterFS) WriteFile(name string, data []byte) error {
+	m[name] = string(data)
+	return nil
+}
+
+func TestConvertFS(t *testing.T) {
+	src := fstest.MapFS{
+		"users.json":         {Data: []byte(`{"z": 1, "users": [{"id": 1, "name": "Alice"}]}`)},
+		"nested/config.JSON": {Data: []byte(`{"debug": true}`)},
+		"notes.txt":          {Data: []byte("skipped")},
+		"data.csv":           {Data: []byte("id\n1\n")},
+	}
+	dst := mapWriterFS{}
+	err := ConvertFS(src, dst, WithFileConverter(".csv", func(data []byte, opts ...Option) (string, error) {
+		return "csv: " + strings.TrimSpace(string(data)), nil
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"users.toon":         "z: 1\nusers[1]{id,name}:\n  1,Alice\n",
+		"nested/config.toon": "debug: true\n",
+		"data.toon":          "csv: id\n1\n",
+	}
+	if len(dst) != len(expected) {
+		t.Errorf("Expected %d files, got: %v", len(expected), dst)
+	}
+	for name, content := range expected {
+		if dst[name] != content {
+			t.Errorf("%s: Expected %q, got: %q", name, content, dst[name])
+		}
+	}
+}
+
+func TestConvertFS_ReportsFailures(t *testing.T) {
+	src := fstest.MapFS{
+		"a.json": {Data: []byte(`{"a": `)},
+		"b.json": {Data: []byte(`{"b": 1}`)},
+		"c.json": {Data: []byte(`[1, 2] 3`)},
+	}
+	dst := mapWriterFS{}
+	err := ConvertFS(src, dst)
+
+	var convertErr *ConvertFSError
+	if !errors.As(err, &convertErr) {
+		t.Fatalf("Expected ConvertFSError, got: %v", err)
+	}
+	if len(convertErr.Files) != 2 || convertErr.Files[0].Path != "a.json" || convertErr.Files[1].Path != "c.json" {
+		t.Errorf("Expected a.json and c.json to fail, got: %v", convertErr.Files)
+	}
+	if !strings.HasPrefix(err.Error(), "totoon: 2 files failed to convert: a.json: ") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if dst["b.toon"] != "b: 1\n" {
+		t.Errorf("Expected b.json to be converted, got: %v", dst)
+	}
+}
+
+func TestDirWriterFS(t *testing.T) {
+	dir := t.TempDir()
+	src := fstest.MapFS{"a/b/c.json": {Data: []byte(`{"x": "y"}`)}}
+	if err := ConvertFS(src, DirWriterFS(dir)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a", "b", "c.toon"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "x: y\n" {
+		t.Errorf("Expected %q, got: %q", "x: y\n", data)
+	}
+
+	if err := DirWriterFS(dir).WriteFile("../escape.toon", nil); err == nil {
+		t.Error("Expected an error for a path outside the directory")
+	}
+}
@@ -0,0 +1,153 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// WithCSVHeader supplies the column names for FromCSVReader, for CSV input
+// that has no header row. When set, the first record is treated as data.
+func WithCSVHeader(fields ...string) Option {
+	return func(o *options) {
+		o.csvHeader = fields
+	}
+}
+
+// csvColumnKind is the inferred type of a CSV column
+type csvColumnKind int
+
+const (
+	csvInt csvColumnKind = iota
+	csvFloat
+	csvBool
+	csvString
+)
+
+// FromCSVReader reads every record from r and converts them to a root TOON
+// table, keeping the column order of the CSV. Column types are inferred from
+// all non-empty cells: a column becomes int, float or bool only if every
+// value parses as such, and is kept as strings otherwise. Integers wider
+// than int64 keep every digit. Numbers with leading zeros (zip codes,
+// identifiers) are kept as strings. Empty cells stay empty.
+func FromCSVReader(r *csv.Reader, opts ...Option) (string, error) {
+	e := newEncoder(opts)
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return "", err
+	}
+
+	fields := e.csvHeader
+	if fields == nil {
+		if len(records) == 0 {
+			return "", errors.New("totoon: CSV input has no header row")
+		}
+		fields, records = records[0], records[1:]
+	}
+	if len(fields) == 0 {
+		return "", errors.New("totoon: CSV input has no columns")
+	}
+	if len(records) == 0 {
+		return "[]", nil
+	}
+
+	kinds := make([]csvColumnKind, len(fields))
+	for col := range fields {
+		kinds[col] = inferCSVColumn(records, col)
+	}
+
+	var buf bytes.Buffer
+	w := &Writer{e: e, w: e.newBufferedWriter(&buf)}
+	if err := w.BeginTable(fields); err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		row := make([]interface{}, len(fields))
+		for col := range fields {
+			row[col] = ""
+			if col < len(record) && record[col] != "" {
+				row[col] = parseCSVCell(record[col], kinds[col])
+			}
+		}
+		if err := w.Row(row...); err != nil {
+			return "", err
+		}
+	}
+	if err := w.EndTable(); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func inferCSVColumn(records [][]string, col int) csvColumnKind {
+	for kind := csvInt; kind < csvString; kind++ {
+		matches := true
+		for _, record := range records {
+			if col < len(record) && record[col] != "" && !csvCellIs(record[col], kind) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return kind
+		}
+	}
+	return csvString
+}
+
+func csvCellIs(cell string, kind csvColumnKind) bool {
+	switch kind {
+	case csvInt:
+		if hasLeadingZero(cell) {
+			return false
+		}
+		_, err := strconv.ParseInt(cell, 10, 64)
+		return err == nil || errors.Is(err, strconv.ErrRange)
+	case csvFloat:
+		if hasLeadingZero(cell) {
+			return false
+		}
+		f := strings.TrimLeft(cell, "+-")
+		if f == "" || !(f[0] >= '0' && f[0] <= '9' || f[0] == '.') {
+			// Rejects NaN and Inf, which would not survive a round-trip
+			return false
+		}
+		_, err := strconv.ParseFloat(cell, 64)
+		return err == nil
+	case csvBool:
+		return strings.EqualFold(cell, "true") || strings.EqualFold(cell, "false")
+	}
+	return true
+}
+
+func parseCSVCell(cell string, kind csvColumnKind) interface{} {
+	switch kind {
+	case csvInt:
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			// wider than int64, written as it is
+			return json.Number(strings.TrimPrefix(cell, "+"))
+		}
+		return n
+	case csvFloat:
+		f, _ := strconv.ParseFloat(cell, 64)
+		return f
+	case csvBool:
+		return strings.EqualFold(cell, "true")
+	}
+	return cell
+}
+
+// hasLeadingZero reports whether a numeric-looking cell such as "007" would
+// lose information if parsed as a number
+func hasLeadingZero(cell string) bool {
+	digits := strings.TrimLeft(cell, "+-")
+	return len(digits) > 1 && digits[0] == '0' && digits[1] != '.'
+}
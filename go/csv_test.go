@@ -0,0 +1,81 @@
+package totoon
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestFromCSVReader_TypeInference(t *testing.T) {
+	input := "name,age,score,active,zip\nAlice,30,1.5,true,02134\nBob,25,2,FALSE,10001\n"
+	result, err := FromCSVReader(csv.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[2]{name,age,score,active,zip}:\n  Alice,30,1.5,true,02134\n  Bob,25,2,false,10001"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestFromCSVReader_WideIntegers(t *testing.T) {
+	input := "id\n12345678901234567890\n-98765432109876543210\n+7\n"
+	result, err := FromCSVReader(csv.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[3]{id}:\n  12345678901234567890\n  -98765432109876543210\n  7"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestFromCSVReader_HeaderOverride(t *testing.T) {
+	input := "1,Alice\n2,\"Smith, Bob\"\n"
+	result, err := FromCSVReader(csv.NewReader(strings.NewReader(input)), WithCSVHeader("id", "name"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[2]{id,name}:\n  1,Alice\n  2,\"Smith, Bob\""
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestFromCSVReader_EmptyCellsAndMixedColumns(t *testing.T) {
+	input := "id,value\n1,\n2,abc\n3,4\n"
+	result, err := FromCSVReader(csv.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[3]{id,value}:\n  1,\n  2,abc\n  3,4"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestInferCSVColumn(t *testing.T) {
+	cases := map[string]csvColumnKind{
+		"1,2,3":      csvInt,
+		"1,2.5":      csvFloat,
+		"true,false": csvBool,
+		"1,true":     csvString,
+		"NaN,1":      csvString,
+		"007,1":      csvString,
+	}
+	for column, expected := range cases {
+		var records [][]string
+		for _, cell := range strings.Split(column, ",") {
+			records = append(records, []string{cell})
+		}
+		if got := inferCSVColumn(records, 0); got != expected {
+			t.Errorf("inferCSVColumn(%s): expected %d, got: %d", column, expected, got)
+		}
+	}
+}
+
+func TestFromCSVReader_NoHeader(t *testing.T) {
+	if _, err := FromCSVReader(csv.NewReader(strings.NewReader(""))); err == nil {
+		t.Errorf("Expected error for empty input")
+	}
+}
@@ -0,0 +1,1228 @@
+package totoon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FromToon parses a TOON document back into Go data: objects become
+// map[string]interface{}, lists []interface{}, numbers float64, and null,
+// true and false nil and bools, as encoding/json would decode them. It reads
+// the indented key/value blocks, "- " list items, key[N]{fields}: tables and
+// inline table cells written by ToToon, in both dialects (see WithDialect),
+// as well as block scalars and the one-value-per-line form. A value written
+// as <<TAG is raw: the lines up to the one holding only TAG, kept as
+// written less the indentation of that line, so code and regular
+// expressions need no escaping.
+//
+// TOON written with DialectV1 does not quote strings that read as numbers,
+// bools or null, so such strings decode as those values; an empty table
+// cell decodes as a missing field. DialectV2 output has neither ambiguity.
+// The footers of WithMaxRows and WithSummaryRow are skipped, so a table cut
+// by WithMaxRows decodes to the rows it shows.
+//
+// Documents written with options that change the syntax, such as
+// WithHeaderStyle or WithMissingCell, are read with FromToonWithOptions.
+//
+// Malformed documents return a *SyntaxError, or a *CountMismatchError when
+// a list, table or row does not hold the number of items its header
+// declares.
+func FromToon(s string) (interface{}, error) {
+	return FromToonWithOptions(s)
+}
+
+// FromToonWithOptions parses a TOON document written with the given options,
+// as FromToon does. It reads the header forms of WithLengthMarkers(false)
+// and WithHeaderStyle, the cells of WithMissingCell, WithBoolStyle and
+// WithNestedRowSeparator, and maps the labels of WithColumnAliases and
+// WithEnumLabels back to field names and values. OneZeroBools cells stay
+// numbers, as they cannot be told apart from 1 and 0, and strings that
+// equal an enum label at a labeled path decode as its value. Other options
+// do not affect decoding.
+func FromToonWithOptions(s string, opts ...Option) (interface{}, error) {
+	v, err := newDecoder(s, newEncoder(opts)).document()
+	if err != nil {
+		return nil, err
+	}
+	return plainValue(v), nil
+}
+
+// WithStringScalars makes the decoder read every number and bool as its
+// string form, numbers as written, for callers that only display values
+// and decode into map[string]string, []string or nested forms of them
+// without handling types. Null stays null, which leaves a string empty.
+func WithStringScalars() Option {
+	return func(o *options) {
+		o.stringScalars = true
+	}
+}
+
+// stringScalars replaces the numbers and bools in v by their string form
+func stringScalars(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *OrderedMap:
+		for _, k := range val.keys {
+			val.values[k] = stringScalars(val.values[k])
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = stringScalars(item)
+		}
+	case nil, string:
+	default:
+		return fmt.Sprint(val)
+	}
+	return v
+}
+
+// Valid reports whether data is a well-formed TOON document, as json.Valid
+// does for JSON. It runs the checks of FromToon, header counts and
+// duplicate keys included, without converting the parsed values to Go data.
+func Valid(data []byte) bool {
+	_, err := newDecoder(string(data), newEncoder(nil)).document()
+	return err == nil
+}
+
+// SyntaxError describes a malformed TOON document
+type SyntaxError struct {
+	Line   int    // 1-based line of the error
+	Column int    // 1-based byte column of the error on its line
+	Offset int    // 0-based byte offset of the error in the document
+	Path   string // location of the value being read, "" for the root
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("totoon: line %d: %s", e.Line, e.Msg)
+}
+
+// CountMismatchError reports a list or table whose length differs from the
+// count in its header, or a table row whose cells differ from the fields of
+// its table. It unwraps to the *SyntaxError that describes it.
+type CountMismatchError struct {
+	*SyntaxError
+	Path     string // location of the list, table or row, "" for the root
+	Expected int    // the count of the header or the number of fields
+	Actual   int    // the number of items, rows or cells found
+}
+
+func (e *CountMismatchError) Unwrap() error {
+	return e.SyntaxError
+}
+
+// docLine is a line of the document with its indentation split off
+type docLine struct {
+	num    int
+	offset int // byte offset of the start of the line in the document
+	indent int
+	text   string
+}
+
+type decoder struct {
+	lines []docLine
+	pos   int
+	e     *encoder // holds the options the document was written with
+	path  string   // location of the value being parsed
+
+	ctx context.Context // checked before each line, nil when there is none
+	err error           // the error of ctx, once it is done
+
+	// more reads the next line of a streamed document, nil when lines
+	// holds all of it
+	more func() (docLine, bool)
+
+	// tableEnd, when set, is called after the rows of each table with its
+	// header, its number of rows and the index in lines past its last row
+	tableEnd func(e entryLine, l docLine, rows, end int)
+}
+
+func newDecoder(s string, e *encoder) *decoder {
+	d := &decoder{e: e}
+	text := strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if text == "" {
+		return d
+	}
+	offset := 0 // in s, whose lines may end in \r\n
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		d.lines = append(d.lines, docLine{num: i + 1, offset: offset, indent: len(line) - len(trimmed), text: trimmed})
+		offset += len(line) + 1
+		if strings.HasPrefix(s[offset-1:], "\r\n") {
+			offset++
+		}
+	}
+	return d
+}
+
+// errorf returns a *SyntaxError for the value at path, located at the start
+// of line l
+func (d *decoder) errorf(l docLine, path string, format string, args ...interface{}) error {
+	return d.syntaxError(l, 0, path, fmt.Sprintf(format, args...))
+}
+
+// syntaxError returns a *SyntaxError located at byte col of the text of l
+func (d *decoder) syntaxError(l docLine, col int, path, msg string) *SyntaxError {
+	return &SyntaxError{
+		Line:   l.num,
+		Column: l.indent + col + 1,
+		Offset: l.offset + l.indent + col,
+		Path:   path,
+		Msg:    msg,
+	}
+}
+
+// countError returns a *CountMismatchError for the value at path, located
+// at byte col of the text of l
+func (d *decoder) countError(l docLine, col int, path string, expected, actual int, format string) error {
+	return &CountMismatchError{
+		SyntaxError: d.syntaxError(l, col, path, fmt.Sprintf(format, actual, expected)),
+		Path:        path,
+		Expected:    expected,
+		Actual:      actual,
+	}
+}
+
+// cellError turns an error of the cell scanner into a *SyntaxError, or a
+// *CountMismatchError for an inline table. The scanned text starts at byte
+// col of the text of l.
+func (d *decoder) cellError(l docLine, col int, path string, err error) error {
+	var at *scanError
+	if errors.As(err, &at) {
+		col += at.at
+		err = at.err
+	}
+	var count *cellCountError
+	if errors.As(err, &count) {
+		return d.countError(l, col, path, count.expected, count.actual, count.format)
+	}
+	return d.syntaxError(l, col, path, err.Error())
+}
+
+// peek returns the next line that is not blank. Once the context is done
+// it reports the end of the document, and document returns its error.
+func (d *decoder) peek() (docLine, bool) {
+	for d.pos < len(d.lines) && strings.TrimSpace(d.lines[d.pos].text) == "" {
+		d.pos++
+	}
+	if d.pos == len(d.lines) || d.done() {
+		return docLine{}, false
+	}
+	return d.lines[d.pos], true
+}
+
+// atEnd reports whether the lines of the document are used up. A streamed
+// document reads its next line, dropping those already used, so that it is
+// held a few lines at a time.
+func (d *decoder) atEnd() bool {
+	if d.pos < len(d.lines) {
+		return false
+	}
+	if d.more == nil {
+		return true
+	}
+	l, ok := d.more()
+	if !ok {
+		return true
+	}
+	d.lines, d.pos = append(d.lines[:0], l), 0
+	return false
+}
+
+// lineAfter returns the line that follows l, reading it from a streamed
+// document if need be
+func (d *decoder) lineAfter(l docLine) (docLine, bool) {
+	i := l.num - d.lines[0].num + 1
+	if i == len(d.lines) && d.more != nil {
+		if next, ok := d.more(); ok {
+			d.lines = append(d.lines, next)
+		}
+	}
+	if i >= len(d.lines) {
+		return docLine{}, false
+	}
+	return d.lines[i], true
+}
+
+// done reports whether the context of the decoder is done
+func (d *decoder) done() bool {
+	if d.err == nil && d.ctx != nil {
+		d.err = d.ctx.Err()
+	}
+	return d.err != nil
+}
+
+func (d *decoder) document() (interface{}, error) {
+	first, ok := d.peek()
+	if !ok {
+		if d.err != nil {
+			return nil, d.err
+		}
+		return NewOrderedMap(), nil
+	}
+
+	var v interface{}
+	var err error
+	if _, isEntry := d.parseEntry(first, first.text); isEntry || isItem(first.text) {
+		v, err = d.block(first.indent)
+	} else {
+		// a document that is a single scalar
+		d.pos++
+		v = parseScalar(first.text)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if next, ok := d.peek(); ok {
+		return nil, d.errorf(next, d.path, "unexpected %q", next.text)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.e.enums) > 0 {
+		v = d.e.unlabel(v, "")
+	}
+	if d.e.stringScalars {
+		v = stringScalars(v)
+	}
+	return v, nil
+}
+
+// block parses the object, list or table that starts at the next line
+func (d *decoder) block(indent int) (interface{}, error) {
+	l, _ := d.peek()
+	if isItem(l.text) {
+		return d.list(indent)
+	}
+	if e, ok := d.parseEntry(l, l.text); ok && e.keyless && e.list {
+		d.pos++
+		return d.listBody(e, l)
+	}
+	return d.object(indent)
+}
+
+func (d *decoder) object(indent int) (*OrderedMap, error) {
+	obj := NewOrderedMap()
+	for {
+		l, ok := d.peek()
+		if !ok || l.indent < indent || isItem(l.text) {
+			return obj, nil
+		}
+		if l.indent > indent {
+			return nil, d.errorf(l, d.path, "unexpected indentation")
+		}
+		e, ok := d.parseEntry(l, l.text)
+		if !ok {
+			return nil, d.errorf(l, d.path, "expected key: value, got %q", l.text)
+		}
+		d.pos++
+		if err := d.addEntry(obj, e, l); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (d *decoder) addEntry(obj *OrderedMap, e entryLine, l docLine) error {
+	parentPath := d.path
+	d.path = joinPath(parentPath, e.key)
+	v, err := d.entryValue(e, l)
+	d.path = parentPath
+	if err != nil {
+		return err
+	}
+	if !d.e.setKey(obj, e.key, v) {
+		return d.errorf(l, joinPath(d.path, e.key), "duplicate key %q", e.key)
+	}
+	return nil
+}
+
+// entryValue parses the value of the entry on line l, reading the lines of
+// a nested block if it has one
+func (d *decoder) entryValue(e entryLine, l docLine) (interface{}, error) {
+	if e.list {
+		return d.listBody(e, l)
+	}
+	if e.value != "" {
+		if isBlockMarker(e.value) {
+			return d.blockScalar(e.value, l.indent), nil
+		}
+		if raw, ok := d.heredoc(e.value); ok {
+			return raw, nil
+		}
+		return parseScalar(e.value), nil
+	}
+
+	next, ok := d.peek()
+	switch {
+	case ok && isEmptyBelow(next, l):
+		d.pos++
+		return parseScalar(next.text), nil
+	case ok && next.indent > l.indent:
+		return d.block(next.indent)
+	case e.spaced:
+		return "", nil
+	}
+	// "key:" with nothing beneath it is an empty object
+	return NewOrderedMap(), nil
+}
+
+func (d *decoder) list(indent int) ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		l, ok := d.peek()
+		if !ok || l.indent < indent || !isItem(l.text) {
+			return items, nil
+		}
+		if l.indent > indent {
+			return nil, d.errorf(l, d.path, "unexpected indentation")
+		}
+		d.pos++
+		parentPath := d.path
+		d.path = indexPath(parentPath, len(items))
+		item, err := d.listItem(l)
+		d.path = parentPath
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (d *decoder) listItem(l docLine) (interface{}, error) {
+	if l.text == "-" {
+		return NewOrderedMap(), nil
+	}
+	rest := l.text[2:]
+	if rest == "" {
+		next, ok := d.peek()
+		switch {
+		case ok && isEmptyBelow(next, l):
+			d.pos++
+			return parseScalar(next.text), nil
+		case ok && next.indent > l.indent:
+			return d.block(next.indent)
+		case ok && next.indent == l.indent && !isItem(next.text):
+			// DialectV1 writes objects and tables inside mixed lists at
+			// the indentation of their dash
+			return d.block(l.indent)
+		}
+		return "", nil
+	}
+	if isBlockMarker(rest) {
+		return d.blockScalar(rest, l.indent), nil
+	}
+	if raw, ok := d.heredoc(rest); ok {
+		return raw, nil
+	}
+
+	e, ok := d.parseEntry(l, rest)
+	if !ok {
+		return parseScalar(rest), nil
+	}
+	if e.keyless && e.list {
+		return d.listBody(e, l)
+	}
+	// the content of the item starts after its dash
+	inner := docLine{num: l.num, indent: l.indent + 2, text: rest}
+
+	// an object whose first entry is on the dash line and whose other
+	// entries are indented beneath it
+	obj := NewOrderedMap()
+	if err := d.addEntry(obj, e, inner); err != nil {
+		return nil, err
+	}
+	next, ok := d.peek()
+	if !ok || next.indent <= l.indent {
+		return obj, nil
+	}
+	others, err := d.object(next.indent)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range others.keys {
+		if !d.e.setKey(obj, k, others.values[k]) {
+			return nil, d.errorf(l, joinPath(d.path, k), "duplicate key %q", k)
+		}
+	}
+	return obj, nil
+}
+
+// listBody parses the list or table announced by a [N] header on line l
+func (d *decoder) listBody(e entryLine, l docLine) (interface{}, error) {
+	if e.fields != nil {
+		return d.table(e, l)
+	}
+	var items []interface{}
+	if e.value != "" {
+		// a list of primitives written inline as key[N]: a,b,c
+		row, err := scanRow(e.value, e.delim, d.e)
+		if err != nil {
+			return nil, d.cellError(l, len(l.text)-len(e.value), d.path, err)
+		}
+		items = row
+	} else if next, ok := d.peek(); ok && next.indent > l.indent {
+		list, err := d.list(next.indent)
+		if err != nil {
+			return nil, err
+		}
+		items = list
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	if e.count >= 0 && len(items) != e.count {
+		return nil, d.countError(l, 0, d.path, e.count, len(items), "list has %d items, header says %d")
+	}
+	return items, nil
+}
+
+// table parses the rows of a table. Rows are read by the count in the
+// header, as DialectV1 indents them by two spaces at any nesting level;
+// without a count they run up to the first line that is not a row. The
+// footers of WithMaxRows and WithSummaryRow are skipped, so a cut table
+// holds the rows it shows.
+func (d *decoder) table(e entryLine, l docLine) (interface{}, error) {
+	rows := []interface{}{}
+	err := d.tableRows(e, l, func(row *OrderedMap, _ string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// tableRows parses the rows of a table as table does, calling fn with each
+// row and its path as soon as it is read
+func (d *decoder) tableRows(e entryLine, l docLine, fn func(row *OrderedMap, path string) error) error {
+	if e.fieldsBelow {
+		d.pos++
+	}
+	rows, end := 0, d.pos
+	for e.count < 0 || rows < e.count {
+		if d.done() {
+			return d.err
+		}
+		if d.atEnd() {
+			if e.count < 0 {
+				break
+			}
+			return d.countError(l, 0, d.path, e.count, rows, "table has %d rows, header says %d")
+		}
+		line := d.lines[d.pos]
+		if m := overflowFooter.FindStringSubmatch(line.text); m != nil {
+			if more, _ := strconv.Atoi(m[1]); e.count < 0 || rows+more == e.count {
+				d.pos++
+				break
+			}
+		}
+		if e.count < 0 && (!d.isRow(line) || line.indent < e.minIndent || len(d.e.summary) > 0 && d.isSummary(line)) {
+			break
+		}
+		d.pos++
+
+		rowPath := indexPath(d.path, rows)
+		cells, err := scanCells(line.text, len(e.fields), e.delim, d.e)
+		if err != nil {
+			return d.cellError(line, 0, rowPath, err)
+		}
+		if len(cells) != len(e.fields) {
+			return d.countError(line, 0, rowPath, len(e.fields), len(cells), "row has %d cells, table has %d fields")
+		}
+		row := NewOrderedMap()
+		for i, field := range e.fields {
+			if cells[i] != missing && !d.e.setKey(row, field, cells[i]) {
+				return d.errorf(line, joinPath(rowPath, field), "duplicate key %q", field)
+			}
+		}
+		if err := d.subTables(row, line, rowPath); err != nil {
+			return err
+		}
+		end = d.pos
+		if err := fn(row, rowPath); err != nil {
+			return err
+		}
+		rows++
+	}
+	if d.tableEnd != nil {
+		d.tableEnd(e, l, rows, end)
+	}
+	if !d.atEnd() && d.isSummary(d.lines[d.pos]) {
+		d.pos++
+	}
+	return nil
+}
+
+// subTables reads the child tables that WithSubTables writes beneath the
+// row on line l, each one named after the path of its cell, such as
+// users[0].orders, and stores their rows in place of the [N] cells
+func (d *decoder) subTables(row *OrderedMap, l docLine, rowPath string) error {
+	for !d.atEnd() {
+		next := d.lines[d.pos]
+		if next.indent <= l.indent {
+			return nil
+		}
+		sub, ok := d.parseEntry(next, next.text)
+		field := strings.TrimPrefix(sub.key, rowPath+".")
+		if !ok || sub.fields == nil || field == sub.key {
+			return nil
+		}
+		if _, isCell := row.Get(field); !isCell {
+			return nil
+		}
+		d.pos++
+		// without a count, the rows end where the parent's rows resume
+		sub.minIndent = next.indent + 1
+
+		parentPath := d.path
+		d.path = sub.key
+		rows, err := d.table(sub, next)
+		d.path = parentPath
+		if err != nil {
+			return err
+		}
+		row.Set(field, rows)
+	}
+	return nil
+}
+
+// overflowFooter matches the line WithMaxRows writes in place of the rows it
+// leaves out
+var overflowFooter = regexp.MustCompile(`^` + ellipsis + ` \+(\d+) more rows?$`)
+
+// isSummary reports whether line is the footer of WithSummaryRow. Tables
+// without a count only end at one when the options include WithSummaryRow,
+// as a row could start the same way.
+func (d *decoder) isSummary(line docLine) bool {
+	if line.indent == 0 || !strings.HasPrefix(line.text, summaryMarker) {
+		return false
+	}
+	_, isEntry := parseEntry(line.text, d.e)
+	return !isEntry
+}
+
+// isRow reports whether line can be a row of a table without a count: an
+// indented line that is neither a list item nor an entry
+func (d *decoder) isRow(line docLine) bool {
+	if line.indent == 0 || strings.TrimSpace(line.text) == "" || isItem(line.text) {
+		return false
+	}
+	_, isEntry := parseEntry(line.text, d.e)
+	return !isEntry
+}
+
+// blockScalar reads the lines of a block scalar whose marker is on a line
+// indented by owner
+func (d *decoder) blockScalar(marker string, owner int) string {
+	var lines []string
+	indent := -1
+	for d.pos < len(d.lines) {
+		l := d.lines[d.pos]
+		blank := strings.TrimSpace(l.text) == ""
+		if !blank && l.indent <= owner {
+			break
+		}
+		d.pos++
+		if blank {
+			lines = append(lines, "")
+			continue
+		}
+		if indent < 0 {
+			indent = l.indent
+		}
+		lines = append(lines, strings.Repeat(" ", l.indent-min(indent, l.indent))+l.text)
+	}
+	if marker == ">-" {
+		return strings.Join(lines, " ")
+	}
+	s := strings.Join(lines, "\n")
+	if marker == "|" {
+		s += "\n"
+	}
+	return s
+}
+
+// heredocMarker matches the <<TAG that announces a raw value
+var heredocMarker = regexp.MustCompile(`^<<([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// heredoc reads a raw value announced by a <<TAG marker: the lines up to
+// the line that holds only TAG, taken as written, less the indentation of
+// that line. It reports false, reading nothing, when value is not a marker
+// or no such line follows, so a plain "<<TAG" string stays a string.
+func (d *decoder) heredoc(value string) (string, bool) {
+	m := heredocMarker.FindStringSubmatch(value)
+	if m == nil {
+		return "", false
+	}
+	end := d.pos
+	for end < len(d.lines) && strings.TrimRight(d.lines[end].text, " ") != m[1] {
+		end++
+	}
+	if end == len(d.lines) {
+		return "", false
+	}
+	strip := d.lines[end].indent
+	lines := make([]string, 0, end-d.pos)
+	for _, l := range d.lines[d.pos:end] {
+		lines = append(lines, strings.Repeat(" ", l.indent-min(strip, l.indent))+l.text)
+	}
+	d.pos = end + 1
+	return strings.Join(lines, "\n"), true
+}
+
+// isEmptyBelow reports whether next is the {} or [] that DialectV1 writes
+// unindented on the line below the key or dash of an empty value
+func isEmptyBelow(next, l docLine) bool {
+	return (next.text == "{}" || next.text == "[]") && (next.indent == 0 || next.indent > l.indent)
+}
+
+func isItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isBlockMarker(value string) bool {
+	return value == "|" || value == "|-" || value == ">-"
+}
+
+// entryLine is a parsed "key: value", "key:" or "key[N]{fields}:" line
+type entryLine struct {
+	key         string
+	keyless     bool     // the line starts with its [N] header, as root lists do
+	list        bool     // the key has a [N] header
+	count       int      // the N of the header, -1 when it has none
+	countAt     int      // the byte offset of N in the text, 0 when it has none
+	fields      []string // the columns of a table header
+	fieldsBelow bool     // the columns are on the next line, as TwoLineHeader writes them
+	delim       byte     // the cell delimiter the header declares
+	value       string   // the text after ": "
+	spaced      bool     // the colon is followed by a space
+	minIndent   int      // the least indent of a child table's rows
+}
+
+// listHeader matches the [N] or [N]{fields} that may follow a key, with a
+// tab or pipe after N declaring the delimiter
+var listHeader = regexp.MustCompile(`^\[(\d*)([\t|]?)\](\{(.*)\})?:( |$)`)
+
+// braceHeader matches the {fields} header written without length markers
+var braceHeader = regexp.MustCompile(`^\{(.*)\}:$`)
+
+// parenHeader matches the (N) header of ParenHeader, whose fields follow
+// the colon; without length markers the parentheses only hold a delimiter,
+// and a header written before its count was known leaves them empty
+var parenHeader = regexp.MustCompile(`^\((\d*[\t|]?)\):( |$)`)
+
+// parseEntry splits the entry on line l, whose text may be the part after
+// a list dash, and reads the columns of a table header that the header
+// style of the document puts after it
+func (d *decoder) parseEntry(l docLine, text string) (entryLine, bool) {
+	e, ok := parseEntry(text, d.e)
+	if !ok || e.fields != nil {
+		return e, ok
+	}
+	next, more := d.lineAfter(l)
+	if !more {
+		return e, ok
+	}
+	switch {
+	case d.e.headerStyle == TwoLineHeader && e.value == "" && !e.spaced && (e.list || d.e.noLengths):
+		// key[N]: over its a,b column line
+		if d.isColumnLine(next) {
+			e.list, e.fields, e.fieldsBelow = true, d.e.fieldNames(splitFields(next.text, e.delim)), true
+		}
+	case d.e.headerStyle == ParenHeader && d.e.noLengths && !e.list && e.value != "" && !isBlockMarker(e.value) && !heredocMarker.MatchString(e.value):
+		// ParenHeader without a count writes key: a,b over the rows
+		if d.isRow(next) {
+			e.list, e.fields, e.value = true, d.e.fieldNames(splitFields(e.value, e.delim)), ""
+		}
+	}
+	return e, ok
+}
+
+// isColumnLine reports whether next can be the column line of a
+// TwoLineHeader table rather than the start of a nested value
+func (d *decoder) isColumnLine(next docLine) bool {
+	if strings.TrimSpace(next.text) == "" || isItem(next.text) || next.text == "{}" || next.text == "[]" {
+		return false
+	}
+	_, isEntry := parseEntry(next.text, d.e)
+	return !isEntry
+}
+
+// parseEntry splits an object entry line, reporting false for lines that
+// are not entries. The header forms of WithLengthMarkers(false) and
+// ParenHeader are only read when e has those options.
+func parseEntry(text string, e *encoder) (entryLine, bool) {
+	entry := entryLine{count: -1, delim: ','}
+	rest := text
+	if key, n, ok := scanQuoted(text); ok {
+		entry.key, rest = key, text[n:]
+	} else {
+		end := keyEnd(text, e)
+		if end < 0 {
+			return entry, false
+		}
+		entry.key, rest = text[:end], text[end:]
+		entry.keyless = end == 0
+	}
+
+	if m := listHeader.FindStringSubmatch(rest); m != nil {
+		entry.list = true
+		if m[1] != "" {
+			entry.count, _ = strconv.Atoi(m[1])
+			entry.countAt = len(text) - len(rest) + 1
+		}
+		if m[2] != "" {
+			entry.delim = m[2][0]
+		}
+		if m[3] != "" {
+			entry.fields = e.fieldNames(splitFields(m[4], entry.delim))
+		}
+		rest = rest[len(m[0]):]
+		entry.value = rest
+		return entry, true
+	}
+	if m := braceHeader.FindStringSubmatch(rest); m != nil && e.noLengths {
+		entry.list = true
+		entry.fields = e.fieldNames(splitFields(m[1], entry.delim))
+		return entry, true
+	}
+	if m := parenHeader.FindStringSubmatch(rest); m != nil && e.headerStyle == ParenHeader {
+		entry.list = true
+		count := m[1]
+		if last := len(count) - 1; last >= 0 && (count[last] == '\t' || count[last] == '|') {
+			entry.delim, count = count[last], count[:last]
+		}
+		if count != "" {
+			entry.count, _ = strconv.Atoi(count)
+			entry.countAt = len(text) - len(rest) + 1
+		}
+		entry.fields = e.fieldNames(splitFields(rest[len(m[0]):], entry.delim))
+		return entry, true
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return entry, false
+	}
+	rest = rest[1:]
+	if rest != "" && rest[0] != ' ' {
+		return entry, false
+	}
+	entry.spaced = rest != ""
+	entry.value = strings.TrimPrefix(rest, " ")
+	return entry, true
+}
+
+// keyEnd returns the end of an unquoted key: the first colon followed by a
+// space or the end of the line, or the first header the options of e allow
+func keyEnd(text string, e *encoder) int {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case ':':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				return i
+			}
+		case '[':
+			if listHeader.MatchString(text[i:]) {
+				return i
+			}
+		case '{':
+			if e.noLengths && braceHeader.MatchString(text[i:]) {
+				return i
+			}
+		case '(':
+			if e.headerStyle == ParenHeader && parenHeader.MatchString(text[i:]) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitFields splits the columns of a table header at delim, unquoting
+// quoted names
+func splitFields(s string, delim byte) []string {
+	fields := []string{}
+	if s == "" {
+		return fields
+	}
+	for _, f := range splitTopLevel(s, delim) {
+		if name, n, ok := scanQuoted(f); ok && n == len(f) {
+			f = name
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// splitTopLevel splits s at sep outside of quotes
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if _, n, ok := scanQuoted(s[i:]); ok {
+				i += n - 1
+			}
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// scanQuoted reads the quoted string at the start of s, returning its
+// unescaped content and length. Unknown escapes are kept as written.
+func scanQuoted(s string) (string, int, bool) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", 0, false
+	}
+	q := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == q:
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// jsonNumber matches the numbers the encoder writes
+var jsonNumber = regexp.MustCompile(`^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?$`)
+
+// parseScalar decodes a value written on its own: a quoted or bare string,
+// a number, a bool, null, or an empty object or list
+func parseScalar(s string) interface{} {
+	switch s {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	case "{}":
+		return NewOrderedMap()
+	case "[]":
+		return []interface{}{}
+	}
+	if str, n, ok := scanQuoted(s); ok && n == len(s) {
+		return str
+	}
+	if jsonNumber.MatchString(s) {
+		// kept as written until the value tree is handed out
+		return json.Number(s)
+	}
+	return s
+}
+
+// missing marks an empty table cell, whose field is left out of the row
+var missing = &struct{}{}
+
+// inlineTableHeader matches the [N]{fields}: that starts an inline table
+// cell, and inlineBraceHeader the {fields}: written without length markers
+var (
+	inlineTableHeader = regexp.MustCompile(`^\[(\d+)\]\{([^}]*)\}:`)
+	inlineBraceHeader = regexp.MustCompile(`^\{([^}:]*)\}:`)
+)
+
+// cellScanner reads the comma-separated cells of a table row or inline
+// list, including the nested lists, objects and tables cells may hold
+type cellScanner struct {
+	s     string
+	i     int
+	delim byte     // the delimiter between the cells of the row
+	e     *encoder // holds the options the document was written with
+
+	// outside nested lists and objects, the bare words of WithMissingCell
+	// and WithBoolStyle are read as missing cells and booleans
+	labeled bool
+	// inside an inline table, the separator between its rows
+	rowSep string
+	// an error found in a nested value, which is not read as a string
+	err error
+}
+
+// scanCells reads the cells of a table row separated by delim; empty cells
+// are missing
+func scanCells(text string, fields int, delim byte, e *encoder) ([]interface{}, error) {
+	c := &cellScanner{s: text, delim: delim, e: e, labeled: true}
+	cells := make([]interface{}, 0, fields)
+	for {
+		start := c.i
+		v, err := c.cell(string(delim))
+		if err != nil {
+			return nil, &scanError{start, err}
+		}
+		cells = append(cells, v)
+		if c.i == len(c.s) {
+			return cells, nil
+		}
+		c.i++ // the delimiter
+	}
+}
+
+// scanRow reads the items of an inline list such as a,b,c separated by
+// delim
+func scanRow(text string, delim byte, e *encoder) ([]interface{}, error) {
+	cells, err := scanCells(text, 0, delim, e)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range cells {
+		if v == missing {
+			cells[i] = ""
+		}
+	}
+	return cells, nil
+}
+
+// cell reads one value ending before a byte in stop, a row separator or
+// the end of the text, returning missing for an empty one
+func (c *cellScanner) cell(stop string) (interface{}, error) {
+	if c.endsAt(c.i, stop) {
+		return missing, nil
+	}
+	start := c.i
+	switch c.s[c.i] {
+	case '"', '\'':
+		if str, n, ok := scanQuoted(c.s[c.i:]); ok && c.endsAt(c.i+n, stop) {
+			c.i += n
+			return str, nil
+		}
+	case '[':
+		if m := inlineTableHeader.FindStringSubmatch(c.s[c.i:]); m != nil {
+			c.i += len(m[0])
+			count, _ := strconv.Atoi(m[1])
+			return c.inlineTable(count, c.e.fieldNames(splitFields(m[2], ',')))
+		}
+		if v, ok := c.nested(']', stop); ok || c.err != nil {
+			return v, c.err
+		}
+	case '{':
+		if m := inlineBraceHeader.FindStringSubmatch(c.s[c.i:]); m != nil && c.e.noLengths {
+			c.i += len(m[0])
+			return c.inlineTable(-1, c.e.fieldNames(splitFields(m[1], ',')))
+		}
+		if v, ok := c.nested('}', stop); ok || c.err != nil {
+			return v, c.err
+		}
+	}
+	c.i = start
+	return c.bare(stop), nil
+}
+
+// endsAt reports whether a cell ending at i is followed by a stop byte, a
+// row separator or the end of the text
+func (c *cellScanner) endsAt(i int, stop string) bool {
+	return i == len(c.s) || strings.IndexByte(stop, c.s[i]) >= 0 ||
+		(c.rowSep != "" && strings.HasPrefix(c.s[i:], c.rowSep))
+}
+
+// bare reads an unquoted value
+func (c *cellScanner) bare(stop string) interface{} {
+	start := c.i
+	for !c.endsAt(c.i, stop) {
+		c.i++
+	}
+	text := c.s[start:c.i]
+	if c.labeled {
+		if c.e.missingSet && text == c.e.missingCell() {
+			return missing
+		}
+		if t, f := c.e.boolLabels(); c.e.boolStyle != TrueFalseBools && c.e.boolStyle != OneZeroBools {
+			switch text {
+			case t:
+				return true
+			case f:
+				return false
+			}
+		}
+	}
+	return parseScalar(text)
+}
+
+// nested reads an inline [a,b] list or {k:v} object, reporting false and
+// leaving the position to the caller when the text is not one
+func (c *cellScanner) nested(closer byte, stop string) (interface{}, bool) {
+	labeled, rowSep := c.labeled, c.rowSep
+	c.labeled, c.rowSep = false, ""
+	v, ok := c.nestedItems(closer)
+	c.labeled, c.rowSep = labeled, rowSep
+	if !ok || !c.endsAt(c.i, stop) {
+		return nil, false
+	}
+	return v, true
+}
+
+// nestedItems reads the items of a nested list or object up to its closer
+func (c *cellScanner) nestedItems(closer byte) (interface{}, bool) {
+	c.i++ // the opening bracket
+	var list []interface{}
+	obj := NewOrderedMap()
+	if c.i < len(c.s) && c.s[c.i] == closer {
+		c.i++
+		if closer == ']' {
+			return []interface{}{}, true
+		}
+		return obj, true
+	}
+	for {
+		if closer == '}' {
+			colon := strings.IndexByte(c.s[c.i:], ':')
+			if colon < 0 {
+				return nil, false
+			}
+			key := c.s[c.i : c.i+colon]
+			c.i += colon + 1
+			v, err := c.cell(",}")
+			if err != nil {
+				return nil, false
+			}
+			if !c.e.setKey(obj, key, orEmpty(v)) {
+				c.err = fmt.Errorf("duplicate key %q", key)
+				return nil, false
+			}
+		} else {
+			v, err := c.cell(",]")
+			if err != nil {
+				return nil, false
+			}
+			list = append(list, orEmpty(v))
+		}
+		if c.i == len(c.s) {
+			return nil, false
+		}
+		if c.s[c.i] == closer {
+			c.i++
+			break
+		}
+		if c.s[c.i] != ',' {
+			return nil, false
+		}
+		c.i++
+	}
+	if closer == ']' {
+		return list, true
+	}
+	return obj, true
+}
+
+// inlineTable reads the rows of a [N]{fields}: cell, whose rows are
+// separated by the nested row separator and cells by commas. Without a
+// count, count is -1 and the rows run as long as a separator follows.
+func (c *cellScanner) inlineTable(count int, fields []string) (interface{}, error) {
+	labeled, rowSep := c.labeled, c.rowSep
+	c.labeled, c.rowSep = true, c.e.nestedRowSeparator()
+	defer func() { c.labeled, c.rowSep = labeled, rowSep }()
+
+	rows := make([]interface{}, 0, max(count, 0))
+	for r := 0; count < 0 || r < count; r++ {
+		if r > 0 {
+			if !strings.HasPrefix(c.s[c.i:], c.rowSep) {
+				if count < 0 {
+					break
+				}
+				return nil, &cellCountError{"inline table has %d rows, header says %d", count, r}
+			}
+			c.i += len(c.rowSep)
+		}
+		row := NewOrderedMap()
+		for f, field := range fields {
+			if f > 0 {
+				if c.i == len(c.s) || c.s[c.i] != ',' {
+					return nil, &cellCountError{"inline table row has %d cells, table has %d fields", len(fields), f}
+				}
+				c.i++
+			}
+			v, err := c.cell("," + string(c.delim))
+			if err != nil {
+				return nil, err
+			}
+			if v != missing && !c.e.setKey(row, field, v) {
+				return nil, fmt.Errorf("duplicate key %q", field)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// scanError is an error of the cell starting at byte at of the scanned text
+type scanError struct {
+	at  int
+	err error
+}
+
+func (e *scanError) Error() string {
+	return e.err.Error()
+}
+
+func (e *scanError) Unwrap() error {
+	return e.err
+}
+
+// cellCountError is the count mismatch of an inline table, which the
+// decoder reports as a *CountMismatchError
+type cellCountError struct {
+	format           string // message taking the actual and expected counts
+	expected, actual int
+}
+
+func (e *cellCountError) Error() string {
+	return fmt.Sprintf(e.format, e.actual, e.expected)
+}
+
+func orEmpty(v interface{}) interface{} {
+	if v == missing {
+		return ""
+	}
+	return v
+}
+
+// plainValue replaces the OrderedMaps the decoder builds by plain maps,
+// and its json.Numbers by float64s
+func plainValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(val), 64)
+		return f
+	case *OrderedMap:
+		m := make(map[string]interface{}, len(val.keys))
+		for _, k := range val.keys {
+			m[k] = plainValue(val.values[k])
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = plainValue(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,428 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustJSON(t *testing.T, doc string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatalf("bad test JSON %s: %v", doc, err)
+	}
+	return v
+}
+
+func TestFromToon(t *testing.T) {
+	input := "name: Ann\nage: 30\nactive: true\nnote: null\n" +
+		"address:\n  city: Paris\n  zip: 75001\n" +
+		"tags:\n  - a\n  - b\n" +
+		"users[2]{id,name}:\n  1,Bob\n  2,\"Lee, Jr\""
+	result, err := FromToon(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := mustJSON(t, `{"name": "Ann", "age": 30, "active": true, "note": null,
+		"address": {"city": "Paris", "zip": 75001}, "tags": ["a", "b"],
+		"users": [{"id": 1, "name": "Bob"}, {"id": 2, "name": "Lee, Jr"}]}`)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got: %v", expected, result)
+	}
+}
+
+func TestFromToon_RoundTrip(t *testing.T) {
+	docs := []string{
+		`{"a": 1, "b": "x", "c": true, "d": null, "e": {"f": [1, 2, 3]}}`,
+		`{"users": [{"id": 1, "name": "Ann", "tags": ["a", "b"]}, {"id": 2, "name": "x:y; z", "tags": []}]}`,
+		`{"deep": {"team": {"members": [{"id": 1, "n": "a"}, {"id": 2, "n": "b"}]}}}`,
+		`{"rows": [{"id": 1, "sub": [{"x": 1, "y": "a,b"}, {"x": 2, "y": "c"}], "m": {"k": "v,w"}}]}`,
+		`{"list": [{"a": 1, "b": {"c": 2}}, {"a": 3}], "text": "line one\nline \"two\""}`,
+		`[1, "two", {"three": 3}, null, true]`,
+		`[{"a": 1, "b": "x"}, {"a": 2, "b": "y"}]`,
+		`"a, b"`,
+		`-2.5`,
+	}
+	variants := map[string][]Option{
+		"default":   nil,
+		"indent":    {WithIndent(4)},
+		"expanded":  {WithOneValuePerLine()},
+		"quotes":    {WithQuoteStyle(SingleQuotes)},
+		"literal":   {WithLiteralBlocks()},
+		"dialectV2": {WithDialect(DialectV2)},
+	}
+	for _, doc := range docs {
+		want := mustJSON(t, doc)
+		for name, opts := range variants {
+			encoded := ToToonWithOptions(want, opts...)
+			got, err := FromToon(encoded)
+			if err != nil {
+				t.Errorf("%s: %s: Unexpected error: %v", name, doc, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Expected %v, got: %v from %q", name, want, got, encoded)
+			}
+		}
+	}
+}
+
+func TestFromToonWithOptions_RoundTrip(t *testing.T) {
+	docs := []string{
+		`{"a": {"rows": [{"x": 1, "y": true, "n": [{"k": 1, "v": "p"}, {"k": 2, "v": false}]}, {"x": 2, "y": false, "n": "s"}]}, "b": 1, "t": [{"x": 3}, {"x": 4, "s": "-"}]}`,
+		`[{"x": 1, "y": "yes"}, {"x": 2}]`,
+		`[{"r": [{"x": 1}, {"x": 2}], "z": 1}, {"o": {"p": 1}}]`,
+	}
+	variants := map[string][]Option{
+		"noLengths":      {WithLengthMarkers(false)},
+		"paren":          {WithHeaderStyle(ParenHeader)},
+		"twoLine":        {WithHeaderStyle(TwoLineHeader)},
+		"parenNoLengths": {WithHeaderStyle(ParenHeader), WithLengthMarkers(false)},
+		"twoLineNoLen":   {WithHeaderStyle(TwoLineHeader), WithLengthMarkers(false)},
+		"pipeParen":      {WithHeaderStyle(ParenHeader), WithLengthMarkers(false), WithDelimiter(PipeDelimiter)},
+		"missing":        {WithMissingCell("-")},
+		"yesNo":          {WithBoolStyle(YesNoBools)},
+		"yn":             {WithBoolStyle(YNBools)},
+		"aliases":        {WithColumnAliases(map[string]string{"x": "X", "k": "K"})},
+		"separator":      {WithNestedRowSeparator(" | ")},
+		"sepNoLengths":   {WithNestedRowSeparator("~"), WithLengthMarkers(false)},
+		"enums":          {WithEnumLabels("x", map[interface{}]string{1: "one", 2: "two"})},
+	}
+	for _, doc := range docs {
+		want := mustJSON(t, doc)
+		for name, opts := range variants {
+			for _, dialect := range []Dialect{DialectV1, DialectV2} {
+				opts := append([]Option{WithDialect(dialect)}, opts...)
+				encoded := ToToonWithOptions(want, opts...)
+				got, err := FromToonWithOptions(encoded, opts...)
+				if err != nil {
+					t.Errorf("%s: %s: Unexpected error: %v from %q", name, doc, err, encoded)
+					continue
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: Expected %v, got: %v from %q", name, want, got, encoded)
+				}
+			}
+		}
+	}
+}
+
+func TestFromToonWithOptions_Labels(t *testing.T) {
+	doc := "orders[2]{id,st}:\n  1,shipped\n  2,new\nnote: shipped"
+	opts := []Option{
+		WithColumnAliases(map[string]string{"status": "st"}),
+		WithEnumLabels("orders.status", map[interface{}]string{2: "shipped", 1: "new"}),
+	}
+	result, err := FromToonWithOptions(doc, opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"orders": []interface{}{
+			map[string]interface{}{"id": 1.0, "status": 2.0},
+			map[string]interface{}{"id": 2.0, "status": 1.0},
+		},
+		"note": "shipped",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got: %v", expected, result)
+	}
+}
+
+func TestFromToonWithOptions_OneZeroBools(t *testing.T) {
+	result, err := FromToonWithOptions("[1]{a}:\n  1", WithBoolStyle(OneZeroBools))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []interface{}{map[string]interface{}{"a": 1.0}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got: %v", expected, result)
+	}
+}
+
+func TestFromToon_Footers(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"r": "eu", "a": 10},
+		map[string]interface{}{"r": "us", "a": 32.5},
+		map[string]interface{}{"r": "us", "a": 1},
+	}
+	all := []interface{}{
+		map[string]interface{}{"a": 10.0, "r": "eu"},
+		map[string]interface{}{"a": 32.5, "r": "us"},
+		map[string]interface{}{"a": 1.0, "r": "us"},
+	}
+	tests := []struct {
+		opts     []Option
+		expected []interface{}
+	}{
+		{[]Option{WithMaxRows(2)}, all[:2]},
+		{[]Option{WithSummaryRow(Sum("a"))}, all},
+		{[]Option{WithMaxRows(1), WithSummaryRow(Sum("a"), Distinct("r"))}, all[:1]},
+		{[]Option{WithMaxRows(2), WithLengthMarkers(false)}, all[:2]},
+		{[]Option{WithSummaryRow(Sum("a")), WithLengthMarkers(false)}, all},
+	}
+	for _, tt := range tests {
+		opts := append([]Option{withSortedKeys()}, tt.opts...)
+		doc := ToToonWithOptions(map[string]interface{}{"o": data, "z": 1}, opts...)
+		result, err := FromToonWithOptions(doc, opts...)
+		if err != nil {
+			t.Errorf("%q: Unexpected error: %v", doc, err)
+			continue
+		}
+		expected := map[string]interface{}{"o": tt.expected, "z": 1.0}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%q: Expected %v, got: %v", doc, expected, result)
+		}
+	}
+}
+
+func TestFromToon_DialectV2(t *testing.T) {
+	want := mustJSON(t, `{"ids": [1, 2], "flags": ["true", "", "-x"], "empty": {}, "none": [],
+		"matrix": [[1, 2], [], [3, [4]]], "mixed": [{"a": 1}, {"b": [1]}], "my key": "05"}`)
+	encoded := ToToonWithOptions(want, WithDialect(DialectV2))
+	got, err := FromToon(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got: %v from %q", want, got, encoded)
+	}
+}
+
+func TestFromToon_EmptyKey(t *testing.T) {
+	docs := []string{
+		`{"": [1, 2]}`,
+		`{"": [{"a": 1}, {"a": 2}], "b": 1}`,
+		`{"b": [3], "": [1, 2]}`,
+	}
+	for _, d := range []Dialect{DialectV1, DialectV2} {
+		for _, doc := range docs {
+			data := mustJSON(t, doc)
+			encoded := ToToonWithOptions(data, withSortedKeys(), WithDialect(d))
+			result, err := FromToon(encoded)
+			if err != nil {
+				t.Fatalf("Unexpected error decoding %q: %v", encoded, err)
+			}
+			if !reflect.DeepEqual(result, data) {
+				t.Errorf("Expected %v, got: %v (from %q)", data, result, encoded)
+			}
+		}
+	}
+}
+
+func TestFromToon_QuoteLikeStrings(t *testing.T) {
+	data := mustJSON(t, `{"a": "'s'", "b": "\"x\"", "c": "'q", "list": ["'s'", "\"x\""],
+		"rows": [{"v": "'s'"}, {"v": "\"x\""}]}`)
+	for _, d := range []Dialect{DialectV1, DialectV2} {
+		encoded := ToToonWithOptions(data, WithDialect(d))
+		result, err := FromToon(encoded)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %q: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(result, data) {
+			t.Errorf("Expected %v, got: %v (from %q)", data, result, encoded)
+		}
+	}
+}
+
+func TestFromToon_Blocks(t *testing.T) {
+	input := "poem: |\n  roses\n\n  violets\nsummary: >-\n  a long\n  line\nafter: 1"
+	result, err := FromToon(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{"poem": "roses\n\nviolets\n", "summary": "a long line", "after": float64(1)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got: %v", expected, result)
+	}
+}
+
+func TestFromToon_Cells(t *testing.T) {
+	input := "rows[2]{id,tags,meta,extra}:\n" +
+		"  1,[a,\"b,c\"],{k:v,n:2},\n" +
+		"  2,[],{},[1]{x,y}:1,\"p;q\""
+	result, err := FromToon(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := mustJSON(t, `{"rows": [
+		{"id": 1, "tags": ["a", "b,c"], "meta": {"k": "v", "n": 2}},
+		{"id": 2, "tags": [], "meta": {}, "extra": [{"x": 1, "y": "p;q"}]}]}`)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got: %v", expected, result)
+	}
+}
+
+func TestFromToon_Empty(t *testing.T) {
+	tests := map[string]interface{}{
+		"":             map[string]interface{}{},
+		"{}":           map[string]interface{}{},
+		"[]":           []interface{}{},
+		"[0]:":         []interface{}{},
+		"a: \n{}":      map[string]interface{}{"a": map[string]interface{}{}},
+		"a:\nb[0]:":    map[string]interface{}{"a": map[string]interface{}{}, "b": []interface{}{}},
+		"a: \nb: \"\"": map[string]interface{}{"a": "", "b": ""},
+	}
+	for input, expected := range tests {
+		result, err := FromToon(input)
+		if err != nil {
+			t.Errorf("%q: Unexpected error: %v", input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%q: Expected %v, got: %v", input, expected, result)
+		}
+	}
+}
+
+func TestFromToon_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+		msg   string
+	}{
+		{"users[3]{id}:\n  1\n  2", 1, "table has 2 rows, header says 3"},
+		{"users[2]{id,name}:\n  1,Ann\n  2", 3, "row has 1 cells, table has 2 fields"},
+		{"a: 1\n    b: 2", 2, "unexpected indentation"},
+		{"a: 1\na: 2", 2, `duplicate key "a"`},
+		{"a:\n  b: 1\n  oops", 3, `expected key: value, got "oops"`},
+		{"ids[3]: 1,2", 1, "list has 2 items, header says 3"},
+		{"- 1\nkey: 2", 2, `unexpected "key: 2"`},
+		{"rows[1]{a}:\n  [2]{x}:1", 2, "inline table has 1 rows, header says 2"},
+	}
+	for _, tt := range tests {
+		_, err := FromToon(tt.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: Expected a *SyntaxError, got: %v", tt.input, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Msg != tt.msg {
+			t.Errorf("%q: Expected line %d %q, got: line %d %q", tt.input, tt.line, tt.msg, syntaxErr.Line, syntaxErr.Msg)
+		}
+	}
+	_, err := FromToon("a: 1\na: 2")
+	if expected := `totoon: line 2: duplicate key "a"`; err.Error() != expected {
+		t.Errorf("Expected %q, got: %q", expected, err.Error())
+	}
+}
+
+func TestFromToon_SyntaxErrorLocation(t *testing.T) {
+	tests := []struct {
+		input        string
+		line, column int
+		offset       int
+		path         string
+	}{
+		{"a: 1\n    b: 2", 2, 5, 9, ""},
+		{"a:\n  b: 1\n  oops", 3, 3, 12, "a"},
+		{"a: 1\r\na: 2", 2, 1, 6, "a"},
+		{"rows[1]{a}:\n  [2]{x}:1", 2, 3, 14, "rows[0]"},
+		{"rows[1]{a,b}:\n  1,{y:1,y:2}", 2, 5, 18, "rows[0]"},
+		{"ids[3]: 1,2", 1, 1, 0, "ids"},
+	}
+	for _, tt := range tests {
+		_, err := FromToon(tt.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: Expected a *SyntaxError, got: %v", tt.input, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column || syntaxErr.Offset != tt.offset || syntaxErr.Path != tt.path {
+			t.Errorf("%q: Expected %d:%d at offset %d and %q, got: %+v", tt.input, tt.line, tt.column, tt.offset, tt.path, syntaxErr)
+		}
+	}
+}
+
+func TestFromToon_CountMismatch(t *testing.T) {
+	tests := []struct {
+		input            string
+		line             int
+		path             string
+		expected, actual int
+	}{
+		{"users[3]{id}:\n  1\n  2", 1, "users", 3, 2},
+		{"a:\n  users[2]{id,name}:\n    1,Ann\n    2", 4, "a.users[1]", 2, 1},
+		{"- ids[3]: 1,2", 1, "[0].ids", 3, 2},
+		{"rows[1]{a}:\n  [2]{x}:1", 2, "rows[0]", 2, 1},
+	}
+	for _, tt := range tests {
+		_, err := FromToon(tt.input)
+		var countErr *CountMismatchError
+		if !errors.As(err, &countErr) {
+			t.Errorf("%q: Expected a *CountMismatchError, got: %v", tt.input, err)
+			continue
+		}
+		if countErr.Line != tt.line || countErr.Path != tt.path || countErr.Expected != tt.expected || countErr.Actual != tt.actual {
+			t.Errorf("%q: Expected line %d at %q, %d for %d, got: %+v", tt.input, tt.line, tt.path, tt.actual, tt.expected, countErr)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"", true},
+		{"a: 1\nusers[2]{id}:\n  1\n  2", true},
+		{"- 1\n- x: 2", true},
+		{"users[3]{id}:\n  1", false},
+		{"a: 1\na: 2", false},
+		{"a: 1\n    b: 2", false},
+	}
+	for _, tt := range tests {
+		if valid := Valid([]byte(tt.input)); valid != tt.valid {
+			t.Errorf("%q: Expected %v, got: %v", tt.input, tt.valid, valid)
+		}
+	}
+}
+
+func TestFromToon_Heredoc(t *testing.T) {
+	doc := "re: <<RE\n^\\s*\"(a|b)\": [x, y]$\nRE\nitems:\n  - <<EOF\n    if x:\n      key: value\n\n    EOF\n  - <<EOF\nplain: <<NOPE"
+	result, err := FromToon(doc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"re":    `^\s*"(a|b)": [x, y]$`,
+		"items": []interface{}{"if x:\n  key: value\n", "<<EOF"},
+		"plain": "<<NOPE",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestFromToon_BlockMarkerStrings(t *testing.T) {
+	data := map[string]interface{}{"a": "|", "b": "|-", "c": ">-", "list": []interface{}{"|", ">-"}}
+	for _, d := range []Dialect{DialectV1, DialectV2} {
+		doc := ToToonWithOptions(data, WithDialect(d))
+		result, err := FromToon(doc)
+		if err != nil {
+			t.Fatalf("%q: Unexpected error: %v", doc, err)
+		}
+		if !reflect.DeepEqual(result, data) {
+			t.Errorf("Expected %q, got: %q from %q", data, result, doc)
+		}
+	}
+}
+
+func TestFromToon_HeredocMarkerStrings(t *testing.T) {
+	data := map[string]interface{}{
+		"a": "<<EOF",
+		"t": []interface{}{map[string]interface{}{"x": "EOF"}, map[string]interface{}{"x": "y"}},
+	}
+	for _, d := range []Dialect{DialectV1, DialectV2} {
+		doc := ToToonWithOptions(data, WithDialect(d))
+		result, err := FromToon(doc)
+		if err != nil {
+			t.Fatalf("%q: Unexpected error: %v", doc, err)
+		}
+		if !reflect.DeepEqual(result, data) {
+			t.Errorf("Expected %q, got: %q from %q", data, result, doc)
+		}
+	}
+}
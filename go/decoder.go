@@ -0,0 +1,171 @@
+package totoon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Decoder reads a sequence of TOON documents from an input stream, like
+// json.Decoder. Documents are separated by DocumentSeparator lines, as
+// Encoder writes them, and only the document being decoded is held in
+// memory.
+type Decoder struct {
+	r    *bufio.Reader
+	e    *encoder // holds the options the documents were written with
+	line int      // lines read so far
+	read int      // bytes read so far
+	eof  bool
+	err  error
+
+	next       string // the following document, already read
+	nextStart  int    // line of the stream it starts at
+	nextOffset int    // byte offset of the stream it starts at
+	hasNext    bool
+}
+
+// NewDecoder returns a Decoder that reads from r. Documents written with
+// options that change the syntax are read by passing the same options, as
+// FromToonWithOptions describes.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), e: newEncoder(opts)}
+}
+
+// More reports whether another document follows. It also reports true
+// after a read error, which the next Decode returns.
+func (dec *Decoder) More() bool {
+	dec.fill(nil)
+	return dec.hasNext || dec.err != nil
+}
+
+// Decode reads the next document and stores it in the value pointed to by
+// v, as Unmarshal does. It returns io.EOF once the stream holds no more
+// documents. The line and offset of a *SyntaxError count from the start
+// of the stream.
+func (dec *Decoder) Decode(v interface{}) error {
+	return dec.DecodeContext(context.Background(), v)
+}
+
+// DecodeContext is Decode with a context, so that reading and parsing a
+// large document can be cancelled or given a deadline: it returns the error
+// of ctx once ctx is done. A cancelled read ends the stream, as its next
+// document is only partly read; a read blocked in the underlying reader
+// only returns when that read does.
+func (dec *Decoder) DecodeContext(ctx context.Context, v interface{}) error {
+	doc, err := dec.document(ctx)
+	if err != nil {
+		return err
+	}
+	return dec.e.storeDocument(doc, v)
+}
+
+// document reads and parses the next document
+func (dec *Decoder) document(ctx context.Context) (interface{}, error) {
+	dec.fill(ctx)
+	if !dec.hasNext {
+		if dec.err != nil {
+			return nil, dec.err
+		}
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, io.EOF
+	}
+	dec.hasNext = false
+
+	d := newDecoder(dec.next, dec.e)
+	d.ctx = ctx
+	doc, err := d.document()
+	if err != nil {
+		var syntax *SyntaxError
+		if errors.As(err, &syntax) {
+			syntax.Line += dec.nextStart - 1
+			syntax.Offset += dec.nextOffset
+		}
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fill reads the following document up to the next separator line, unless
+// it has been read already or ctx is done. A blank document at the end of
+// the stream does not count.
+func (dec *Decoder) fill(ctx context.Context) {
+	if dec.hasNext || dec.eof {
+		return
+	}
+	var b strings.Builder
+	start, offset := dec.line+1, dec.read
+	separated := false
+	for !separated {
+		if ctx != nil && ctx.Err() != nil {
+			if b.Len() > 0 {
+				dec.eof, dec.err = true, ctx.Err()
+			}
+			return
+		}
+		line, err := dec.readLine(b.Len())
+		if err == errDocumentTooLarge {
+			dec.eof = true
+			dec.err = fmt.Errorf("totoon: document at line %d is larger than %d bytes", start, dec.e.maxDocSize)
+			return
+		}
+		if line != "" {
+			dec.line++
+			dec.read += len(line)
+			if strings.TrimRight(line, "\r\n") == DocumentSeparator {
+				separated = true
+			} else {
+				b.WriteString(line)
+			}
+		}
+		if err != nil {
+			dec.eof = true
+			if err != io.EOF {
+				dec.err = err
+				return
+			}
+			break
+		}
+	}
+	if separated || strings.TrimSpace(b.String()) != "" {
+		dec.next, dec.nextStart, dec.nextOffset, dec.hasNext = b.String(), start, offset, true
+	}
+}
+
+var errDocumentTooLarge = errors.New("document too large")
+
+// readLine reads the next line of the stream, including its newline. Under
+// WithMaxDocumentSize it stops with errDocumentTooLarge as soon as the line
+// would take a document of size bytes beyond the limit, so an oversized
+// line is never held in full.
+func (dec *Decoder) readLine(size int) (string, error) {
+	if dec.e.maxDocSize <= 0 {
+		return dec.r.ReadString('\n')
+	}
+	var line []byte
+	for {
+		chunk, err := dec.r.ReadSlice('\n')
+		line = append(line, chunk...)
+		// the separator line that ends the document does not count
+		if size+len(line) > dec.e.maxDocSize && strings.TrimRight(string(line), "\r\n") != DocumentSeparator {
+			return "", errDocumentTooLarge
+		}
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+// WithMaxDocumentSize makes Decoder fail once a document of its stream
+// grows beyond n bytes, without reading the rest of it, to protect services
+// from oversized or runaway uploads. The error is returned by Decode and
+// ends the stream. Zero or a negative n sets no limit.
+func WithMaxDocumentSize(n int) Option {
+	return func(o *options) {
+		o.maxDocSize = n
+	}
+}
@@ -0,0 +1,194 @@
+package totoon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder(t *testing.T) {
+	input := "a:\n  b: 1\n---\n[2]{id}:\n  1\n  2\n---\ndone\n"
+	dec := NewDecoder(strings.NewReader(input))
+	var got []interface{}
+	for dec.More() {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, v)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"a": map[string]interface{}{"b": float64(1)}},
+		[]interface{}{map[string]interface{}{"id": float64(1)}, map[string]interface{}{"id": float64(2)}},
+		"done",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got: %v", expected, got)
+	}
+	if err := dec.Decode(new(interface{})); err != io.EOF {
+		t.Errorf("Expected io.EOF, got: %v", err)
+	}
+}
+
+func TestDecoder_EncoderRoundTrip(t *testing.T) {
+	type event struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	events := []event{{9007199254740993, "start"}, {2, "stop"}}
+
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	dec := NewDecoder(strings.NewReader(buf.String()))
+	var got []event
+	for dec.More() {
+		var ev event
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, ev)
+	}
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected %v, got: %v", events, got)
+	}
+}
+
+func TestDecoder_Options(t *testing.T) {
+	opts := []Option{WithHeaderStyle(TwoLineHeader), WithBoolStyle(YesNoBools)}
+	docs := []interface{}{
+		[]interface{}{map[string]interface{}{"id": 1.0, "ok": true}, map[string]interface{}{"id": 2.0, "ok": false}},
+		map[string]interface{}{"t": []interface{}{map[string]interface{}{"id": 3.0, "ok": true}}},
+	}
+	var buf strings.Builder
+	enc := NewEncoder(&buf, opts...)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	dec := NewDecoder(strings.NewReader(buf.String()), opts...)
+	var got []interface{}
+	for dec.More() {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, docs) {
+		t.Errorf("Expected %v, got: %v from %q", docs, got, buf.String())
+	}
+}
+
+func TestDecoder_Empty(t *testing.T) {
+	for _, input := range []string{"", "\n\n"} {
+		dec := NewDecoder(strings.NewReader(input))
+		if dec.More() {
+			t.Errorf("Expected no documents in %q", input)
+		}
+		if err := dec.Decode(new(interface{})); err != io.EOF {
+			t.Errorf("Expected io.EOF for %q, got: %v", input, err)
+		}
+	}
+}
+
+func TestDecoder_SyntaxErrorLine(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a: 1\n---\nb: 1\nb: 2\n"))
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := dec.Decode(&v)
+	var syntax *SyntaxError
+	if !errors.As(err, &syntax) || syntax.Line != 4 || syntax.Offset != 14 {
+		t.Errorf("Expected a SyntaxError on line 4 at offset 14, got: %+v", err)
+	}
+}
+
+func TestDecoder_ReadError(t *testing.T) {
+	dec := NewDecoder(iotest.TimeoutReader(strings.NewReader("a: 1\n")))
+	if !dec.More() {
+		t.Fatal("Expected More to report the read error")
+	}
+	if err := dec.Decode(new(interface{})); err != iotest.ErrTimeout {
+		t.Errorf("Expected the read error, got: %v", err)
+	}
+}
+
+func TestDecoder_SeparatorString(t *testing.T) {
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	docs := []interface{}{"---", "x"}
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if expected := "\"---\"\n---\nx\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+
+	dec := NewDecoder(strings.NewReader(buf.String()))
+	var got []interface{}
+	for dec.More() {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, docs) {
+		t.Errorf("Expected %v, got: %v", docs, got)
+	}
+}
+
+func TestDecoder_MaxDocumentSize(t *testing.T) {
+	input := "a: 1\n---\nb: " + strings.Repeat("x", 10000) + "\n---\nc: 3\n"
+	dec := NewDecoder(strings.NewReader(input), WithMaxDocumentSize(100))
+	var first interface{}
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := map[string]interface{}{"a": 1.0}; !reflect.DeepEqual(first, expected) {
+		t.Errorf("Expected %v, got: %v", expected, first)
+	}
+	err := dec.Decode(new(interface{}))
+	if err == nil || err.Error() != "totoon: document at line 3 is larger than 100 bytes" {
+		t.Fatalf("Expected a size error, got: %v", err)
+	}
+	if err2 := dec.Decode(new(interface{})); err2 != err {
+		t.Errorf("Expected the size error to end the stream, got: %v", err2)
+	}
+}
+
+func TestDecoder_DecodeContext(t *testing.T) {
+	input := "a: 1\n---\nrows[3]{id}:\n  1\n  2\n  3\n"
+	dec := NewDecoder(strings.NewReader(input))
+	if err := dec.DecodeContext(context.Background(), new(interface{})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dec.DecodeContext(ctx, new(interface{})); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	// cancellation during parsing
+	d := newDecoder("rows[3]{id}:\n  1\n  2\n  3", newEncoder(nil))
+	d.ctx = ctx
+	if _, err := d.document(); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package totoon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecodeTable reads the table under the top-level key of the TOON document
+// in r and calls fn with each of its rows, stored in a new T as Unmarshal
+// stores a value. Rows are read and stored one at a time, so a large table
+// is never held in memory whole; the rest of the document is skipped
+// without being parsed. Documents written with options that change the
+// syntax are read by passing the same options, as FromToonWithOptions
+// describes, and WithMaxDocumentSize limits the length of each line.
+//
+// DecodeTable stops at the first error, which it returns: a *SyntaxError or
+// *CountMismatchError for a malformed table, a *TypeError whose path starts
+// at the row for a row that does not fit T, or the error returned by fn. It
+// also fails when the document has no table under key. Only the first
+// document of a stream of documents is read.
+func DecodeTable[T any](r io.Reader, key string, fn func(T) error, opts ...Option) error {
+	e := newEncoder(opts)
+	lines := &lineReader{dec: NewDecoder(r, opts...)}
+	d := &decoder{e: e, path: key, more: lines.next}
+
+	header, l, err := d.findTable(key)
+	if err == nil {
+		err = d.tableRows(header, l, func(row *OrderedMap, path string) error {
+			var v T
+			if err := e.storeDocument(row, &v); err != nil {
+				var typeErr *TypeError
+				if errors.As(err, &typeErr) && typeErr.Path == "" {
+					typeErr.Path = path
+				} else if typeErr != nil {
+					typeErr.Path = joinPath(path, typeErr.Path)
+				}
+				return err
+			}
+			return fn(v)
+		})
+	}
+	// a read error ends the document early, which is reported instead
+	if lines.err != nil {
+		return lines.err
+	}
+	return err
+}
+
+// findTable skips the lines of the document up to the header of the table
+// under the top-level key and returns it, leaving d past that header
+func (d *decoder) findTable(key string) (entryLine, docLine, error) {
+	for !d.atEnd() {
+		l := d.lines[d.pos]
+		text := l.text
+		if isItem(text) {
+			text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), " ")
+		}
+		entry, ok := parseEntry(text, d.e)
+		if ok && l.indent == 0 && text == l.text && entry.key == key && !entry.keyless {
+			if entry, _ = d.parseEntry(l, l.text); entry.fields == nil {
+				return entry, l, fmt.Errorf("totoon: %s is not a table", key)
+			}
+			d.pos++
+			return entry, l, nil
+		}
+		d.pos++
+		if ok {
+			d.skipRaw(entry.value)
+		}
+	}
+	return entryLine{}, docLine{}, fmt.Errorf("totoon: no table %s in the document", key)
+}
+
+// skipRaw skips the lines of a raw value if value opens one. Without its
+// closing line the marker is a plain string, and the lines are read again.
+func (d *decoder) skipRaw(value string) {
+	m := heredocMarker.FindStringSubmatch(value)
+	if m == nil {
+		return
+	}
+	var skipped []docLine
+	for !d.atEnd() {
+		l := d.lines[d.pos]
+		d.pos++
+		if strings.TrimRight(l.text, " ") == m[1] {
+			return
+		}
+		skipped = append(skipped, l)
+	}
+	d.lines, d.pos = skipped, 0
+}
+
+// lineReader reads the lines of the first document of a stream for a
+// streamed decoder
+type lineReader struct {
+	dec    *Decoder
+	num    int
+	offset int
+	done   bool
+	err    error // a read error, which ends the document
+}
+
+func (r *lineReader) next() (docLine, bool) {
+	if r.done {
+		return docLine{}, false
+	}
+	line, err := r.dec.readLine(0)
+	if err != nil {
+		r.done = true
+		switch {
+		case err == errDocumentTooLarge:
+			r.err = fmt.Errorf("totoon: line %d is longer than %d bytes", r.num+1, r.dec.e.maxDocSize)
+			return docLine{}, false
+		case err != io.EOF:
+			r.err = err
+			return docLine{}, false
+		case line == "":
+			return docLine{}, false
+		}
+	}
+	if strings.TrimRight(line, "\r\n") == DocumentSeparator {
+		r.done = true
+		return docLine{}, false
+	}
+
+	r.num++
+	l := docLine{num: r.num, offset: r.offset}
+	r.offset += len(line)
+	text := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	l.text = strings.TrimLeft(text, " ")
+	l.indent = len(text) - len(l.text)
+	return l, true
+}
@@ -0,0 +1,127 @@
+package totoon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type tableUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDecodeTable(t *testing.T) {
+	doc := "title: Users\nusers[3]{id,name}:\n  1,Ann\n  2,Bob\n  3,Cy\nfooter: end"
+	var users []tableUser
+	err := DecodeTable(strings.NewReader(doc), "users", func(u tableUser) error {
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []tableUser{{1, "Ann"}, {2, "Bob"}, {3, "Cy"}}
+	if fmt.Sprint(users) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got: %v", expected, users)
+	}
+}
+
+// endlessTable is a table without a count whose rows never end
+type endlessTable struct {
+	header bool
+	row    int
+	buf    []byte
+}
+
+func (r *endlessTable) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if !r.header {
+			r.header, r.buf = true, []byte("users[]{id,name}:\n")
+		} else {
+			r.row++
+			r.buf = []byte(fmt.Sprintf("  %d,user-%d\n", r.row, r.row))
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestDecodeTable_Streams(t *testing.T) {
+	stop := errors.New("stop")
+	seen := 0
+	err := DecodeTable(&endlessTable{}, "users", func(u tableUser) error {
+		seen++
+		if u.ID != seen {
+			t.Fatalf("Expected row %d, got: %v", seen, u)
+		}
+		if seen == 10000 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Expected the error of fn, got: %v", err)
+	}
+}
+
+func TestDecodeTable_SkipsOtherSections(t *testing.T) {
+	doc := "note: <<EOF\nusers[1]{id,name}:\n  9,Fake\nEOF\nmeta:\n  users: 2\n- users[1]{id}:\n    8\nusers:\n  id,name\n  1,Ann"
+	var users []tableUser
+	err := DecodeTable(strings.NewReader(doc), "users", func(u tableUser) error {
+		users = append(users, u)
+		return nil
+	}, WithHeaderStyle(TwoLineHeader), WithLengthMarkers(false))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0] != (tableUser{1, "Ann"}) {
+		t.Errorf("Expected only the top-level table, got: %v", users)
+	}
+}
+
+func TestDecodeTable_Errors(t *testing.T) {
+	ignore := func(tableUser) error { return nil }
+	tests := []struct {
+		doc  string
+		line int
+		path string
+	}{
+		{"a: 1\nusers[2]{id,name}:\n  1,Ann", 2, "users"},
+		{"users[2]{id,name}:\n  1,Ann\n  2", 3, "users[1]"},
+		{"users[1]{id,id}:\n  1,2", 2, "users[0].id"},
+	}
+	for _, tt := range tests {
+		err := DecodeTable(strings.NewReader(tt.doc), "users", ignore)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != tt.line || syntaxErr.Path != tt.path {
+			t.Errorf("%q: Expected a *SyntaxError on line %d at %s, got: %v", tt.doc, tt.line, tt.path, err)
+		}
+	}
+
+	err := DecodeTable(strings.NewReader("users[2]{id,name}:\n  1,Ann\n  x,Bob"), "users", ignore)
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) || typeErr.Path != "users[1].id" {
+		t.Errorf("Expected a *TypeError at users[1].id, got: %v", err)
+	}
+	if err := DecodeTable(strings.NewReader("users: 1"), "users", ignore); err == nil || !strings.Contains(err.Error(), "not a table") {
+		t.Errorf("Expected an error for a value that is not a table, got: %v", err)
+	}
+	if err := DecodeTable(strings.NewReader("a: 1\n---\nusers[1]{id}:\n  1"), "users", ignore); err == nil || !strings.Contains(err.Error(), "no table") {
+		t.Errorf("Expected an error for a missing table, got: %v", err)
+	}
+	if err := DecodeTable(io.MultiReader(strings.NewReader("users[2]{id}:\n  1\n"), errReader{}), "users", ignore); err != errRead {
+		t.Errorf("Expected the read error, got: %v", err)
+	}
+}
+
+var errRead = errors.New("read failed")
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errRead
+}
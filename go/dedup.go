@@ -0,0 +1,55 @@
+package totoon
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// dedupCountField is the column added by WithDedupCounts
+const dedupCountField = "×"
+
+// dedupRows removes exact-duplicate rows, preserving first-occurrence order
+func (e *encoder) dedupRows(data []interface{}) []interface{} {
+	var unique []interface{}
+	var counts []int
+	seen := make(map[string]int)
+
+	for _, item := range data {
+		key := rowIdentity(item)
+		if idx, exists := seen[key]; exists {
+			counts[idx]++
+			continue
+		}
+		seen[key] = len(unique)
+		unique = append(unique, item)
+		counts = append(counts, 1)
+	}
+
+	if !e.dedupCount {
+		return unique
+	}
+
+	for i, item := range unique {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		counted := make(map[string]interface{}, len(obj)+1)
+		for k, v := range obj {
+			counted[k] = v
+		}
+		counted[dedupCountField] = counts[i]
+		unique[i] = counted
+	}
+	return unique
+}
+
+// rowIdentity returns a key that is equal for structurally equal rows.
+// encoding/json sorts map keys, which makes it a convenient canonical form.
+func rowIdentity(row interface{}) string {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Sprintf("%#v", row)
+	}
+	return string(b)
+}
@@ -0,0 +1,45 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithDedupRows(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"event": "login"},
+		map[string]interface{}{"event": "logout"},
+		map[string]interface{}{"event": "login"},
+	}
+	result := ToToonWithOptions(data, WithDedupRows())
+	if result != "[2]{event}:\n  login\n  logout" {
+		t.Errorf("Expected duplicate row to be dropped, got: %q", result)
+	}
+}
+
+func TestWithDedupRows_NestedValues(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"tags": []interface{}{"b", "a"}},
+	}
+	result := ToToonWithOptions(data, WithDedupRows())
+	if !strings.HasPrefix(result, "[2]{tags}:") {
+		t.Errorf("Expected 2 unique rows, got: %s", result)
+	}
+}
+
+func TestWithDedupCounts(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"event": "login"},
+		map[string]interface{}{"event": "login"},
+		map[string]interface{}{"event": "logout"},
+	}
+	result := ToToonWithOptions(data, WithDedupCounts())
+	if !strings.Contains(result, "[2]{") || !strings.Contains(result, "×") {
+		t.Errorf("Expected 2 rows with a count column, got: %s", result)
+	}
+	if !strings.Contains(result, "login,2") && !strings.Contains(result, "2,login") {
+		t.Errorf("Expected login row with count 2, got: %s", result)
+	}
+}
@@ -0,0 +1,27 @@
+package totoon
+
+import "sync"
+
+var defaults struct {
+	sync.RWMutex
+	opts []Option
+}
+
+// SetDefaults sets options that apply to every conversion in the package,
+// ahead of the options passed to each call, so applications can configure
+// indentation, key order and the like once at startup. Each call replaces
+// the previous defaults; SetDefaults() with no options clears them. It is
+// safe to call concurrently with conversions, which use the defaults in
+// effect when they start.
+func SetDefaults(opts ...Option) {
+	defaults.Lock()
+	defer defaults.Unlock()
+	defaults.opts = append([]Option(nil), opts...)
+}
+
+// defaultOpts returns the options set by SetDefaults
+func defaultOpts() []Option {
+	defaults.RLock()
+	defer defaults.RUnlock()
+	return defaults.opts
+}
@@ -0,0 +1,62 @@
+package totoon
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetDefaults(t *testing.T) {
+	SetDefaults(WithIndent(4), WithKeyOrder("b", "a"))
+	defer SetDefaults()
+
+	data := map[string]interface{}{"a": map[string]interface{}{"x": 1}, "b": 2}
+	if result, expected := ToToon(data), "b: 2\na:\n    x: 1"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// options passed to a call are applied after the defaults
+	if result, expected := ToToonWithOptions(data, WithIndent(1)), "b: 2\na:\n x: 1"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	SetDefaults()
+	if result, expected := ToToonWithOptions(data, withSortedKeys()), "a:\n  x: 1\nb: 2"; result != expected {
+		t.Errorf("Expected %q after clearing the defaults, got: %q", expected, result)
+	}
+}
+
+func TestSetDefaults_CanonicalBytes(t *testing.T) {
+	data := map[string]interface{}{"b": []interface{}{1, 2}, "a": 1.5}
+	expected, err := CanonicalBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	SetDefaults(WithIndent(4), WithKeyOrder("b"), WithQuoteStyle(SingleQuotes))
+	defer SetDefaults()
+	result, err := CanonicalBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result) != string(expected) {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestSetDefaults_Concurrent(t *testing.T) {
+	defer SetDefaults()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(indent int) {
+			defer wg.Done()
+			SetDefaults(WithIndent(indent))
+		}(i)
+		go func() {
+			defer wg.Done()
+			ToToon(map[string]interface{}{"a": []interface{}{1, 2}})
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,63 @@
+package totoon
+
+// Delimiter selects the separator between the cells of table rows
+type Delimiter int
+
+const (
+	// CommaDelimiter separates cells with commas (the default)
+	CommaDelimiter Delimiter = iota
+	// TabDelimiter separates cells with tabs, which tokenize well and leave
+	// commas in values unquoted
+	TabDelimiter
+	// PipeDelimiter separates cells with "|"
+	PipeDelimiter
+)
+
+// delimiterText holds the separator written for each Delimiter
+var delimiterText = [...]string{
+	CommaDelimiter: ",",
+	TabDelimiter:   "\t",
+	PipeDelimiter:  "|",
+}
+
+// rowDelimiters are the bytes that make a table cell quoted, by Delimiter
+var rowDelimiters = [...]*byteSet{
+	CommaDelimiter: cellDelimiters,
+	TabDelimiter:   newByteSet("\t:;\n"),
+	PipeDelimiter:  newByteSet("|:;\n"),
+}
+
+// WithDelimiter sets the separator between the cells of table rows and the
+// fields of their headers. As the TOON spec requires, a tab or pipe is
+// declared in the brackets of the header, key[N|]{a|b}:, or alone as
+// key[|]{a|b}: without length markers, and so is the separator of inline
+// primitive lists under DialectV2. Values containing the delimiter are
+// quoted; with tabs or pipes, commas in values no longer are. Tables nested
+// inside cells keep their commas. Unknown delimiters are ignored.
+func WithDelimiter(d Delimiter) Option {
+	return func(o *options) {
+		if d < CommaDelimiter || d > PipeDelimiter {
+			return
+		}
+		o.delimiter = d
+	}
+}
+
+// delimiterString returns the separator between the cells of a row
+func (e *encoder) delimiterString() string {
+	return delimiterText[e.delimiter]
+}
+
+// delimiterMark returns the declaration of the delimiter written inside
+// header brackets, "" for commas
+func (e *encoder) delimiterMark() string {
+	if e.delimiter == CommaDelimiter {
+		return ""
+	}
+	return delimiterText[e.delimiter]
+}
+
+// cellSpecial returns the bytes that make a table cell quoted
+func (e *encoder) cellSpecial() *byteSet {
+	return rowDelimiters[e.delimiter]
+}
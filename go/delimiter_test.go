@@ -0,0 +1,104 @@
+package totoon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithDelimiter_Tab(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Smith, Alice"},
+			map[string]interface{}{"id": 2, "name": "Bob"},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithDelimiter(TabDelimiter))
+	expected := "users[2\t]{id\tname}:\n  1\tSmith, Alice\n  2\tBob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDelimiter_Pipe(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"a": "x|y", "b": "1,2"},
+		map[string]interface{}{"a": "z", "b": []interface{}{1, 2}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithDelimiter(PipeDelimiter))
+	expected := "[2|]{a|b}:\n  \"x|y\"|1,2\n  z|[1,2]"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDelimiter_HeaderStyles(t *testing.T) {
+	data := map[string]interface{}{"t": []interface{}{map[string]interface{}{"a": 1, "b": 2}}}
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{[]Option{WithLengthMarkers(false)}, "t[|]{a|b}:\n  1|2"},
+		{[]Option{WithHeaderStyle(ParenHeader)}, "t(1|): a|b\n  1|2"},
+		{[]Option{WithHeaderStyle(TwoLineHeader)}, "t[1|]:\n  a|b\n  1|2"},
+	}
+	for _, tt := range tests {
+		opts := append([]Option{withSortedKeys(), WithDelimiter(PipeDelimiter)}, tt.opts...)
+		if result := ToToonWithOptions(data, opts...); result != tt.expected {
+			t.Errorf("Expected %q, got: %q", tt.expected, result)
+		}
+	}
+}
+
+func TestWithDelimiter_NestedTable(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "tags": []interface{}{
+			map[string]interface{}{"k": "a|b"},
+			map[string]interface{}{"k": "c"},
+		}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithDelimiter(PipeDelimiter))
+	expected := "[1|]{id|tags}:\n  1|[2]{k}:\"a|b\";c"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDelimiter_InlineList(t *testing.T) {
+	data := map[string]interface{}{"tags": []interface{}{"a,b", "c"}}
+	result := ToToonWithOptions(data, WithDialect(DialectV2), WithDelimiter(TabDelimiter))
+	expected := "tags[2\t]: \"a,b\"\tc"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDelimiter_RoundTrip(t *testing.T) {
+	data := map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"a": "x, y", "b": 1.0, "c": []interface{}{map[string]interface{}{"k": 1.0}, map[string]interface{}{"k": 2.0}}},
+			map[string]interface{}{"a": "p|q", "b": 2.0, "c": "z"},
+		},
+	}
+	for _, d := range []Delimiter{CommaDelimiter, TabDelimiter, PipeDelimiter} {
+		doc := ToToonWithOptions(data, WithDelimiter(d))
+		var decoded interface{}
+		if err := Unmarshal([]byte(doc), &decoded); err != nil {
+			t.Fatalf("Unexpected error decoding %q: %v", doc, err)
+		}
+		if !reflect.DeepEqual(decoded, data) {
+			t.Errorf("Expected %v, got: %v", data, decoded)
+		}
+	}
+}
+
+func TestAppendRows_DeclaredDelimiter(t *testing.T) {
+	doc := []byte("rows[1|]{a|b}:\n  1|x")
+	out, err := AppendRows(doc, "rows", []map[string]interface{}{{"a": 2, "b": "y,z"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "rows[2|]{a|b}:\n  1|x\n  2|y,z"
+	if string(out) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(out))
+	}
+}
@@ -0,0 +1,327 @@
+package totoon
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect selects the flavor of TOON that is written
+type Dialect int
+
+const (
+	// DialectV1 is the output this package has always written (the default)
+	DialectV1 Dialect = iota
+	// DialectV2 follows the TOON specification strictly
+	DialectV2
+)
+
+// WithDialect selects the output dialect, so consumers of the legacy output
+// keep working while others adopt the spec-compliant form. DialectV2 differs
+// from DialectV1 in that:
+//
+//   - table rows are indented one level below their header
+//   - lists of primitives are written inline as key[N]: a,b,c
+//   - empty lists are written as key[0]: and empty objects as key:
+//   - only lists of objects with identical keys and primitive values become
+//     tables; other lists of objects are written as key[N]: followed by
+//     "- " items
+//   - strings are double-quoted whenever they could be misread: empty,
+//     padded with spaces, equal to true, false or null, numeric, starting
+//     with "-", or containing : " \ [ ] { } , # or control characters
+//   - keys that are not identifiers are quoted
+//   - numbers never use exponent notation, and NaN and infinities are null
+//
+// Block scalars (WithLiteralBlocks, WithFoldedBlocks) and single quotes are
+// not part of the specification and are ignored under DialectV2.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.dialect = d
+	}
+}
+
+// strict reports whether spec-compliant output is being written
+func (e *encoder) strict() bool {
+	return e.dialect == DialectV2
+}
+
+// rowPrefix returns the indentation of the rows of a table whose header is
+// at level. DialectV1 always indents rows by two spaces.
+func (e *encoder) rowPrefix(level int) string {
+	if e.strict() {
+		return strings.Repeat(" ", e.indent*(level+1))
+	}
+	return "  "
+}
+
+var (
+	specNumeric    = regexp.MustCompile(`^-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?$|^0\d+$`)
+	specIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+)
+
+// specNeedsQuotes reports whether the spec requires s to be quoted
+func specNeedsQuotes(s string) bool {
+	switch {
+	case s == "", s != strings.TrimSpace(s), s == "true", s == "false", s == "null":
+		return true
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "'"), specNumeric.MatchString(s):
+		// a leading single quote would be taken for quoting by decoders
+		// that read both dialects
+		return true
+	case readsAsMarker(s):
+		return true
+	}
+	return specSpecial.containsAny(s)
+}
+
+// specString writes s as a spec string, quoting and escaping it if needed
+func (e *encoder) specString(s string) string {
+	if !specNeedsQuotes(s) {
+		return s
+	}
+	e.unsafeValue(s)
+	e.trace("value %q quoted", s)
+	return specQuote(s)
+}
+
+func specQuote(s string) string {
+	return escapeQuoted(s, '"')
+}
+
+// specKey writes an object key or table field, quoted unless it is an
+// identifier. DialectV1 writes keys as they are but for the empty key,
+// which would otherwise read as a root list or table.
+func (e *encoder) specKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	if !e.strict() || specIdentifier.MatchString(key) {
+		return key
+	}
+	return specQuote(key)
+}
+
+// specNumber writes a number in plain decimal notation
+func specNumber(v interface{}) string {
+	var f float64
+	bits := 64
+	switch n := v.(type) {
+	case float32:
+		f, bits = float64(n), 32
+	case float64:
+		f = n
+	case json.Number:
+		if d, ok := decimalText(string(n)); ok {
+			return d
+		}
+		f, _ = n.Float64()
+	default:
+		return formatPlainNumber(v)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "null"
+	}
+	if f == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, bits)
+}
+
+// maxDecimalShift bounds the exponent decimalText expands, so that a number
+// such as 1e999999 cannot produce a line of a million digits
+const maxDecimalShift = 400
+
+// decimalText rewrites the JSON number s in plain decimal notation without
+// going through float64, so every digit is kept: the exponent is applied by
+// moving the decimal point, leading and trailing zeros are dropped and -0
+// becomes 0. It reports false for malformed numbers and huge exponents.
+func decimalText(s string) (string, bool) {
+	if !jsonNumber.MatchString(s) {
+		return "", false
+	}
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	mantissa, exponent, hasExp := strings.Cut(strings.ToLower(s), "e")
+	shift := 0
+	if hasExp {
+		n, err := strconv.Atoi(exponent)
+		if err != nil || n > maxDecimalShift || n < -maxDecimalShift {
+			return "", false
+		}
+		shift = n
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	digits := whole + frac
+	point := len(whole) + shift
+	trimmed := strings.TrimLeft(digits, "0")
+	point -= len(digits) - len(trimmed)
+	digits = strings.TrimRight(trimmed, "0")
+
+	var out string
+	switch {
+	case digits == "":
+		return "0", true
+	case point <= 0:
+		out = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		out = digits + strings.Repeat("0", point-len(digits))
+	default:
+		out = digits[:point] + "." + digits[point:]
+	}
+	if neg {
+		out = "-" + out
+	}
+	return out, true
+}
+
+// isPrimitive reports whether v is written as a single scalar
+func isPrimitive(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
+
+// allPrimitive reports whether every item of data is a primitive
+func allPrimitive(data []interface{}) bool {
+	for _, item := range data {
+		if !isPrimitive(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// specTabular reports whether the spec allows data to be written as a
+// table: objects that all have the same keys, at least one, and only
+// primitive values
+func specTabular(data []interface{}) bool {
+	first, ok := data[0].(map[string]interface{})
+	if !ok || len(first) == 0 {
+		return false
+	}
+	for _, item := range data {
+		obj, ok := item.(map[string]interface{})
+		if !ok || len(obj) != len(first) {
+			return false
+		}
+		for k, v := range obj {
+			if _, shared := first[k]; !shared || !isPrimitive(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inlineList writes a list of primitives as key[N]: a,b,c, declaring a
+// delimiter other than commas as key[N|]: a|b|c
+func (e *encoder) inlineList(key string, data []interface{}) string {
+	cells := make([]string, len(data))
+	parentPath := e.path
+	for i, item := range data {
+		e.path = indexPath(parentPath, i)
+		cells[i] = e.cellToToon(e.format(item))
+	}
+	e.path = parentPath
+	header := key + "[" + strconv.Itoa(len(data)) + e.delimiterMark() + "]:"
+	if len(cells) == 0 {
+		return header
+	}
+	return header + " " + strings.Join(cells, e.delimiterString())
+}
+
+func (e *encoder) rootListToToon(data []interface{}, level int) string {
+	var lines lineBuffer
+	e.writeRootList(data, level, lines.add)
+	return lines.String()
+}
+
+// writeRootList writes a list that is the whole document. Under DialectV2
+// it carries a [N] header like lists under a key.
+func (e *encoder) writeRootList(data []interface{}, level int, emit lineSink) {
+	if !e.strict() {
+		e.writeList(data, level, emit)
+		return
+	}
+	prefix := strings.Repeat(" ", e.indent*level)
+	if len(data) > 0 {
+		if _, ok := data[0].(map[string]interface{}); ok {
+			e.writeTable("", data, level, emit)
+			return
+		}
+	}
+	if allPrimitive(data) {
+		emit(prefix + e.inlineList("", data))
+		return
+	}
+	emit(prefix + "[" + strconv.Itoa(len(data)) + "]:")
+	e.writeList(data, level+1, emit)
+}
+
+// writeSpecEntry writes the DialectV2 forms of an object entry whose value
+// is empty or a list not starting with an object, reporting false for
+// entries written as in DialectV1
+func (e *encoder) writeSpecEntry(key string, value interface{}, level int, emit lineSink) bool {
+	prefix := strings.Repeat(" ", e.indent*level)
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			emit(prefix + key + ":")
+			return true
+		}
+	case []map[string]interface{}:
+		if len(val) == 0 {
+			emit(prefix + key + "[0]:")
+			return true
+		}
+	case []interface{}:
+		if len(val) > 0 {
+			if _, ok := val[0].(map[string]interface{}); ok {
+				return false
+			}
+		}
+		if allPrimitive(val) {
+			emit(prefix + e.inlineList(key, val))
+			return true
+		}
+		emit(prefix + key + "[" + strconv.Itoa(len(val)) + "]:")
+		e.writeList(val, level+1, emit)
+		return true
+	}
+	return false
+}
+
+// writeSpecItems writes the items of a list as DialectV2 "- " items:
+// objects start on the dash line, and lists of primitives are inline
+func (e *encoder) writeSpecItems(data []interface{}, level int, emit lineSink) {
+	prefix := strings.Repeat(" ", e.indent*level)
+	parentPath := e.path
+	defer func() { e.path = parentPath }()
+	for i, item := range data {
+		e.path = indexPath(parentPath, i)
+		item = e.format(item)
+		switch val := item.(type) {
+		case map[string]interface{}:
+			if len(val) == 0 {
+				emit(prefix + "-")
+				continue
+			}
+			body := e.dictToToon(val, level+1)
+			emit(prefix + "- " + strings.TrimPrefix(body, strings.Repeat(" ", e.indent*(level+1))))
+			continue
+		case []interface{}:
+			if allPrimitive(val) {
+				emit(prefix + "- " + e.inlineList("", val))
+				continue
+			}
+			emit(prefix + "- [" + strconv.Itoa(len(val)) + "]:")
+			e.writeSpecItems(val, level+1, emit)
+			continue
+		}
+		emit(prefix + "- " + e.valueToToon(item, level))
+	}
+}
@@ -0,0 +1,105 @@
+package totoon
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWithDialect_V2(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Ann"},
+			map[string]interface{}{"id": 2, "name": "a,b"},
+		},
+		"tags":   []interface{}{"x", "true", "", 1e21},
+		"empty":  map[string]interface{}{},
+		"none":   []interface{}{},
+		"ratio":  math.NaN(),
+		"my key": "-x",
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithDialect(DialectV2))
+	expected := "empty:\n\"my key\": \"-x\"\nnone[0]:\nratio: null\n" +
+		"tags[4]: x,\"true\",\"\",1000000000000000000000\n" +
+		"users[2]{id,name}:\n  1,Ann\n  2,\"a,b\""
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDialect_V2ListItems(t *testing.T) {
+	data := map[string]interface{}{
+		"mixed": []interface{}{
+			map[string]interface{}{"a": 1, "b": []interface{}{1, 2}},
+			map[string]interface{}{"a": 2},
+		},
+		"nested": []interface{}{1, []interface{}{1, 2}, map[string]interface{}{"k": "v", "z": 2}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithDialect(DialectV2))
+	expected := "mixed[2]:\n  - a: 1\n    b[2]: 1,2\n  - a: 2\n" +
+		"nested[3]:\n  - 1\n  - [2]: 1,2\n  - k: v\n    z: 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDialect_V2NestedRows(t *testing.T) {
+	data := map[string]interface{}{
+		"team": map[string]interface{}{
+			"members": []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 2},
+			},
+		},
+	}
+	result := ToToonWithOptions(data, WithDialect(DialectV2))
+	expected := "team:\n  members[2]{id}:\n    1\n    2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// DialectV1 keeps its two-space rows
+	result = ToToonWithOptions(data, WithDialect(DialectV1))
+	expected = "team:\n  members[2]{id}:\n  1\n  2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDialect_V2RootLists(t *testing.T) {
+	tests := []struct {
+		data     []interface{}
+		expected string
+	}{
+		{[]interface{}{}, "[0]:"},
+		{[]interface{}{1, "a b"}, "[2]: 1,a b"},
+		{[]interface{}{map[string]interface{}{"a": 1}}, "[1]{a}:\n  1"},
+		{[]interface{}{1, []interface{}{map[string]interface{}{}}}, "[2]:\n  - 1\n  - [1]:\n    -"},
+	}
+	for _, tt := range tests {
+		result := ToToonWithOptions(tt.data, WithDialect(DialectV2))
+		if result != tt.expected {
+			t.Errorf("Expected %q, got: %q", tt.expected, result)
+		}
+	}
+}
+
+func TestWithDialect_V2IgnoresBlocksAndSingleQuotes(t *testing.T) {
+	data := map[string]interface{}{"text": "a\nb"}
+	result := ToToonWithOptions(data, WithDialect(DialectV2), WithLiteralBlocks(), WithQuoteStyle(SingleQuotes))
+	expected := `text: "a\nb"`
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithDialect_V2EncodeTo(t *testing.T) {
+	data := map[string]interface{}{"ids": []interface{}{1, 2, 3}}
+	var buf bytes.Buffer
+	if err := EncodeTo(&buf, data, WithDialect(DialectV2)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := ToToonWithOptions(data, WithDialect(DialectV2)); buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,39 @@
+package totoon
+
+// DuplicateKeyPolicy selects how the decoder reads an object that holds the
+// same key more than once
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeyError fails with a *SyntaxError naming the key
+	DuplicateKeyError DuplicateKeyPolicy = iota
+	// DuplicateKeyKeepFirst keeps the first value and drops later ones
+	DuplicateKeyKeepFirst
+	// DuplicateKeyKeepLast keeps the last value, at the position of the
+	// first
+	DuplicateKeyKeepLast
+)
+
+// WithDuplicateKeys selects how FromToonWithOptions, UnmarshalWithOptions
+// and Decoder read objects, table rows and inline objects that repeat a
+// key, a common mistake in model output. By default they fail.
+func WithDuplicateKeys(policy DuplicateKeyPolicy) Option {
+	return func(o *options) {
+		o.duplicateKeys = policy
+	}
+}
+
+// setKey sets key in obj under the duplicate key policy, reporting false
+// for a duplicate the policy rejects
+func (e *encoder) setKey(obj *OrderedMap, key string, v interface{}) bool {
+	if _, dup := obj.Get(key); dup {
+		switch e.duplicateKeys {
+		case DuplicateKeyError:
+			return false
+		case DuplicateKeyKeepFirst:
+			return true
+		}
+	}
+	obj.Set(key, v)
+	return true
+}
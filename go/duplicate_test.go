@@ -0,0 +1,54 @@
+package totoon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithDuplicateKeys(t *testing.T) {
+	doc := "a: 1\nb: 2\na: 3\nitems:\n  - x: 1\n    x: 2\nrows[1]{k,k}:\n  {y:1,y:2},4"
+	tests := []struct {
+		policy   DuplicateKeyPolicy
+		expected map[string]interface{}
+	}{
+		{DuplicateKeyKeepFirst, map[string]interface{}{
+			"a": 1.0, "b": 2.0,
+			"items": []interface{}{map[string]interface{}{"x": 1.0}},
+			"rows":  []interface{}{map[string]interface{}{"k": map[string]interface{}{"y": 1.0}}},
+		}},
+		{DuplicateKeyKeepLast, map[string]interface{}{
+			"a": 3.0, "b": 2.0,
+			"items": []interface{}{map[string]interface{}{"x": 2.0}},
+			"rows":  []interface{}{map[string]interface{}{"k": 4.0}},
+		}},
+	}
+	for _, tt := range tests {
+		result, err := FromToonWithOptions(doc, WithDuplicateKeys(tt.policy))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("policy %d: Expected %v, got: %v", tt.policy, tt.expected, result)
+		}
+	}
+}
+
+func TestWithDuplicateKeys_Error(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{"a: 1\nb: 2\na: 3", 3},
+		{"- x: 1\n  x: 2", 1},
+		{"rows[1]{k,k}:\n  1,2", 2},
+		{"rows[1]{k}:\n  {y:1,y:2}", 2},
+	}
+	for _, tt := range tests {
+		_, err := FromToonWithOptions(tt.input, WithDuplicateKeys(DuplicateKeyError))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != tt.line {
+			t.Errorf("%q: Expected a *SyntaxError on line %d, got: %v", tt.input, tt.line, err)
+		}
+	}
+}
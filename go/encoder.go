@@ -0,0 +1,74 @@
+package totoon
+
+import "io"
+
+// DocumentSeparator is the line written between the documents of a stream
+// by Encoder. Strings equal to it are always quoted, so a document never
+// holds the line itself.
+const DocumentSeparator = "---"
+
+// Encoder writes a sequence of TOON documents to an output stream, like
+// json.Encoder: each call to Encode writes one document, as it is rendered,
+// followed by a newline. From the second document on, a DocumentSeparator
+// line comes first, so a stream can hold any number of documents. The
+// Encoder carries its own options, so a service can keep one policy and
+// adjust it per call.
+//
+// An Encoder is not safe for concurrent use.
+type Encoder struct {
+	w       io.Writer
+	opts    []Option
+	started bool
+}
+
+// NewEncoder returns an Encoder that writes to w, applying opts to every
+// document
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{w: w, opts: append([]Option(nil), opts...)}
+}
+
+// Encode writes v to the stream like EncodeTo, and a newline after it,
+// applying overrides after the Encoder's own options for this document
+// only. The errors EncodeTo returns once the document is complete, such as
+// an *UnsafeValuesError, leave the stream usable for the next document.
+func (enc *Encoder) Encode(v interface{}, overrides ...Option) error {
+	if enc.started {
+		if _, err := io.WriteString(enc.w, DocumentSeparator+"\n"); err != nil {
+			return err
+		}
+	}
+	enc.started = true
+	w := &writeErrors{w: enc.w}
+	err := EncodeTo(w, v, enc.with(overrides)...)
+	if w.err != nil {
+		// the document is incomplete
+		return w.err
+	}
+	if _, werr := io.WriteString(enc.w, "\n"); werr != nil {
+		return werr
+	}
+	return err
+}
+
+func (enc *Encoder) with(overrides []Option) []Option {
+	if len(overrides) == 0 {
+		return enc.opts
+	}
+	opts := make([]Option, 0, len(enc.opts)+len(overrides))
+	opts = append(opts, enc.opts...)
+	return append(opts, overrides...)
+}
+
+// writeErrors records the first error of the writer it wraps
+type writeErrors struct {
+	w   io.Writer
+	err error
+}
+
+func (w *writeErrors) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,72 @@
+package totoon
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, WithIndent(4))
+	docs := []interface{}{
+		map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+		[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}},
+		"done",
+	}
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	expected := "a:\n    b: 1\n---\n[2]{id}:\n  1\n  2\n---\ndone\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestEncoder_Overrides(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, WithIndent(4), WithKeyOrder("b", "a"))
+	data := map[string]interface{}{"a": map[string]interface{}{"x": "y"}, "b": 1}
+
+	if err := enc.Encode(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := enc.Encode(data, WithIndent(1)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// overrides do not leak into later calls
+	if err := enc.Encode(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "b: 1\na:\n    x: y\n---\nb: 1\na:\n x: y\n---\nb: 1\na:\n    x: y\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestEncoder_UnsafeValues(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, WithAssertSafe())
+	err := enc.Encode(map[string]interface{}{"a": "x\ny"})
+	var unsafe *UnsafeValuesError
+	if !errors.As(err, &unsafe) {
+		t.Errorf("Expected UnsafeValuesError, got: %v", err)
+	}
+
+	// the document is still written in full, and the next one follows it
+	if err := enc.Encode(map[string]interface{}{"b": 1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "a: \"x\\ny\"\n---\nb: 1\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestEncoder_WriteError(t *testing.T) {
+	enc := NewEncoder(failingWriter{})
+	if err := enc.Encode(map[string]interface{}{"a": 1}); err == nil {
+		t.Error("Expected a write error")
+	}
+}
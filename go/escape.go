@@ -0,0 +1,65 @@
+package totoon
+
+import "strings"
+
+// byteSet is a lookup table of ASCII bytes; bytes of multi-byte UTF-8
+// sequences are never ASCII, so strings can be scanned byte by byte
+type byteSet [256]bool
+
+func newByteSet(chars string) *byteSet {
+	var set byteSet
+	for i := 0; i < len(chars); i++ {
+		set[chars[i]] = true
+	}
+	return &set
+}
+
+// containsAny reports whether s contains a byte of the set
+func (set *byteSet) containsAny(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if set[s[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	controlChars   = newByteSet("\n\r\t")
+	cellDelimiters = newByteSet(",:;\n")
+	specSpecial    = newByteSet(":\"\\[]{},#\n\r\t")
+)
+
+// escapeSequences maps the bytes escapeQuoted escapes, besides the quote
+// character, to their escape sequences
+var escapeSequences = [256]string{
+	'\\': `\\`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+}
+
+// escapeQuoted wraps s in the quote character q, escaping backslashes, q
+// and control characters. The runs between escapes are copied whole.
+func escapeQuoted(s string, q byte) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte(q)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == q {
+			b.WriteString(s[start:i])
+			b.WriteByte('\\')
+			b.WriteByte(q)
+			start = i + 1
+		} else if seq := escapeSequences[c]; seq != "" {
+			b.WriteString(s[start:i])
+			b.WriteString(seq)
+			start = i + 1
+		}
+	}
+	b.WriteString(s[start:])
+	b.WriteByte(q)
+	return b.String()
+}
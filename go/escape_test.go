@@ -0,0 +1,92 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeQuoted(t *testing.T) {
+	tests := []struct {
+		input    string
+		quote    byte
+		expected string
+	}{
+		{"", '"', `""`},
+		{"plain", '"', `"plain"`},
+		{"a\nb\tc\rd", '"', `"a\nb\tc\rd"`},
+		{`say "hi" \ 'x'`, '"', `"say \"hi\" \\ 'x'"`},
+		{`say "hi" 'x'`, '\'', `'say "hi" \'x\''`},
+		{"zoë\n東京", '"', `"zoë\n東京"`},
+	}
+	for _, tt := range tests {
+		if result := escapeQuoted(tt.input, tt.quote); result != tt.expected {
+			t.Errorf("escapeQuoted(%q): Expected %q, got: %q", tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestByteSet(t *testing.T) {
+	for _, s := range []string{"a,b", "a:b", "a;b", "a\nb"} {
+		if !cellDelimiters.containsAny(s) {
+			t.Errorf("Expected %q to contain a delimiter", s)
+		}
+	}
+	for _, s := range []string{"", "plain text", "zoë|東京"} {
+		if cellDelimiters.containsAny(s) {
+			t.Errorf("Expected %q to contain no delimiter", s)
+		}
+	}
+}
+
+func TestEscapeString_TextHeavy(t *testing.T) {
+	data := map[string]interface{}{
+		"notes": []interface{}{
+			map[string]interface{}{"id": 1, "text": "line one\nline \"two\""},
+			map[string]interface{}{"id": 2, "text": "a, b; c"},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys())
+	expected := "notes[2]{id,text}:\n  1,\"line one\\nline \\\"two\\\"\"\n  2,\"a, b; c\""
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+// textPayload is a table of prose cells, most of which need no escaping
+func textPayload() map[string]interface{} {
+	rows := make([]interface{}, 200)
+	for i := range rows {
+		text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+		if i%10 == 0 {
+			text += "\nSee: \"notes\", page 2"
+		}
+		rows[i] = map[string]interface{}{"id": i, "title": "Entry", "body": text}
+	}
+	return map[string]interface{}{"entries": rows}
+}
+
+func BenchmarkEscapeString(b *testing.B) {
+	e := newEncoder(nil)
+	plain := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+	escaped := plain + "\n\t\"quoted\" \\ end"
+	b.Run("plain", func(b *testing.B) {
+		b.SetBytes(int64(len(plain)))
+		for i := 0; i < b.N; i++ {
+			e.escapeString(plain)
+		}
+	})
+	b.Run("escaped", func(b *testing.B) {
+		b.SetBytes(int64(len(escaped)))
+		for i := 0; i < b.N; i++ {
+			e.escapeString(escaped)
+		}
+	})
+}
+
+func BenchmarkToToon_TextHeavy(b *testing.B) {
+	data := textPayload()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToToon(data)
+	}
+}
@@ -0,0 +1,37 @@
+package totoon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expandedListToToon writes each item of a list on its own "- " line. Object
+// items start on the dash line and continue with their remaining keys
+// indented beneath it, so no value is ever packed into a table cell.
+func (e *encoder) expandedListToToon(data []interface{}, level int) string {
+	var lines []string
+	prefix := strings.Repeat(" ", e.indent*level)
+
+	parentPath := e.path
+	defer func() { e.path = parentPath }()
+	for i, item := range data {
+		e.path = indexPath(parentPath, i)
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			lines = append(lines, fmt.Sprintf("%s- %s", prefix, e.valueToToon(item, level)))
+			continue
+		}
+		if len(obj) == 0 {
+			lines = append(lines, fmt.Sprintf("%s- {}", prefix))
+			continue
+		}
+
+		// The object is rendered one level deeper; its first line moves up
+		// onto the dash line
+		body := e.dictToToon(obj, level+1)
+		body = strings.TrimPrefix(body, strings.Repeat(" ", e.indent*(level+1)))
+		lines = append(lines, fmt.Sprintf("%s- %s", prefix, body))
+	}
+
+	return strings.Join(lines, "\n")
+}
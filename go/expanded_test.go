@@ -0,0 +1,45 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithOneValuePerLine_ListOfObjects(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice"},
+			map[string]interface{}{"name": "Bob"},
+		},
+	}
+	result := ToToonWithOptions(data, WithOneValuePerLine())
+	expected := "users:\n  - name: Alice\n  - name: Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithOneValuePerLine_NoInlineCells(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{
+			"id":    1,
+			"tags":  []interface{}{"a", "b"},
+			"owner": map[string]interface{}{"name": "Alice"},
+		},
+	}
+	result := ToToonWithOptions(data, WithOneValuePerLine(), withSortedKeys())
+	expected := "- id: 1\n  owner:\n    name: Alice\n  tags:\n    - a\n    - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	if strings.Contains(result, "[") || strings.Contains(result, "{") {
+		t.Errorf("Expected no inline structures, got: %s", result)
+	}
+}
+
+// withSortedKeys makes key order deterministic for exact-match assertions
+func withSortedKeys() Option {
+	return func(o *options) {
+		o.sortKeys = true
+	}
+}
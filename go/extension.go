@@ -0,0 +1,89 @@
+package totoon
+
+import (
+	"sort"
+	"sync"
+)
+
+var extensions struct {
+	sync.RWMutex
+	list []Extension // in priority order
+}
+
+// Extension converts values of a type the encoder does not know, such as a
+// geo point or a protobuf well-known type, into a value it does: a scalar,
+// a map[string]interface{}, a []interface{} or an *OrderedMap
+type Extension struct {
+	// Name identifies the extension; registering a name again replaces it
+	Name string
+	// Priority orders the extensions: higher priorities are tried first,
+	// equal priorities in registration order
+	Priority int
+	// Convert returns the replacement for v and true, or false to leave v
+	// to the next extension
+	Convert func(v interface{}) (interface{}, bool)
+}
+
+// RegisterExtension adds ext to the extensions tried for every value that
+// is not a bool, number, string, map[string]interface{}, []interface{} or
+// *OrderedMap, so packages can teach the encoder their own types without
+// changing its type switch. Values for which no extension reports true are
+// encoded as before. Extensions also see the fields of structs and the
+// items of typed slices and maps, unless the container itself is encoded
+// through encoding/json (see reflectValue).
+//
+// It is safe to call concurrently with conversions, which use the
+// extensions registered when they start. RegisterExtension panics if ext
+// has no Name or no Convert function.
+func RegisterExtension(ext Extension) {
+	if ext.Name == "" || ext.Convert == nil {
+		panic("totoon: RegisterExtension needs a Name and a Convert function")
+	}
+	extensions.Lock()
+	defer extensions.Unlock()
+	list := make([]Extension, 0, len(extensions.list)+1)
+	for _, other := range extensions.list {
+		if other.Name != ext.Name {
+			list = append(list, other)
+		}
+	}
+	list = append(list, ext)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Priority > list[j].Priority
+	})
+	extensions.list = list
+}
+
+// UnregisterExtension removes the extension registered under name, if any
+func UnregisterExtension(name string) {
+	extensions.Lock()
+	defer extensions.Unlock()
+	list := make([]Extension, 0, len(extensions.list))
+	for _, ext := range extensions.list {
+		if ext.Name != name {
+			list = append(list, ext)
+		}
+	}
+	extensions.list = list
+}
+
+// registeredExtensions returns the extensions in priority order; the slice
+// is replaced, never modified, on registration
+func registeredExtensions() []Extension {
+	extensions.RLock()
+	defer extensions.RUnlock()
+	return extensions.list
+}
+
+// extend converts v with the first extension that accepts it
+func (e *encoder) extend(v interface{}) (interface{}, bool) {
+	if len(e.extensions) == 0 || isPrimitive(v) {
+		return v, false
+	}
+	for _, ext := range e.extensions {
+		if out, ok := ext.Convert(v); ok {
+			return out, true
+		}
+	}
+	return v, false
+}
@@ -0,0 +1,80 @@
+package totoon
+
+import (
+	"fmt"
+	"testing"
+)
+
+type geoPoint struct {
+	Lat, Lon float64
+}
+
+func geoExtension(priority int, format string) Extension {
+	return Extension{
+		Name:     "geo",
+		Priority: priority,
+		Convert: func(v interface{}) (interface{}, bool) {
+			p, ok := v.(geoPoint)
+			if !ok {
+				return nil, false
+			}
+			return fmt.Sprintf(format, p.Lat, p.Lon), true
+		},
+	}
+}
+
+func TestRegisterExtension(t *testing.T) {
+	RegisterExtension(geoExtension(0, "%g;%g"))
+	defer UnregisterExtension("geo")
+
+	data := map[string]interface{}{
+		"home":   geoPoint{52.5, 13.4},
+		"stops":  []interface{}{geoPoint{1, 2}, "none"},
+		"places": []interface{}{map[string]interface{}{"id": 1, "at": geoPoint{3, 4}}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys())
+	expected := "home: 52.5;13.4\nplaces[1]{at,id}:\n  \"3;4\",1\nstops:\n  - 1;2\n  - none"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestRegisterExtension_Priority(t *testing.T) {
+	RegisterExtension(geoExtension(0, "%g;%g"))
+	RegisterExtension(geoExtension(5, "%g,%g")) // replaces the first
+	RegisterExtension(Extension{
+		Name:     "geo-object",
+		Priority: 10,
+		Convert: func(v interface{}) (interface{}, bool) {
+			p, ok := v.(geoPoint)
+			if !ok {
+				return nil, false
+			}
+			return map[string]interface{}{"lat": p.Lat, "raw": p}, true
+		},
+	})
+	defer UnregisterExtension("geo")
+	defer UnregisterExtension("geo-object")
+
+	// the higher priority wins, and its result is not extended again
+	result := ToToonWithOptions(map[string]interface{}{"at": geoPoint{1, 2}}, withSortedKeys())
+	expected := "at:\n  lat: 1\n  raw:\n    Lat: 1\n    Lon: 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	UnregisterExtension("geo-object")
+	result = ToToonWithOptions(map[string]interface{}{"at": geoPoint{1, 2}})
+	if expected := "at: 1,2"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestRegisterExtension_Invalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for an extension without Convert")
+		}
+	}()
+	RegisterExtension(Extension{Name: "broken"})
+}
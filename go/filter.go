@@ -0,0 +1,40 @@
+package totoon
+
+import "strings"
+
+// WithRowFilter drops the table rows for which keep returns false, so
+// irrelevant rows (inactive users, say) can be left out while encoding
+// instead of by filtering every slice beforehand. It applies to every list
+// of objects written as a table, at any depth and including tables inside
+// cells, before sorting and deduping; the row count in the header is that
+// of the kept rows.
+func WithRowFilter(keep func(row map[string]interface{}) bool) Option {
+	return func(o *options) {
+		o.rowFilter = keep
+	}
+}
+
+// filterRows returns the items of data kept by WithRowFilter. Items that
+// are not objects are kept.
+func (e *encoder) filterRows(data []interface{}) []interface{} {
+	kept := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		if obj, ok := item.(map[string]interface{}); ok && !e.rowFilter(obj) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if dropped := len(data) - len(kept); dropped > 0 {
+		e.trace("%d of %d rows dropped by the row filter", dropped, len(data))
+	}
+	return kept
+}
+
+// emptyTable emits a table whose rows were all filtered out
+func (e *encoder) emptyTable(key string, level int, emit lineSink) {
+	if key == "" {
+		emit("[]")
+		return
+	}
+	emit(strings.Repeat(" ", e.indent*level) + key + ": []")
+}
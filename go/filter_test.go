@@ -0,0 +1,54 @@
+package totoon
+
+import "testing"
+
+func activeOnly(row map[string]interface{}) bool {
+	active, _ := row["active"].(bool)
+	return active
+}
+
+func TestWithRowFilter(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "active": true},
+			map[string]interface{}{"id": 2, "active": false},
+			map[string]interface{}{"id": 3, "active": true},
+		},
+		"tags": []interface{}{"a", "b"},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithRowFilter(activeOnly))
+	expected := "tags:\n  - a\n  - b\nusers[2]{active,id}:\n  true,1\n  true,3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithRowFilter_AllDropped(t *testing.T) {
+	rows := []interface{}{map[string]interface{}{"id": 1}}
+
+	result := ToToonWithOptions(map[string]interface{}{"users": rows}, WithRowFilter(activeOnly))
+	if expected := "users: []"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	result = ToToonWithOptions(rows, WithRowFilter(activeOnly))
+	if expected := "[]"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithRowFilter_NestedCells(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "members": []interface{}{
+			map[string]interface{}{"name": "a", "active": true},
+			map[string]interface{}{"name": "b"},
+		}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithRowFilter(func(row map[string]interface{}) bool {
+		_, isMember := row["name"]
+		return !isMember || activeOnly(row)
+	}))
+	expected := "[1]{id,members}:\n  1,[1]{active,name}:true,a"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
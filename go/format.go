@@ -0,0 +1,151 @@
+package totoon
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// indexPattern matches the list indexes of a value path
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// formatter is a value rendering hook registered with WithFormatter
+type formatter struct {
+	segments []string
+	fn       func(v interface{}) string
+}
+
+// WithFormatter renders the scalar values at matching paths with fn, for
+// units, percentages or other custom renderings (for example
+// WithFormatter("*.bytes", humanizeBytes)). The result is written as a
+// string and quoted like any other.
+//
+// pattern is a dot-separated key path matched against the end of each
+// value's path, with list indexes ignored: "bytes" matches every key named
+// bytes, "*.bytes" a bytes key under any parent and "files.bytes" only the
+// bytes column of the files table. Segments may use path.Match wildcards.
+// When several patterns match, the first one registered wins.
+func WithFormatter(pattern string, fn func(v interface{}) string) Option {
+	return func(o *options) {
+		o.formatters = append(o.formatters, formatter{segments: strings.Split(pattern, "."), fn: fn})
+	}
+}
+
+// enumLabels is a label mapping registered with WithEnumLabels
+type enumLabels struct {
+	segments []string
+	labels   map[interface{}]string
+}
+
+// WithEnumLabels replaces raw enum values at matching paths with readable
+// labels, for example 2 with "shipped". path uses the same patterns as
+// WithFormatter. Numeric values match numeric keys of any type, so a JSON
+// 2.0 finds the label registered under int 2; values without a label are
+// written unchanged. Labels take precedence over formatters.
+func WithEnumLabels(path string, labels map[interface{}]string) Option {
+	return func(o *options) {
+		o.enums = append(o.enums, enumLabels{segments: strings.Split(path, "."), labels: labels})
+	}
+}
+
+// lookup returns the label of v
+func (l enumLabels) lookup(v interface{}) (string, bool) {
+	if v == nil || !reflect.TypeOf(v).Comparable() {
+		return "", false
+	}
+	if label, ok := l.labels[v]; ok {
+		return label, true
+	}
+	f, isNumber := toFloat(v)
+	if !isNumber {
+		return "", false
+	}
+	for k, label := range l.labels {
+		if kf, ok := toFloat(k); ok && kf == f {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// value returns the value whose label is label
+func (l enumLabels) value(label string) (interface{}, bool) {
+	for k, text := range l.labels {
+		if text != label {
+			continue
+		}
+		if _, isNumber := toFloat(k); isNumber {
+			// numbers read back as the decoder reads them
+			return json.Number(formatPlainNumber(k)), true
+		}
+		return k, true
+	}
+	return nil, false
+}
+
+// unlabel replaces the enum labels in a decoded value tree by the values
+// they were written for, following the paths format matches
+func (e *encoder) unlabel(v interface{}, path string) interface{} {
+	switch val := v.(type) {
+	case *OrderedMap:
+		for _, k := range val.keys {
+			val.values[k] = e.unlabel(val.values[k], joinPath(path, k))
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = e.unlabel(item, indexPath(path, i))
+		}
+	case string:
+		segments := strings.Split(indexPattern.ReplaceAllString(path, ""), ".")
+		for _, l := range e.enums {
+			if matchSuffix(l.segments, segments) {
+				if raw, ok := l.value(val); ok {
+					return raw
+				}
+			}
+		}
+	}
+	return v
+}
+
+// format applies the first matching enum label or formatter to the scalar v
+// at the current path
+func (e *encoder) format(v interface{}) interface{} {
+	if len(e.formatters) == 0 && len(e.enums) == 0 {
+		return v
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}, []map[string]interface{}:
+		return v
+	}
+	segments := strings.Split(indexPattern.ReplaceAllString(e.path, ""), ".")
+	for _, l := range e.enums {
+		if matchSuffix(l.segments, segments) {
+			if label, ok := l.lookup(v); ok {
+				return label
+			}
+		}
+	}
+	for _, f := range e.formatters {
+		if matchSuffix(f.segments, segments) {
+			return f.fn(v)
+		}
+	}
+	return v
+}
+
+// matchSuffix reports whether pattern matches the last segments of path
+func matchSuffix(pattern, segments []string) bool {
+	if len(pattern) > len(segments) {
+		return false
+	}
+	tail := segments[len(segments)-len(pattern):]
+	for i, p := range pattern {
+		if ok, err := path.Match(p, tail[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
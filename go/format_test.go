@@ -0,0 +1,73 @@
+package totoon
+
+import (
+	"fmt"
+	"testing"
+)
+
+func humanizeBytes(v interface{}) string {
+	f, _ := toFloat(v)
+	return fmt.Sprintf("%.1f KiB", f/1024)
+}
+
+func TestWithFormatter_TableColumn(t *testing.T) {
+	data := map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"name": "a.txt", "bytes": 2048},
+			map[string]interface{}{"name": "b.txt", "bytes": 512},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithFormatter("*.bytes", humanizeBytes))
+	expected := "files[2]{bytes,name}:\n  2.0 KiB,a.txt\n  0.5 KiB,b.txt"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithFormatter_PathsAndPrecedence(t *testing.T) {
+	percent := func(v interface{}) string {
+		f, _ := toFloat(v)
+		return fmt.Sprintf("%.0f%%", f*100)
+	}
+	data := map[string]interface{}{
+		"ratio": 0.5,
+		"stats": map[string]interface{}{"ratio": 0.25, "hits": []interface{}{1, 2}},
+	}
+	opts := []Option{
+		withSortedKeys(),
+		WithFormatter("stats.ratio", func(interface{}) string { return "first" }),
+		WithFormatter("ratio", percent),
+		WithFormatter("h*", func(v interface{}) string { return fmt.Sprintf("#%v", v) }),
+	}
+	result := ToToonWithOptions(data, opts...)
+	expected := "ratio: 50%\nstats:\n  hits:\n    - #1\n    - #2\n  ratio: first"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithEnumLabels(t *testing.T) {
+	data := map[string]interface{}{
+		"orders": []interface{}{
+			map[string]interface{}{"id": 1, "status": 2.0},
+			map[string]interface{}{"id": 2, "status": 9},
+			map[string]interface{}{"id": 3, "status": "pending"},
+		},
+	}
+	labels := map[interface{}]string{1: "paid", 2: "shipped", "pending": "awaiting payment"}
+	result := ToToonWithOptions(data, withSortedKeys(), WithEnumLabels("orders.status", labels))
+	expected := "orders[3]{id,status}:\n  1,shipped\n  2,9\n  3,awaiting payment"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithEnumLabels_BeforeFormatter(t *testing.T) {
+	data := map[string]interface{}{"level": 3}
+	result := ToToonWithOptions(data,
+		WithFormatter("level", func(interface{}) string { return "formatted" }),
+		WithEnumLabels("level", map[interface{}]string{3: "error"}))
+	if result != "level: error" {
+		t.Errorf("Expected %q, got: %q", "level: error", result)
+	}
+}
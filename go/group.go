@@ -0,0 +1,98 @@
+package totoon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupID identifies a group by the text of its value and whether that
+// value is a string, so that "1" and 1 are grouped apart
+type groupID struct {
+	text     string
+	isString bool
+}
+
+// groupedListToToon emits one sub-table per distinct value of the group-by
+// field, in order of first appearance. The grouping column is dropped from
+// the rows since it is already carried by the group header.
+func (e *encoder) groupedListToToon(key string, data []interface{}, level int) string {
+	var order []groupID
+	groups := make(map[groupID][]interface{})
+
+	for _, item := range data {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		groupKey := groupID{isString: true}
+		if groupKey.text, groupKey.isString = obj[e.groupBy].(string); !groupKey.isString {
+			groupKey.text = e.valueToToonInline(obj[e.groupBy])
+		}
+		rest := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			if k != e.groupBy {
+				rest[k] = v
+			}
+		}
+
+		if _, seen := groups[groupKey]; !seen {
+			order = append(order, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], rest)
+	}
+
+	var lines []string
+	groupLevel := level
+	if key != "" {
+		prefix := strings.Repeat(" ", e.indent*level)
+		lines = append(lines, fmt.Sprintf("%s%s:", prefix, key))
+		groupLevel = level + 1
+	}
+
+	// Sub-tables must not be grouped again by the same field
+	inner := *e
+	inner.groupBy = ""
+	written := make(map[string]bool, len(order))
+	for _, groupKey := range order {
+		if written[groupKey.text] {
+			e.warn("string and non-string group values are both written as key %q", groupKey.text)
+		}
+		written[groupKey.text] = true
+
+		header := e.headerKey(groupKey.text)
+		table := inner.listOfObjectsToToon(header, groups[groupKey], groupLevel)
+		if table == "[]" {
+			prefix := strings.Repeat(" ", e.indent*groupLevel)
+			table = prefix + e.tableHeader(header, len(groups[groupKey]), nil)
+		}
+		lines = append(lines, table)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// headerKey writes the key of a table header, such as a group value. Keys
+// are written as specKey writes them, and DialectV1 also quotes those that
+// would not read back as the whole key, such as "a: b" or "x[1]".
+func (e *encoder) headerKey(key string) string {
+	if e.strict() {
+		return e.specKey(key)
+	}
+	if key == "" || key != strings.TrimSpace(key) || strings.HasPrefix(key, `"`) || isItem(key) || keyEnd(key+":", e) != len(key) {
+		return specQuote(key)
+	}
+	return key
+}
+
+// hasField reports whether any object in data contains field
+func hasField(data []interface{}, field string) bool {
+	for _, item := range data {
+		if obj, ok := item.(map[string]interface{}); ok {
+			if _, exists := obj[field]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithGroupBy_SplitsTables(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "region": "us"},
+			map[string]interface{}{"name": "Bob", "region": "eu"},
+			map[string]interface{}{"name": "Carol", "region": "us"},
+		},
+	}
+	result := ToToonWithOptions(data, WithGroupBy("region"))
+	expected := "users:\n  us[2]{name}:\n  Alice\n  Carol\n  eu[1]{name}:\n  Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithGroupBy_TopLevelList(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "level": "info"},
+		map[string]interface{}{"id": 2, "level": "error"},
+	}
+	result := ToToonWithOptions(data, WithGroupBy("level"))
+	if !strings.HasPrefix(result, "info[1]{id}:") {
+		t.Errorf("Expected result to start with 'info[1]{id}:', got: %s", result)
+	}
+	if !strings.Contains(result, "error[1]{id}:") {
+		t.Errorf("Expected 'error[1]{id}:' in result, got: %s", result)
+	}
+	if strings.Contains(result, "level") {
+		t.Errorf("Expected grouping column to be removed, got: %s", result)
+	}
+}
+
+func TestWithGroupBy_MissingField(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"name": "Alice"},
+		map[string]interface{}{"name": "Bob"},
+	}
+	result := ToToonWithOptions(data, WithGroupBy("region"))
+	if result != ToToon(data) {
+		t.Errorf("Expected ungrouped table, got: %s", result)
+	}
+}
+
+func TestWithGroupBy_QuotesGroupKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "region": "a: b"},
+			map[string]interface{}{"name": "Bob", "region": "x[1]"},
+		},
+	}
+	for _, dialect := range []Dialect{DialectV1, DialectV2} {
+		result := ToToonWithOptions(data, WithGroupBy("region"), WithDialect(dialect))
+		if !strings.Contains(result, `"a: b"[1]{name}:`) {
+			t.Errorf("Expected quoted group key, got: %s", result)
+		}
+		decoded, err := FromToonWithOptions(result, WithDialect(dialect))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v\n%s", err, result)
+		}
+		users := decoded.(map[string]interface{})["users"].(map[string]interface{})
+		if _, ok := users["a: b"]; !ok {
+			t.Errorf("Expected group a: b, got: %v", users)
+		}
+		if _, ok := users["x[1]"]; !ok {
+			t.Errorf("Expected group x[1], got: %v", users)
+		}
+	}
+}
+
+func TestWithGroupBy_GroupsByType(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "code": "1"},
+		map[string]interface{}{"id": 2, "code": 1},
+		map[string]interface{}{"id": 3, "code": "1"},
+	}
+	result, warnings := ToToonWithWarnings(data, WithGroupBy("code"))
+	expected := "1[2]{id}:\n  1\n  3\n1[1]{id}:\n  2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, `key "1"`) {
+		t.Errorf("Expected one warning about key 1, got: %v", warnings)
+	}
+}
@@ -0,0 +1,21 @@
+package totoon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Hash returns the hex-encoded SHA-256 digest of CanonicalBytes(v). Values
+// that are structurally equal hash identically regardless of map iteration
+// order or the concrete Go numeric types used, while values of different
+// types, such as the string "1" and the number 1, hash differently. This
+// makes the result suitable as a cache key for prompt caching layers and as
+// an integrity check.
+func Hash(v interface{}) (string, error) {
+	canonical, err := CanonicalBytes(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,79 @@
+package totoon
+
+import (
+	"testing"
+)
+
+func TestHash_StableAcrossKeyOrder(t *testing.T) {
+	a := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "age": 30, "active": true},
+			map[string]interface{}{"name": "Bob", "age": 25, "active": false},
+		},
+		"meta": map[string]interface{}{"count": 2, "source": "db"},
+	}
+	first, err := Hash(a)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		h, err := Hash(a)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if h != first {
+			t.Fatalf("Expected stable hash, got %s and %s", first, h)
+		}
+	}
+	if len(first) != 64 {
+		t.Errorf("Expected 64 hex characters, got: %s", first)
+	}
+}
+
+func TestHash_NumericTypes(t *testing.T) {
+	a, _ := Hash(map[string]interface{}{"n": 2})
+	b, _ := Hash(map[string]interface{}{"n": 2.0})
+	if a != b {
+		t.Errorf("Expected int and float of equal value to hash the same")
+	}
+}
+
+func TestHash_DiffersOnContent(t *testing.T) {
+	a, _ := Hash(map[string]interface{}{"n": 1})
+	b, _ := Hash(map[string]interface{}{"n": 2})
+	if a == b {
+		t.Errorf("Expected different values to hash differently")
+	}
+}
+
+func TestHash_DiffersOnType(t *testing.T) {
+	pairs := [][2]interface{}{
+		{map[string]interface{}{"v": "1"}, map[string]interface{}{"v": 1}},
+		{map[string]interface{}{"v": "false"}, map[string]interface{}{"v": false}},
+		{map[string]interface{}{"v": "null"}, map[string]interface{}{"v": nil}},
+		{[]interface{}{"2.5"}, []interface{}{2.5}},
+		{
+			[]interface{}{map[string]interface{}{"a": "", "b": 1}, map[string]interface{}{"a": "", "b": 2}},
+			[]interface{}{map[string]interface{}{"b": 1}, map[string]interface{}{"b": 2, "a": ""}},
+		},
+	}
+	for _, pair := range pairs {
+		a, err := Hash(pair[0])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		b, err := Hash(pair[1])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if a == b {
+			t.Errorf("Expected %v and %v to hash differently", pair[0], pair[1])
+		}
+	}
+}
+
+func TestHash_UnsupportedValue(t *testing.T) {
+	if _, err := Hash(map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Errorf("Expected error for unsupported value")
+	}
+}
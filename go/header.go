@@ -0,0 +1,101 @@
+package totoon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HeaderStyle selects how table headers are written
+type HeaderStyle int
+
+const (
+	// BracketHeader writes key[N]{a,b}: (the default)
+	BracketHeader HeaderStyle = iota
+	// ParenHeader writes key(N): a,b
+	ParenHeader
+	// TwoLineHeader writes key[N]: followed by an a,b column line
+	TwoLineHeader
+)
+
+// WithHeaderStyle selects the table header dialect, for consumers that
+// expect a header form other than key[N]{a,b}:. Rows are unchanged. Tables
+// nested inside cells always use the compact bracket form.
+func WithHeaderStyle(style HeaderStyle) Option {
+	return func(o *options) {
+		o.headerStyle = style
+	}
+}
+
+// WithLengthMarkers controls the [N] row counts in table headers. Passing
+// false writes headers as key{a,b}: for parsers that do not accept the
+// bracketed counts; the rows themselves are unchanged.
+func WithLengthMarkers(enabled bool) Option {
+	return func(o *options) {
+		o.noLengths = !enabled
+	}
+}
+
+// tableHeader returns the header of a table of count rows under key, without
+// indentation; key is empty for root tables
+func (e *encoder) tableHeader(key string, count int, fields []string) string {
+	return e.formatHeader(key, e.countText(count), e.columnLabels(fields))
+}
+
+// countText returns the row count as written in headers, "" without length
+// markers
+func (e *encoder) countText(count int) string {
+	if e.noLengths {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
+
+// formatHeader writes a header in the selected style with count as the row
+// count text, or without a count when it is empty, declaring the delimiter
+// after it
+func (e *encoder) formatHeader(key, count string, fields []string) string {
+	count += e.delimiterMark()
+	return e.writeHeader(key, count, count != "", fields)
+}
+
+// openHeader writes a header whose count is left empty, key[]{a,b}:, for a
+// table whose row count is not known when the header is written. It reads
+// back without a count check, with or without length markers.
+func (e *encoder) openHeader(key string, fields []string) string {
+	return e.writeHeader(key, e.delimiterMark(), true, fields)
+}
+
+// writeHeader writes a header in the selected style, enclosing count in the
+// style's count marker when marked
+func (e *encoder) writeHeader(key, count string, marked bool, fields []string) string {
+	columns := strings.Join(fields, e.delimiterString())
+	switch e.headerStyle {
+	case ParenHeader:
+		if marked {
+			key += "(" + count + ")"
+		}
+		if columns == "" {
+			return key + ":"
+		}
+		return key + ": " + columns
+	case TwoLineHeader:
+		if marked {
+			key += "[" + count + "]"
+		}
+		return key + ":\n  " + columns
+	}
+	if marked {
+		key += "[" + count + "]"
+	}
+	return fmt.Sprintf("%s{%s}:", key, columns)
+}
+
+// inlineTableHeader returns the header of a table nested inside a cell
+func (e *encoder) inlineTableHeader(count int, fields []string) string {
+	fields = e.columnLabels(fields)
+	if e.noLengths {
+		return fmt.Sprintf("{%s}:", strings.Join(fields, ","))
+	}
+	return fmt.Sprintf("[%d]{%s}:", count, strings.Join(fields, ","))
+}
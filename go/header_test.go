@@ -0,0 +1,81 @@
+package totoon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithLengthMarkers_Disabled(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "roles": []interface{}{
+				map[string]interface{}{"id": 1},
+			}},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithLengthMarkers(false))
+	expected := "users{name,roles}:\n  Alice,{id}:1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithLengthMarkers_Enabled(t *testing.T) {
+	data := []interface{}{map[string]interface{}{"a": 1}}
+	if result := ToToonWithOptions(data, WithLengthMarkers(true)); result != "[1]{a}:\n  1" {
+		t.Errorf("Expected counted header, got: %q", result)
+	}
+}
+
+func TestWithLengthMarkers_TableWriterSkipsReservedCount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rows.toon")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw, err := NewTableWriter(f, "rows", []string{"a"}, WithLengthMarkers(false))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw.WriteRow(1)
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.Close()
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "rows{a}:\n  1" {
+		t.Errorf("Expected %q, got: %q", "rows{a}:\n  1", string(content))
+	}
+}
+
+func TestWithHeaderStyle(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "age": 30},
+		},
+	}
+	cases := []struct {
+		style    HeaderStyle
+		expected string
+	}{
+		{BracketHeader, "users[1]{age,name}:\n  30,Alice"},
+		{ParenHeader, "users(1): age,name\n  30,Alice"},
+		{TwoLineHeader, "users[1]:\n  age,name\n  30,Alice"},
+	}
+	for _, c := range cases {
+		result := ToToonWithOptions(data, withSortedKeys(), WithHeaderStyle(c.style))
+		if result != c.expected {
+			t.Errorf("Style %d: expected %q, got: %q", c.style, c.expected, result)
+		}
+	}
+}
+
+func TestWithHeaderStyle_WithoutLengthMarkers(t *testing.T) {
+	data := map[string]interface{}{"rows": []interface{}{map[string]interface{}{"a": 1}}}
+	result := ToToonWithOptions(data, WithHeaderStyle(ParenHeader), WithLengthMarkers(false))
+	if result != "rows: a\n  1" {
+		t.Errorf("Expected %q, got: %q", "rows: a\n  1", result)
+	}
+}
@@ -0,0 +1,56 @@
+package totoon
+
+// kubernetesKeyOrder lists the keys written first in Kubernetes objects:
+// type information and metadata at the top, identifying fields first within
+// metadata and container tables
+var kubernetesKeyOrder = []string{
+	"apiVersion", "kind", "metadata",
+	"name", "namespace", "labels", "annotations",
+	"image", "spec", "status",
+}
+
+// KubernetesToToon converts a Kubernetes object held as unstructured content
+// (the map behind unstructured.Unstructured, or a decoded YAML/JSON manifest)
+// to TOON with defaults suited to manifests: metadata.managedFields is
+// removed, apiVersion, kind and metadata come first, and lists of objects
+// such as containers, ports and env are written as tables. List kinds are
+// handled the same way for every object under items.
+//
+// obj is not modified. opts are applied after the defaults, so a later
+// WithKeyOrder replaces the built-in ordering.
+func KubernetesToToon(obj map[string]interface{}, opts ...Option) string {
+	opts = append([]Option{WithKeyOrder(kubernetesKeyOrder...)}, opts...)
+	return ToToonWithOptions(stripManagedFields(obj), opts...)
+}
+
+// stripManagedFields returns a copy of v with managedFields removed from
+// every metadata object
+func stripManagedFields(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			if k == "metadata" {
+				if meta, ok := child.(map[string]interface{}); ok {
+					stripped := make(map[string]interface{}, len(meta))
+					for mk, mv := range meta {
+						if mk != "managedFields" {
+							stripped[mk] = stripManagedFields(mv)
+						}
+					}
+					out[k] = stripped
+					continue
+				}
+			}
+			out[k] = stripManagedFields(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = stripManagedFields(item)
+		}
+		return out
+	}
+	return v
+}
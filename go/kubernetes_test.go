@@ -0,0 +1,66 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPod() map[string]interface{} {
+	return map[string]interface{}{
+		"kind":       "Pod",
+		"apiVersion": "v1",
+		"status":     map[string]interface{}{"phase": "Running"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"image": "nginx:1.25", "name": "web"},
+				map[string]interface{}{"image": "envoy:1.29", "name": "proxy"},
+			},
+		},
+		"metadata": map[string]interface{}{
+			"namespace": "default",
+			"name":      "web-0",
+			"managedFields": []interface{}{
+				map[string]interface{}{"manager": "kubectl", "operation": "Apply"},
+			},
+		},
+	}
+}
+
+func TestKubernetesToToon_Pod(t *testing.T) {
+	result := KubernetesToToon(testPod())
+	expected := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web-0
+  namespace: default
+spec:
+  containers[2]{name,image}:
+  web,"nginx:1.25"
+  proxy,"envoy:1.29"
+status:
+  phase: Running`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestKubernetesToToon_DoesNotModifyInput(t *testing.T) {
+	pod := testPod()
+	KubernetesToToon(pod)
+	meta := pod["metadata"].(map[string]interface{})
+	if _, ok := meta["managedFields"]; !ok {
+		t.Errorf("Expected input managedFields to be kept")
+	}
+}
+
+func TestKubernetesToToon_ListItems(t *testing.T) {
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      []interface{}{testPod()},
+	}
+	result := KubernetesToToon(list)
+	if strings.Contains(result, "kubectl") {
+		t.Errorf("Expected managedFields of items to be removed, got:\n%s", result)
+	}
+}
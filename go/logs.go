@@ -0,0 +1,188 @@
+package totoon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// defaultLogGroups are the fields LogsToToon groups lines by unless
+// WithLogGroupFields says otherwise
+var defaultLogGroups = []string{"service", "level"}
+
+// WithLogGroupFields sets the fields LogsToToon groups log lines by,
+// replacing the default of "service" and "level".
+func WithLogGroupFields(fields ...string) Option {
+	return func(o *options) {
+		o.logGroups = fields
+	}
+}
+
+// logGroup is the set of log lines sharing the same group field values
+type logGroup struct {
+	key   string
+	lines []map[string]interface{}
+}
+
+// LogsToToon reads structured JSON log lines from r, one object per line,
+// and groups them by their service and level (see WithLogGroupFields). Each
+// group is written under a key made of its group values, for example
+// "api/error". The group fields, and other fields that hold the same value on
+// every line of a group of several lines, are hoisted above the group's
+// entries table, which holds the remaining fields in input order. Blank lines
+// are skipped; a line that is not a JSON object is an error.
+func LogsToToon(r io.Reader, opts ...Option) (string, error) {
+	e := newEncoder(opts)
+	groupFields := e.logGroups
+	if groupFields == nil {
+		groupFields = defaultLogGroups
+	}
+
+	var groups []*logGroup
+	byKey := make(map[string]*logGroup)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&entry); err != nil || entry == nil {
+			return "", fmt.Errorf("totoon: log line %d is not a JSON object", n)
+		}
+
+		key := logGroupKey(entry, groupFields)
+		g, ok := byKey[key]
+		if !ok {
+			g = &logGroup{key: key}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.lines = append(g.lines, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if len(groups) == 0 {
+		return "{}", nil
+	}
+
+	var buf bytes.Buffer
+	w := &Writer{e: e, w: e.newBufferedWriter(&buf)}
+	w.BeginObject()
+	for _, g := range groups {
+		w.Key(g.key)
+		writeLogGroup(w, g, groupFields)
+	}
+	w.EndObject()
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeLogGroup(w *Writer, g *logGroup, groupFields []string) {
+	fields := logFieldOrder(g.lines, groupFields)
+	grouped := make(map[string]bool, len(groupFields))
+	for _, f := range groupFields {
+		grouped[f] = true
+	}
+	var common, columns []string
+	for _, f := range fields {
+		if (grouped[f] || len(g.lines) > 1) && sharedLogField(g.lines, f) {
+			common = append(common, f)
+		} else {
+			columns = append(columns, f)
+		}
+	}
+
+	w.BeginObject()
+	for _, f := range common {
+		w.Key(f)
+		w.Scalar(g.lines[0][f])
+	}
+	w.Key("entries")
+	if len(columns) == 0 {
+		w.Scalar(len(g.lines))
+	} else {
+		w.BeginTable(columns)
+		for _, line := range g.lines {
+			row := make([]interface{}, len(columns))
+			for i, f := range columns {
+				row[i] = ""
+				if v, ok := line[f]; ok {
+					row[i] = v
+				}
+			}
+			w.Row(row...)
+		}
+		w.EndTable()
+	}
+	w.EndObject()
+}
+
+// logGroupKey joins the values of the group fields of entry, using "-" for
+// missing ones
+func logGroupKey(entry map[string]interface{}, groupFields []string) string {
+	parts := make([]string, len(groupFields))
+	for i, f := range groupFields {
+		parts[i] = "-"
+		if v, ok := entry[f]; ok && v != nil {
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// logFieldOrder returns the group fields present in lines followed by the
+// other fields, sorted within each line since JSON objects decode unordered
+func logFieldOrder(lines []map[string]interface{}, groupFields []string) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, f := range groupFields {
+		for _, line := range lines {
+			if _, ok := line[f]; ok {
+				seen[f] = true
+				fields = append(fields, f)
+				break
+			}
+		}
+	}
+	for _, line := range lines {
+		keys := make([]string, 0, len(line))
+		for k := range line {
+			if !seen[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			seen[k] = true
+			fields = append(fields, k)
+		}
+	}
+	return fields
+}
+
+// sharedLogField reports whether every line holds the same value for field
+func sharedLogField(lines []map[string]interface{}, field string) bool {
+	first, ok := lines[0][field]
+	if !ok {
+		return false
+	}
+	for _, line := range lines[1:] {
+		v, ok := line[field]
+		if !ok || !reflect.DeepEqual(v, first) {
+			return false
+		}
+	}
+	return true
+}
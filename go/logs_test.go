@@ -0,0 +1,56 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogsToToon_GroupsAndHoists(t *testing.T) {
+	input := `{"service":"api","level":"error","host":"web-1","msg":"timeout","ms":1200}
+{"service":"api","level":"info","host":"web-1","msg":"ok"}
+
+{"service":"api","level":"error","host":"web-1","msg":"refused","ms":5}
+`
+	result, err := LogsToToon(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `api/error:
+  service: api
+  level: error
+  host: web-1
+  entries[2]{ms,msg}:
+  1200,timeout
+  5,refused
+api/info:
+  service: api
+  level: info
+  entries[1]{host,msg}:
+  web-1,ok`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestLogsToToon_CustomGroupFields(t *testing.T) {
+	input := `{"pod":"a","msg":"x"}
+{"pod":"a","msg":"y"}
+{"msg":"z"}`
+	result, err := LogsToToon(strings.NewReader(input), WithLogGroupFields("pod"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result, "a:\n  pod: a\n  entries[2]{msg}:") {
+		t.Errorf("Expected group a with hoisted pod, got:\n%s", result)
+	}
+	if !strings.Contains(result, "\n-:\n") {
+		t.Errorf("Expected group - for lines without pod, got:\n%s", result)
+	}
+}
+
+func TestLogsToToon_InvalidLine(t *testing.T) {
+	_, err := LogsToToon(strings.NewReader("{\"a\":1}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected error naming line 2, got: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package totoon
+
+import "reflect"
+
+// Marshal returns the TOON encoding of v, mirroring json.Marshal. It uses
+// the options set by SetDefaults; values that must come back unchanged
+// from Unmarshal need DialectV2 (see WithDialect), as DialectV1 leaves
+// strings such as "true" or "42" unquoted.
+func Marshal(v interface{}) ([]byte, error) {
+	out, err := Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// Unmarshal parses the TOON document in data and stores the result in the
+// value pointed to by v, mirroring json.Unmarshal: v may point to a struct,
+// a map, a slice, an *OrderedMap or an interface{}, and struct fields are
+// matched by their toon or json tags. Types implementing ToonUnmarshaler
+// parse their own values. Numbers keep their written digits, so large
+// integers decode exactly into integer fields. Malformed documents return
+// a *SyntaxError, or a *CountMismatchError for counts that do not match;
+// values that do not fit v return a *TypeError with their path, including
+// numbers beyond the range of a sized integer or float field, negative
+// numbers for unsigned fields and fractions for integer fields.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v)
+}
+
+// UnmarshalWithOptions parses a TOON document written with the given
+// options and stores it in the value pointed to by v, as Unmarshal does.
+// The options are read as by FromToonWithOptions.
+func UnmarshalWithOptions(data []byte, v interface{}, opts ...Option) error {
+	e := newEncoder(opts)
+	doc, err := newDecoder(string(data), e).document()
+	if err != nil {
+		return err
+	}
+	return e.storeDocument(doc, v)
+}
+
+// WithDisallowUnknownFields makes UnmarshalWithOptions and Decoder.Decode
+// return an error when an object holds a key that matches no field of the
+// struct it is stored in, as json.Decoder.DisallowUnknownFields does, so
+// that misspelled keys are caught instead of dropped
+func WithDisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknown = true
+	}
+}
+
+// storeDocument stores a parsed document in the value pointed to by v,
+// through encoding/json unless v leads to a ToonUnmarshaler or to toon
+// struct tags
+func (e *encoder) storeDocument(doc, v interface{}) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() && storesNatively(rv.Type().Elem()) {
+		return e.storeValue(doc, rv.Elem())
+	}
+	return e.unmarshalJSON(doc, v)
+}
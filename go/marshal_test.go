@@ -0,0 +1,277 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type marshalUser struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags,omitempty"`
+	Admin bool     `json:"admin"`
+}
+
+type marshalTeam struct {
+	Team  string        `json:"team"`
+	Users []marshalUser `json:"users"`
+}
+
+func TestMarshal(t *testing.T) {
+	result, err := Marshal(map[string]interface{}{"ids": []interface{}{1, 2}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "ids:\n  - 1\n  - 2"; string(result) != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	SetDefaults(WithAssertSafe())
+	defer SetDefaults()
+	var unsafe *UnsafeValuesError
+	if _, err := Marshal(map[string]interface{}{"a": "x\ny"}); !errors.As(err, &unsafe) {
+		t.Errorf("Expected an *UnsafeValuesError, got: %v", err)
+	}
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+	team := marshalTeam{Team: "core", Users: []marshalUser{
+		{ID: 1, Name: "Ann", Tags: []string{"a", "b"}, Admin: true},
+		{ID: 2, Name: "Bob, Jr"},
+	}}
+	doc, err := Marshal(team)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var result marshalTeam
+	if err := Unmarshal(doc, &result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, team) {
+		t.Errorf("Expected %+v, got: %+v", team, result)
+	}
+}
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	team := marshalTeam{Team: "core", Users: []marshalUser{
+		{ID: 1, Name: "Ann", Admin: true},
+		{ID: 2, Name: "Bob, Jr"},
+	}}
+	opts := []Option{WithHeaderStyle(ParenHeader), WithLengthMarkers(false), WithMissingCell("-")}
+	doc := ToToonWithOptions(team, opts...)
+	var result marshalTeam
+	if err := UnmarshalWithOptions([]byte(doc), &result, opts...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, team) {
+		t.Errorf("Expected %+v, got: %+v from %q", team, result, doc)
+	}
+}
+
+func TestUnmarshalWithOptions_DisallowUnknownFields(t *testing.T) {
+	doc := []byte("id: 1\nname: Ann\nnmae: Bob")
+	var user marshalUser
+	if err := Unmarshal(doc, &user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := UnmarshalWithOptions(doc, &user, WithDisallowUnknownFields()); err == nil || !strings.Contains(err.Error(), `"nmae"`) {
+		t.Errorf("Expected an unknown field error, got: %v", err)
+	}
+
+	// structs with toon tags are stored without encoding/json
+	var tagged struct {
+		Team  string `toon:"team"`
+		Users []struct {
+			Name string `toon:"name"`
+		} `toon:"users"`
+	}
+	doc = []byte("team: core\nusers[1]{name,age}:\n  Ann,30")
+	err := UnmarshalWithOptions(doc, &tagged, WithDisallowUnknownFields())
+	if err == nil || err.Error() != `totoon: unknown field "users[0].age"` {
+		t.Errorf("Expected an unknown field error, got: %v", err)
+	}
+}
+
+func TestUnmarshal_TypeErrorPaths(t *testing.T) {
+	var team marshalTeam
+	var tagged struct {
+		Counts map[string][]int `toon:"counts"`
+	}
+	tests := []struct {
+		doc    string
+		v      interface{}
+		path   string
+		actual string
+	}{
+		{"team: core\nusers[2]{id,name}:\n  1,Ann\n  x,Bob", &team, "users[1].id", "string"},
+		{"counts:\n  a[2]: 1,x", &tagged, "counts.a[1]", "string"},
+		{"counts[1]: 1", &tagged, "counts", "array"},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.doc), tt.v)
+		var typeErr *TypeError
+		if !errors.As(err, &typeErr) || typeErr.Path != tt.path || typeErr.Actual != tt.actual {
+			t.Errorf("%q: Expected a *TypeError for %s at %s, got: %v", tt.doc, tt.actual, tt.path, err)
+		}
+	}
+}
+
+func TestUnmarshal_LargeIntegers(t *testing.T) {
+	var user marshalUser
+	if err := Unmarshal([]byte("id: 9007199254740993\nname: Ann"), &user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 9007199254740993 {
+		t.Errorf("Expected %d, got: %d", int64(9007199254740993), user.ID)
+	}
+}
+
+func TestUnmarshal_Maps(t *testing.T) {
+	doc := []byte("b: 1\na:\n  c: x")
+	var m map[string]interface{}
+	if err := Unmarshal(doc, &m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{"a": map[string]interface{}{"c": "x"}, "b": float64(1)}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected %v, got: %v", expected, m)
+	}
+
+	var ordered OrderedMap
+	if err := Unmarshal(doc, &ordered); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := ordered.Keys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Errorf("Expected keys [b a], got: %v", keys)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	var syntaxErr *SyntaxError
+	if err := Unmarshal([]byte("ids[2]: 1"), &[]int{}); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected a *SyntaxError, got: %v", err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var user marshalUser
+	if err := Unmarshal([]byte("id: Ann"), &user); !errors.As(err, &typeErr) {
+		t.Errorf("Expected a *json.UnmarshalTypeError, got: %v", err)
+	}
+
+	var storeErr *TypeError
+	if err := Unmarshal([]byte("id: Ann"), &user); !errors.As(err, &storeErr) || storeErr.Path != "id" {
+		t.Errorf("Expected a *TypeError at id, got: %v", err)
+	}
+
+	var invalid *json.InvalidUnmarshalError
+	if err := Unmarshal([]byte("a: 1"), user); !errors.As(err, &invalid) {
+		t.Errorf("Expected a *json.InvalidUnmarshalError, got: %v", err)
+	}
+}
+
+func TestUnmarshal_RangeErrors(t *testing.T) {
+	var sized struct {
+		Small  int8             `json:"small"`
+		Port   uint16           `json:"port"`
+		Bytes  []uint8          `json:"bytes"`
+		Ratio  float32          `json:"ratio"`
+		ByCode map[string]int8  `json:"by_code"`
+		Keyed  map[int8]float64 `json:"keyed"`
+	}
+	var tagged struct {
+		Small int8 `toon:"small"`
+		Items []struct {
+			Count uint `toon:"count"`
+		} `toon:"items"`
+	}
+	tests := []struct {
+		doc    string
+		v      interface{}
+		path   string
+		actual string
+	}{
+		{"small: 300", &sized, "small", "number 300"},
+		{"port: -1", &sized, "port", "number -1"},
+		{"bytes[2]: 1,256", &sized, "bytes[1]", "number 256"},
+		{"ratio: 1e40", &sized, "ratio", "number 1e40"},
+		{"small: 1.5", &sized, "small", "number 1.5"},
+		{"by_code:\n  7: 128", &sized, "by_code.7", "number 128"},
+		{"keyed:\n  300: 1", &sized, "keyed.300", "number 300"},
+		{"small: -129", &tagged, "small", "number -129"},
+		{"items[2]{count}:\n  1\n  -2", &tagged, "items[1].count", "number -2"},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.doc), tt.v)
+		var typeErr *TypeError
+		if !errors.As(err, &typeErr) || typeErr.Path != tt.path || typeErr.Actual != tt.actual {
+			t.Errorf("%q: Expected a *TypeError for %s at %s, got: %v", tt.doc, tt.actual, tt.path, err)
+		}
+	}
+}
+
+// Integer fields never take a number written with a fraction or exponent,
+// even an integral one such as 2.0 or 1e3, so nothing is truncated and no
+// strict mode is needed
+func TestUnmarshal_FractionalIntoInteger(t *testing.T) {
+	tests := []struct {
+		doc    string
+		path   string
+		actual string
+	}{
+		{"count: 3.7", "count", "number 3.7"},
+		{"count: 2.0", "count", "number 2.0"},
+		{"total: 1e3", "total", "number 1e3"},
+		{"ids[2]: 1,2.5", "ids[1]", "number 2.5"},
+		{"rows[1]{n}:\n  -0.5", "rows[0].n", "number -0.5"},
+		{"by_id:\n  1.5: a", "by_id.1.5", "number 1.5"},
+	}
+	for _, tt := range tests {
+		var v struct {
+			Count int               `json:"count"`
+			Total uint64            `json:"total"`
+			IDs   []int32           `json:"ids"`
+			Rows  []struct{ N int } `json:"rows"`
+			ByID  map[int]string    `json:"by_id"`
+		}
+		err := Unmarshal([]byte(tt.doc), &v)
+		var typeErr *TypeError
+		if !errors.As(err, &typeErr) || typeErr.Path != tt.path || typeErr.Actual != tt.actual {
+			t.Errorf("%q: Expected a *TypeError for %s at %s, got: %v", tt.doc, tt.actual, tt.path, err)
+		}
+		if v.Count != 0 || v.Total != 0 {
+			t.Errorf("%q: Expected integer fields to stay unset, got: %+v", tt.doc, v)
+		}
+	}
+}
+
+func TestUnmarshalWithOptions_StringScalars(t *testing.T) {
+	doc := []byte("name: Ann\nage: 30\nscore: 1.50\nadmin: true\nnote: null\nsizes[2]: 9007199254740993,M\nusers[1]{id,ok}:\n  7,false")
+	var display struct {
+		Name  string              `json:"name"`
+		Age   string              `json:"age"`
+		Score string              `json:"score"`
+		Admin string              `json:"admin"`
+		Note  string              `json:"note"`
+		Sizes []string            `json:"sizes"`
+		Users []map[string]string `json:"users"`
+	}
+	if err := UnmarshalWithOptions(doc, &display, WithStringScalars()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if display.Age != "30" || display.Score != "1.50" || display.Admin != "true" || display.Note != "" ||
+		!reflect.DeepEqual(display.Sizes, []string{"9007199254740993", "M"}) ||
+		!reflect.DeepEqual(display.Users, []map[string]string{{"id": "7", "ok": "false"}}) {
+		t.Errorf("Expected every scalar as a string, got: %+v", display)
+	}
+
+	var flat map[string]string
+	if err := UnmarshalWithOptions([]byte("a: 1\nb: yes\nc: false"), &flat, WithStringScalars()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := map[string]string{"a": "1", "b": "yes", "c": "false"}; !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got: %v", expected, flat)
+	}
+}
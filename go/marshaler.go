@@ -0,0 +1,98 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ToonMarshaler is implemented by types that write their own TOON, as
+// json.Marshaler is for JSON. MarshalTOON returns a TOON fragment that is
+// inserted like a RawMessage: a scalar, or a document re-indented to where
+// the value appears. It is consulted before extensions and before any
+// other encoding of the value.
+type ToonMarshaler interface {
+	MarshalTOON() ([]byte, error)
+}
+
+// MarshalerError is returned by Encode, EncodeTo and Marshal when a
+// MarshalTOON, MarshalJSON or MarshalText method fails
+type MarshalerError struct {
+	Type   reflect.Type
+	Err    error
+	method string
+}
+
+func (err *MarshalerError) Error() string {
+	method := err.method
+	if method == "" {
+		method = "MarshalTOON"
+	}
+	return fmt.Sprintf("totoon: error calling %s for type %s: %v", method, err.Type, err.Err)
+}
+
+func (err *MarshalerError) Unwrap() error {
+	return err.Err
+}
+
+// marshalTOON returns the fragment written for m
+func (e *encoder) marshalTOON(m ToonMarshaler) interface{} {
+	if isNilPointer(m) {
+		return nil
+	}
+	out, err := m.MarshalTOON()
+	if err != nil {
+		return e.marshalFailed(m, "MarshalTOON", err)
+	}
+	return RawMessage(out)
+}
+
+// marshalSelf returns the value written for a json.Marshaler, decoded from
+// its JSON with the key order kept, or the string of an
+// encoding.TextMarshaler
+func (e *encoder) marshalSelf(v interface{}) (interface{}, bool) {
+	switch m := v.(type) {
+	case json.Marshaler:
+		if isNilPointer(v) {
+			return nil, true
+		}
+		out, err := m.MarshalJSON()
+		if err != nil {
+			return e.marshalFailed(v, "MarshalJSON", err), true
+		}
+		dec := json.NewDecoder(bytes.NewReader(out))
+		dec.UseNumber()
+		decoded, err := decodeOrdered(dec)
+		if err != nil {
+			return e.marshalFailed(v, "MarshalJSON", err), true
+		}
+		return decoded, true
+	case encoding.TextMarshaler:
+		if isNilPointer(v) {
+			return nil, true
+		}
+		out, err := m.MarshalText()
+		if err != nil {
+			return e.marshalFailed(v, "MarshalText", err), true
+		}
+		return string(out), true
+	}
+	return nil, false
+}
+
+// marshalFailed records the error of a marshaling method for the functions
+// that return one, and writes null in place of the value
+func (e *encoder) marshalFailed(v interface{}, method string, err error) interface{} {
+	e.warn("%s of %T failed, rendered as null: %v", method, v, err)
+	if e.err == nil {
+		e.err = &MarshalerError{Type: reflect.TypeOf(v), Err: err, method: method}
+	}
+	return nil
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
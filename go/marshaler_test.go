@@ -0,0 +1,119 @@
+package totoon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type point struct{ X, Y int }
+
+func (p point) MarshalTOON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d;%d", p.X, p.Y)), nil
+}
+
+type shape struct {
+	Name   string
+	Points []point
+}
+
+func (s *shape) MarshalTOON() ([]byte, error) {
+	if s.Name == "" {
+		return nil, errors.New("shape has no name")
+	}
+	lines := []string{"name: " + s.Name, "points:"}
+	for _, p := range s.Points {
+		lines = append(lines, fmt.Sprintf("  - %d;%d", p.X, p.Y))
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func TestToonMarshaler(t *testing.T) {
+	var none *shape
+	data := map[string]interface{}{
+		"origin": point{0, 0},
+		"shape":  &shape{Name: "line", Points: []point{{1, 2}, {3, 4}}},
+		"rows":   []interface{}{map[string]interface{}{"at": point{5, 6}, "id": 1}},
+		"none":   none,
+	}
+	result := ToToon(data)
+	expected := "none: null\norigin: 0;0\nrows[1]{at,id}:\n  \"5;6\",1\nshape:\n  name: line\n  points:\n    - 1;2\n    - 3;4"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestToonMarshaler_BeforeExtensions(t *testing.T) {
+	RegisterExtension(Extension{Name: "point", Convert: func(v interface{}) (interface{}, bool) {
+		_, ok := v.(point)
+		return "extension", ok
+	}})
+	defer UnregisterExtension("point")
+
+	if result := ToToon(point{1, 2}); result != "1;2" {
+		t.Errorf("Expected %q, got: %q", "1;2", result)
+	}
+}
+
+func TestToonMarshaler_Error(t *testing.T) {
+	data := map[string]interface{}{"a": 1, "shape": &shape{}}
+
+	_, err := Encode(data)
+	var marshalErr *MarshalerError
+	if !errors.As(err, &marshalErr) || marshalErr.Err.Error() != "shape has no name" {
+		t.Fatalf("Expected a MarshalerError, got: %v", err)
+	}
+	if _, err := Marshal(data); !errors.As(err, &marshalErr) {
+		t.Errorf("Expected Marshal to return the MarshalerError, got: %v", err)
+	}
+
+	// the conversions without an error return write null
+	result, warnings := ToToonWithWarnings(data, withSortedKeys())
+	if expected := "a: 1\nshape: null"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected one warning, got: %v", warnings)
+	}
+}
+
+type jsonPair struct{ A, B int }
+
+func (p jsonPair) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"second":%d,"first":%d}`, p.B, p.A)), nil
+}
+
+type badText struct{}
+
+func (badText) MarshalText() ([]byte, error) {
+	return nil, errors.New("no text")
+}
+
+func TestSelfMarshalers(t *testing.T) {
+	stamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data := map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{"at": stamp, "ip": net.IPv4(10, 0, 0, 1)},
+		},
+		"pair": jsonPair{1, 2},
+	}
+	result := ToToon(data)
+	expected := "events[1]{at,ip}:\n  \"2024-05-01T12:00:00Z\",10.0.0.1\npair:\n  second: 2\n  first: 1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestSelfMarshalers_Error(t *testing.T) {
+	_, err := Encode(map[string]interface{}{"v": badText{}})
+	var marshalErr *MarshalerError
+	if !errors.As(err, &marshalErr) {
+		t.Fatalf("Expected a MarshalerError, got: %v", err)
+	}
+	if expected := "totoon: error calling MarshalText for type totoon.badText: no text"; err.Error() != expected {
+		t.Errorf("Expected %q, got: %q", expected, err.Error())
+	}
+}
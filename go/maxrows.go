@@ -0,0 +1,27 @@
+package totoon
+
+import "fmt"
+
+// WithMaxRows writes at most n rows of each table, followed by a footer
+// line such as "… +42 more rows" in place of the rest. The header keeps
+// the full row count, so readers know how much was left out. Tables inside
+// cells and transposed tables are not limited.
+func WithMaxRows(n int) Option {
+	return func(o *options) {
+		o.maxRows = n
+	}
+}
+
+// rowOverflow returns the footer that replaces the rows of a table of
+// total rows from index row on, when WithMaxRows cuts the table there
+func (e *encoder) rowOverflow(row, total int) (string, bool) {
+	if e.maxRows <= 0 || row < e.maxRows || row >= total {
+		return "", false
+	}
+	more := total - row
+	e.trace("%d of %d rows left out by the row limit", more, total)
+	if more == 1 {
+		return ellipsis + " +1 more row", true
+	}
+	return fmt.Sprintf("%s +%d more rows", ellipsis, more), true
+}
@@ -0,0 +1,36 @@
+package totoon
+
+import "testing"
+
+func TestWithMaxRows(t *testing.T) {
+	rows := make([]interface{}, 5)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i + 1}
+	}
+	data := map[string]interface{}{"users": rows}
+
+	tests := map[int]string{
+		2: "users[5]{id}:\n  1\n  2\n  … +3 more rows",
+		4: "users[5]{id}:\n  1\n  2\n  3\n  4\n  … +1 more row",
+		5: "users[5]{id}:\n  1\n  2\n  3\n  4\n  5",
+		0: "users[5]{id}:\n  1\n  2\n  3\n  4\n  5",
+	}
+	for n, expected := range tests {
+		result := ToToonWithOptions(data, WithMaxRows(n))
+		if result != expected {
+			t.Errorf("%d rows: Expected %q, got: %q", n, expected, result)
+		}
+	}
+}
+
+func TestWithMaxRows_WideTables(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"id": 1, "a": 1, "b": 2},
+		map[string]interface{}{"id": 2, "a": 3, "b": 4},
+	}
+	result := ToToonWithOptions(rows, withSortedKeys(), WithMaxRows(1), WithMaxColumns(2, SplitWideTables))
+	expected := "1[2]{id,a}:\n  1,1\n  … +1 more row\n2[2]{id,b}:\n  1,2\n  … +1 more row"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,64 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// MergeFiles combines several JSON documents into one TOON document, each
+// under its own top-level key, in the order given. An empty key is derived
+// from the reader's file name when it has one (as *os.File does), without
+// directory or extension, so "testdata/users.json" becomes "users". Keys
+// within each document keep their source order.
+func MergeFiles(keys []string, readers []io.Reader, opts ...Option) (string, error) {
+	if len(keys) != len(readers) {
+		return "", fmt.Errorf("totoon: %d keys for %d readers", len(keys), len(readers))
+	}
+
+	merged := NewOrderedMap()
+	for i, r := range readers {
+		key := keys[i]
+		if key == "" {
+			key = readerKey(r)
+		}
+		if key == "" {
+			return "", fmt.Errorf("totoon: input %d has no key and no file name", i)
+		}
+		if _, taken := merged.Get(key); taken {
+			return "", fmt.Errorf("totoon: duplicate key %q for input %d", key, i)
+		}
+
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		value, err := decodeOrdered(dec)
+		if err == nil {
+			if _, err = dec.Token(); err == io.EOF {
+				err = nil
+			} else if err == nil {
+				err = errors.New("unexpected data after the JSON document")
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("totoon: input %q: %w", key, err)
+		}
+		merged.Set(key, value)
+	}
+	return newEncoder(opts).encode(merged), nil
+}
+
+// readerKey derives a key from the file name of r, if it has one
+func readerKey(r io.Reader) string {
+	named, ok := r.(interface{ Name() string })
+	if !ok {
+		return ""
+	}
+	base := filepath.Base(named.Name())
+	if base == "." || base == string(filepath.Separator) {
+		return ""
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,57 @@
+package totoon
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(`[{"id": 1, "name": "Alice"}, {"id": 2, "name": "Bob"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	result, err := MergeFiles(
+		[]string{"config", ""},
+		[]io.Reader{strings.NewReader(`{"version": 2, "debug": false}`), f},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "config:\n  version: 2\n  debug: false\nusers[2]{id,name}:\n  1,Alice\n  2,Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestMergeFiles_Errors(t *testing.T) {
+	tests := []struct {
+		keys    []string
+		inputs  []string
+		message string
+	}{
+		{[]string{"a"}, nil, "1 keys for 0 readers"},
+		{[]string{""}, []string{`{}`}, "input 0 has no key"},
+		{[]string{"a", "a"}, []string{`{}`, `{}`}, `duplicate key "a"`},
+		{[]string{"a"}, []string{`{"x": `}, `input "a"`},
+		{[]string{"a"}, []string{`1 2`}, "unexpected data"},
+	}
+	for _, test := range tests {
+		readers := make([]io.Reader, len(test.inputs))
+		for i, input := range test.inputs {
+			readers[i] = strings.NewReader(input)
+		}
+		_, err := MergeFiles(test.keys, readers)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Expected an error containing %q, got: %v", test.message, err)
+		}
+	}
+}
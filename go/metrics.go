@@ -0,0 +1,82 @@
+package totoon
+
+import (
+	"expvar"
+	"io"
+)
+
+// Names of the counters reported to Metrics
+const (
+	MetricDocumentsEncoded = "documents_encoded" // documents converted to TOON
+	MetricBytesIn          = "bytes_in"          // JSON bytes read by the JSON entry points
+	MetricBytesOut         = "bytes_out"         // TOON bytes produced
+	MetricTruncations      = "truncations"       // values cut by WithColumnMaxWidth
+)
+
+// Metrics receives counters from conversions, for monitoring TOON usage.
+// Count is called with one of the Metric names and the amount to add. It
+// may be called from several goroutines at once.
+type Metrics interface {
+	Count(name string, n int)
+}
+
+// WithMetrics reports counters to m. Documents are counted by the
+// functions that convert a whole value (ToToon and its variants, Encode,
+// EncodeTo, JSONToToon, MergeFiles), by NewToonWriter and by ConvertFS for
+// JSON files. Combine it with SetDefaults to monitor every conversion in a
+// process.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// ExpvarMetrics returns Metrics that add every counter to m under its name
+func ExpvarMetrics(m *expvar.Map) Metrics {
+	return expvarMetrics{m}
+}
+
+type expvarMetrics struct {
+	m *expvar.Map
+}
+
+func (em expvarMetrics) Count(name string, n int) {
+	em.m.Add(name, int64(n))
+}
+
+// count adds n to the named counter when metrics are enabled
+func (e *encoder) count(name string, n int) {
+	if e.metrics != nil {
+		e.metrics.Count(name, n)
+	}
+}
+
+// encoded records a finished document of n bytes
+func (e *encoder) encoded(n int) {
+	e.count(MetricDocumentsEncoded, 1)
+	e.count(MetricBytesOut, n)
+}
+
+// byteCounter counts the bytes written through it
+type byteCounter struct {
+	w io.Writer
+	n int
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// byteReader counts the bytes read through it
+type byteReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *byteReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,87 @@
+package totoon
+
+import (
+	"bytes"
+	"expvar"
+	"sync"
+	"testing"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *recordingMetrics) Count(name string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[name] += n
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "name": "Alexander"},
+		map[string]interface{}{"id": 2, "name": "Bob"},
+	}
+	out := ToToonWithOptions(data, WithMetrics(m), WithColumnMaxWidth(map[string]int{"name": 4}))
+
+	var buf bytes.Buffer
+	if err := EncodeTo(&buf, data, WithMetrics(m)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]int{
+		MetricDocumentsEncoded: 2,
+		MetricBytesOut:         len(out) + buf.Len(),
+		MetricTruncations:      1,
+	}
+	for name, n := range expected {
+		if m.counts[name] != n {
+			t.Errorf("%s: Expected %d, got: %d", name, n, m.counts[name])
+		}
+	}
+}
+
+func TestWithMetrics_JSONInput(t *testing.T) {
+	m := &recordingMetrics{}
+	input := `{"a": [1, 2, 3]}`
+
+	var buf bytes.Buffer
+	w := NewToonWriter(&buf, WithMetrics(m))
+	w.Write([]byte(input))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	SetDefaults(WithMetrics(m))
+	defer SetDefaults()
+	out, err := JSONToToon(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if m.counts[MetricBytesIn] != 2*len(input) {
+		t.Errorf("Expected %d bytes in, got: %d", 2*len(input), m.counts[MetricBytesIn])
+	}
+	if m.counts[MetricBytesOut] != buf.Len()+len(out) {
+		t.Errorf("Expected %d bytes out, got: %d", buf.Len()+len(out), m.counts[MetricBytesOut])
+	}
+	if m.counts[MetricDocumentsEncoded] != 2 {
+		t.Errorf("Expected 2 documents, got: %d", m.counts[MetricDocumentsEncoded])
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m := new(expvar.Map).Init()
+	ToToonWithOptions(map[string]interface{}{"a": 1}, WithMetrics(ExpvarMetrics(m)))
+	if v := m.Get(MetricDocumentsEncoded); v == nil || v.String() != "1" {
+		t.Errorf("Expected 1 document, got: %v", v)
+	}
+	if v := m.Get(MetricBytesOut); v == nil || v.String() != "4" {
+		t.Errorf("Expected 4 bytes, got: %v", v)
+	}
+}
@@ -0,0 +1,23 @@
+package totoon
+
+// WithMissingCell writes marker in table cells whose row lacks the column,
+// so missing keys can be told apart from empty strings. Once it is set,
+// empty strings and strings equal to marker are quoted, and nulls keep
+// rendering as null, making missing, null and "" distinct in every table.
+func WithMissingCell(marker string) Option {
+	return func(o *options) {
+		o.missing = marker
+		o.missingSet = true
+	}
+}
+
+// missingCell returns the cell written for a column a row does not have
+func (e *encoder) missingCell() string {
+	return e.missing
+}
+
+// ambiguousCell reports whether the string s would read as a missing cell
+// and must be quoted
+func (e *encoder) ambiguousCell(s string) bool {
+	return e.missingSet && (s == "" || s == e.missing)
+}
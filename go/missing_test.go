@@ -0,0 +1,39 @@
+package totoon
+
+import "testing"
+
+func TestWithMissingCell(t *testing.T) {
+	data := map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"a": "x", "b": nil, "c": ""},
+			map[string]interface{}{"a": "-", "c": "y"},
+			map[string]interface{}{"b": 1},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithMissingCell("-"))
+	expected := "rows[3]{a,b,c}:\n  x,null,\"\"\n  \"-\",-,y\n  -,1,-"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// without the option, missing cells and empty strings are both empty
+	result = ToToonWithOptions(data, withSortedKeys())
+	expected = "rows[3]{a,b,c}:\n  x,null,\n  -,,y\n  ,1,"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithMissingCell_NestedTables(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 1, "items": []interface{}{
+			map[string]interface{}{"k": "a", "v": ""},
+			map[string]interface{}{"k": "b"},
+		}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithMissingCell("~"))
+	expected := "[1]{id,items}:\n  1,[2]{k,v}:a,\"\";b,~"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,53 @@
+package totoon
+
+import "strings"
+
+// defaultNestedRowSeparator separates the rows of a table nested in a cell
+const defaultNestedRowSeparator = ";"
+
+// WithNestedRowSeparator sets the separator placed between the rows of a
+// table nested inside a cell ([2]{a,b}:1,x;2,y), replacing the default ";"
+// for data where semicolons are common. Values containing the separator are
+// quoted. Separators that would clash with the surrounding syntax (empty,
+// ",", ":", quotes, backslashes or line breaks) are ignored.
+func WithNestedRowSeparator(sep string) Option {
+	return func(o *options) {
+		if sep == "" || strings.ContainsAny(sep, ",:\"'\\\n\r") {
+			return
+		}
+		o.nestedSep = sep
+	}
+}
+
+// nestedRowSeparator returns the separator between nested table rows
+func (e *encoder) nestedRowSeparator() string {
+	if e.nestedSep != "" {
+		return e.nestedSep
+	}
+	return defaultNestedRowSeparator
+}
+
+// nestedCellToToon renders a value inside a nested table row, quoting it when
+// it contains a field or row separator, or the delimiter of the outer row.
+// Backslashes and quotes inside quoted values are escaped so the value reads
+// back unchanged.
+func (e *encoder) nestedCellToToon(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return e.stringCell(val, e.breaksNestedRow)
+	case bool:
+		return e.boolCell(val)
+	}
+	value := e.valueToToonInline(v)
+	if !e.breaksNestedRow(value) {
+		return value
+	}
+	return e.quote(value)
+}
+
+// breaksNestedRow reports whether value, written bare in a nested table row,
+// would be split by a separator
+func (e *encoder) breaksNestedRow(value string) bool {
+	return strings.Contains(value, ",") || strings.Contains(value, ":") ||
+		strings.Contains(value, e.nestedRowSeparator()) || strings.Contains(value, e.delimiterString())
+}
@@ -0,0 +1,53 @@
+package totoon
+
+import "testing"
+
+func nestedRowsData() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"note": "a;b"},
+			map[string]interface{}{"note": "c|d"},
+		}},
+	}
+}
+
+func TestNestedRows_DefaultSeparator(t *testing.T) {
+	result := ToToon(nestedRowsData())
+	expected := `[1]{items}:
+  [2]{note}:"a;b";c|d`
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithNestedRowSeparator(t *testing.T) {
+	result := ToToonWithOptions(nestedRowsData(), WithNestedRowSeparator("|"))
+	expected := `[1]{items}:
+  [2]{note}:a;b|"c|d"`
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithNestedRowSeparator_InvalidIgnored(t *testing.T) {
+	for _, sep := range []string{"", ",", ":", `"`} {
+		result := ToToonWithOptions(nestedRowsData(), WithNestedRowSeparator(sep))
+		if result != ToToon(nestedRowsData()) {
+			t.Errorf("Expected separator %q to be ignored, got: %q", sep, result)
+		}
+	}
+}
+
+func TestNestedRows_EscapesQuotedValues(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"path": `C:\dir "x"`},
+		}},
+	}
+	result := ToToon(data)
+	expected := `[1]{items}:
+  [1]{path}:"C:\\dir \"x\""`
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,77 @@
+package totoon
+
+import (
+	"fmt"
+	"math"
+)
+
+// NonFinitePolicy selects how NaN and infinite floats are written
+type NonFinitePolicy int
+
+const (
+	// NonFiniteDefault writes NaN, +Inf and -Inf in DialectV1 and null
+	// in DialectV2
+	NonFiniteDefault NonFinitePolicy = iota
+	// NonFiniteNull writes null
+	NonFiniteNull
+	// NonFiniteString writes the quoted strings "NaN", "+Inf" and "-Inf"
+	NonFiniteString
+	// NonFiniteError writes null and makes Encode, EncodeTo and Marshal
+	// fail with an *UnsupportedValueError
+	NonFiniteError
+)
+
+// WithNonFinite selects how NaN and infinite floats are written. The bare
+// NaN and +Inf of DialectV1 read as strings to most decoders; null keeps
+// documents parseable and a quoted string keeps the value visible.
+func WithNonFinite(policy NonFinitePolicy) Option {
+	return func(o *options) {
+		o.nonFinite = policy
+	}
+}
+
+// UnsupportedValueError reports a value the encoder refused to write: a
+// NaN or infinity under NonFiniteError, or a pointer, map or slice that
+// contains itself
+type UnsupportedValueError struct {
+	Path  string // location of the value, "" for the root or a cycle
+	Value string // the value, or the type of the reference for a cycle
+
+	cycle bool
+}
+
+func (err *UnsupportedValueError) Error() string {
+	msg := "unsupported value " + err.Value
+	if err.cycle {
+		msg = "encountered a cycle via " + err.Value
+	}
+	if err.Path == "" {
+		return "totoon: " + msg
+	}
+	return fmt.Sprintf("totoon: %s at %s", msg, err.Path)
+}
+
+// nonFiniteNumber renders a NaN or infinite float, reporting false for
+// finite numbers and under NonFiniteDefault
+func (e *encoder) nonFiniteNumber(v interface{}) (string, bool) {
+	if e.nonFinite == NonFiniteDefault || e.canonical {
+		return "", false
+	}
+	f, ok := v.(float64)
+	if f32, is32 := v.(float32); is32 {
+		f, ok = float64(f32), true
+	}
+	if !ok || !(math.IsNaN(f) || math.IsInf(f, 0)) {
+		return "", false
+	}
+	text := formatPlainNumber(f)
+	switch e.nonFinite {
+	case NonFiniteString:
+		return e.quote(text), true
+	case NonFiniteError:
+		if e.err == nil {
+			e.err = &UnsupportedValueError{Path: e.path, Value: text}
+		}
+	}
+	return "null", true
+}
@@ -0,0 +1,54 @@
+package totoon
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWithNonFinite(t *testing.T) {
+	data := map[string]interface{}{
+		"nan":  math.NaN(),
+		"rows": []interface{}{map[string]interface{}{"v": math.Inf(1)}, map[string]interface{}{"v": float32(math.Inf(-1))}},
+		"ok":   1.5,
+	}
+	tests := []struct {
+		policy   NonFinitePolicy
+		expected string
+	}{
+		{NonFiniteDefault, "nan: NaN\nok: 1.5\nrows[2]{v}:\n  +Inf\n  -Inf"},
+		{NonFiniteNull, "nan: null\nok: 1.5\nrows[2]{v}:\n  null\n  null"},
+		{NonFiniteString, "nan: \"NaN\"\nok: 1.5\nrows[2]{v}:\n  \"+Inf\"\n  \"-Inf\""},
+	}
+	for _, tt := range tests {
+		result := ToToonWithOptions(data, WithNonFinite(tt.policy))
+		if result != tt.expected {
+			t.Errorf("Policy %d: expected %q, got: %q", tt.policy, tt.expected, result)
+		}
+	}
+
+	// DialectV2 writes null by default and still honors an explicit policy
+	if result := ToToonWithOptions(math.NaN(), WithDialect(DialectV2)); result != "null" {
+		t.Errorf("Expected %q, got: %q", "null", result)
+	}
+	if result := ToToonWithOptions(math.NaN(), WithDialect(DialectV2), WithNonFinite(NonFiniteString)); result != `"NaN"` {
+		t.Errorf("Expected %q, got: %q", `"NaN"`, result)
+	}
+}
+
+func TestWithNonFinite_Error(t *testing.T) {
+	data := map[string]interface{}{"a": map[string]interface{}{"b": math.Inf(-1)}}
+	_, err := Encode(data, WithNonFinite(NonFiniteError))
+	var unsupported *UnsupportedValueError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected an UnsupportedValueError, got: %v", err)
+	}
+	if expected := "totoon: unsupported value -Inf at a.b"; err.Error() != expected {
+		t.Errorf("Expected %q, got: %q", expected, err.Error())
+	}
+
+	// the conversions that return no error write null
+	if result := ToToonWithOptions(data, WithNonFinite(NonFiniteError)); result != "a:\n  b: null" {
+		t.Errorf("Expected %q, got: %q", "a:\n  b: null", result)
+	}
+}
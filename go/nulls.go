@@ -0,0 +1,18 @@
+package totoon
+
+// WithOmitNulls leaves out object entries whose value is null, including
+// nil pointers, maps and slices, so optional fields cost no tokens. Fields
+// of table rows are left out the same way and count as missing cells (see
+// WithMissingCell). Null list items are kept, as dropping them would shift
+// the positions of the rest.
+func WithOmitNulls() Option {
+	return func(o *options) {
+		o.omitNulls = true
+	}
+}
+
+// omitted reports whether v, an object entry already converted by unorder,
+// is left out
+func (e *encoder) omitted(v interface{}) bool {
+	return e.omitNulls && v == nil
+}
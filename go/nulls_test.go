@@ -0,0 +1,38 @@
+package totoon
+
+import "testing"
+
+func TestWithOmitNulls(t *testing.T) {
+	type profile struct {
+		Name  string  `toon:"name"`
+		Email *string `toon:"email"`
+	}
+	data := map[string]interface{}{
+		"a":       nil,
+		"b":       1,
+		"ordered": NewOrderedMap().Set("z", nil).Set("y", 2).Set("x", nil),
+		"profile": profile{Name: "Ann"},
+		"items":   []interface{}{1, nil},
+		"rows": []interface{}{
+			map[string]interface{}{"id": 1, "note": nil},
+			map[string]interface{}{"id": 2, "note": "x"},
+		},
+	}
+	result := ToToonWithOptions(data, WithOmitNulls(), WithMissingCell("-"), withSortedKeys())
+	expected := "b: 1\nitems:\n  - 1\n  - null\nordered:\n  y: 2\nprofile:\n  name: Ann\nrows[2]{id,note}:\n  1,-\n  2,x"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	rows := []map[string]interface{}{{"id": 1, "note": nil}, {"id": 2}}
+	result = ToToonWithOptions(rows, WithOmitNulls(), withSortedKeys())
+	if expected := "[2]{id}:\n  1\n  2"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// without the option nulls are written
+	result = ToToonWithOptions(map[string]interface{}{"a": nil}, withSortedKeys())
+	if expected := "a: null"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
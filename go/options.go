@@ -0,0 +1,263 @@
+package totoon
+
+import (
+	"bufio"
+	"io"
+	"sort"
+)
+
+// Option configures how values are converted to TOON format
+type Option func(*options)
+
+type options struct {
+	indent      int
+	groupBy     string
+	sortRows    []sortKey
+	dedup       bool
+	dedupCount  bool
+	sortKeys    bool
+	canonical   bool
+	expanded    bool
+	bufferSize  int
+	csvHeader   []string
+	keyOrder    map[string]int
+	logGroups   []string
+	maxWidths   map[string]int
+	noLengths   bool
+	headerStyle HeaderStyle
+	subTables   bool
+	nestedSep   string
+	quoteStyle  QuoteStyle
+	literal     bool
+	foldWidth   int
+	assertSafe  bool
+	keyLess     func(a, b string) bool
+	sortRest    bool
+	formatters  []formatter
+	enums       []enumLabels
+	converters  map[string]FileConverter
+	metrics     Metrics
+	traceTo     io.Writer
+	transpose   bool
+	maxColumns  int
+	wideMode    WideTableMode
+	aliases     map[string]string
+	missing     string
+	missingSet  bool
+	boolStyle   BoolStyle
+	rowFilter   func(row map[string]interface{}) bool
+	maxRows     int
+	summary     []Aggregate
+	dialect     Dialect
+	jsonIndent  int
+	omitNulls   bool
+	nonFinite   NonFinitePolicy
+	timeFormat  TimeFormat
+	timeLayout  string
+	delimiter   Delimiter
+
+	tableMinRows  int
+	maxMissing    float64
+	maxMissingSet bool
+
+	disallowUnknown bool
+	duplicateKeys   DuplicateKeyPolicy
+	maxDocSize      int
+	stringScalars   bool
+}
+
+func defaultOptions() options {
+	return options{
+		indent: 2,
+	}
+}
+
+// WithIndent sets the number of spaces used per nesting level
+func WithIndent(indent int) Option {
+	return func(o *options) {
+		o.indent = indent
+	}
+}
+
+// WithGroupBy splits lists of objects into one sub-table per distinct value
+// of field. The grouping column is removed from the rows and each sub-table
+// is nested under a header named after its group value, quoted like an
+// object key when needed. A string and a non-string value with the same
+// text, such as "1" and 1, form separate groups.
+func WithGroupBy(field string) Option {
+	return func(o *options) {
+		o.groupBy = field
+	}
+}
+
+// WithSortRows orders the rows of every table by the given keys, each written
+// as a field name optionally followed by "asc" or "desc" (for example
+// "age desc"). Later keys break ties left by earlier ones; rows that still
+// compare equal keep their input order.
+func WithSortRows(keys ...string) Option {
+	return func(o *options) {
+		o.sortRows = parseSortKeys(keys)
+	}
+}
+
+// WithDedupRows drops rows that exactly duplicate an earlier row of the same
+// table, keeping the first occurrence.
+func WithDedupRows() Option {
+	return func(o *options) {
+		o.dedup = true
+	}
+}
+
+// WithDedupCounts behaves like WithDedupRows and additionally appends a "×"
+// column holding how many times each remaining row occurred.
+func WithDedupCounts() Option {
+	return func(o *options) {
+		o.dedup = true
+		o.dedupCount = true
+	}
+}
+
+// WithOneValuePerLine disables tabular and inline layouts: lists of objects
+// are written as list items holding indented objects, so every scalar ends up
+// on its own line. Output is larger but produces clean line-based diffs when
+// TOON artifacts are stored in version control.
+func WithOneValuePerLine() Option {
+	return func(o *options) {
+		o.expanded = true
+	}
+}
+
+// WithBufferSize sets the size in bytes of the output buffer used by the
+// streaming writers (Writer and TableWriter). Small buffers reduce latency
+// for sockets and server-sent event streams at the cost of more write calls;
+// Flush can be used to push buffered output at any time. Zero or a negative
+// size selects the default of 4096 bytes.
+func WithBufferSize(size int) Option {
+	return func(o *options) {
+		o.bufferSize = size
+	}
+}
+
+// WithSortKeys sorts the keys of every object and the columns of every
+// table, struct fields included; map keys are sorted even without it.
+// OrderedMaps keep their insertion order.
+func WithSortKeys() Option {
+	return func(o *options) {
+		o.sortKeys = true
+	}
+}
+
+// WithKeyOrder puts the given keys first, in the given order, in every
+// object and table header. Remaining keys follow in sorted order.
+func WithKeyOrder(keys ...string) Option {
+	return func(o *options) {
+		o.keyOrder = keyRanks(keys)
+		o.sortRest = true
+	}
+}
+
+// WithPriorityKeys puts the given keys first, in the given order, in every
+// object and table header, since models anchor on leading fields. Unlike
+// WithKeyOrder, the remaining keys keep their normal order.
+func WithPriorityKeys(keys ...string) Option {
+	return func(o *options) {
+		o.keyOrder = keyRanks(keys)
+		o.sortRest = false
+	}
+}
+
+func keyRanks(keys []string) map[string]int {
+	ranks := make(map[string]int, len(keys))
+	for _, k := range keys {
+		if _, dup := ranks[k]; !dup {
+			ranks[k] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+// WithKeyLess orders the keys of every object and table header with less,
+// for domain-specific orderings such as status before details or timestamps
+// last. less must define a strict weak ordering. Keys placed by WithKeyOrder
+// or WithPriorityKeys still come first; less orders the rest.
+func WithKeyLess(less func(a, b string) bool) Option {
+	return func(o *options) {
+		o.keyLess = less
+	}
+}
+
+// encoder holds the resolved options and the state of a single conversion
+type encoder struct {
+	options
+
+	path     string                    // location of the value being encoded
+	warnings *[]Warning                // collected warnings, nil when not requested
+	unsafe   *[]Warning                // values that needed quoting, nil unless asserting
+	orders   map[uintptr]recordedOrder // key order of maps made from OrderedMaps
+
+	extensions []Extension       // registered extensions in priority order
+	err        error             // first error for the functions that return one
+	depth      int               // nesting of the value unorder is converting
+	visiting   map[visitKey]bool // references on the way to it, see reference
+}
+
+func newEncoder(opts []Option) *encoder {
+	e := &encoder{options: defaultOptions(), extensions: registeredExtensions()}
+	for _, opt := range defaultOpts() {
+		opt(&e.options)
+	}
+	for _, opt := range opts {
+		opt(&e.options)
+	}
+	return e
+}
+
+// objectKeys returns the keys of m in output order
+func (e *encoder) objectKeys(m map[string]interface{}) []string {
+	if keys, ok := e.recordedKeys(m); ok {
+		keys = append([]string(nil), keys...)
+		if !e.fixedOrder(m) {
+			e.orderKeys(keys, true)
+		}
+		return keys
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	e.orderKeys(keys, false)
+	return keys
+}
+
+// orderKeys sorts keys in place according to the key ordering options.
+// Without any, the keys of a plain map are sorted so that output does not
+// depend on map iteration order, and declared keys (struct fields) keep
+// their order.
+func (e *encoder) orderKeys(keys []string, declared bool) {
+	switch {
+	case e.keyLess != nil:
+		sort.SliceStable(keys, func(i, j int) bool { return e.keyLess(keys[i], keys[j]) })
+	case e.sortKeys || e.sortRest || !declared:
+		sort.Strings(keys)
+	}
+	if len(e.keyOrder) > 0 {
+		sort.SliceStable(keys, func(i, j int) bool { return e.keyRank(keys[i]) < e.keyRank(keys[j]) })
+	}
+}
+
+// keyRank returns the position of key in the WithKeyOrder or
+// WithPriorityKeys list, placing unlisted keys after all listed ones
+func (e *encoder) keyRank(key string) int {
+	if rank, ok := e.keyOrder[key]; ok {
+		return rank
+	}
+	return len(e.keyOrder)
+}
+
+// newBufferedWriter wraps w in a buffer sized according to WithBufferSize
+func (e *encoder) newBufferedWriter(w io.Writer) *bufio.Writer {
+	if e.bufferSize > 0 {
+		return bufio.NewWriterSize(w, e.bufferSize)
+	}
+	return bufio.NewWriter(w)
+}
@@ -0,0 +1,110 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithKeyOrder(t *testing.T) {
+	data := map[string]interface{}{"c": 3, "b": 2, "id": 1, "a": 0}
+	result := ToToonWithOptions(data, WithKeyOrder("id", "missing"))
+	expected := "id: 1\na: 0\nb: 2\nc: 3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestMapKeysSortedByDefault(t *testing.T) {
+	data := map[string]interface{}{
+		"c": 3, "b": 2, "a": map[string]interface{}{"z": 1, "y": 2, "x": 3},
+		"rows": []interface{}{
+			map[string]interface{}{"q": 1, "p": 2},
+			map[string]interface{}{"r": 3, "p": 4},
+		},
+	}
+	expected := ToToon(data)
+	for i := 0; i < 20; i++ {
+		if result := ToToon(data); result != expected {
+			t.Fatalf("Expected the same output on every run, got: %q and %q", expected, result)
+		}
+	}
+	if want := "a:\n  x: 3\n  y: 2\n  z: 1\nb: 2\nc: 3\nrows[2]{p,q,r}:\n  2,1,\n  4,,3"; expected != want {
+		t.Errorf("Expected %q, got: %q", want, expected)
+	}
+}
+
+func TestWithSortKeys(t *testing.T) {
+	type row struct {
+		Name string `toon:"name"`
+		ID   int    `toon:"id"`
+	}
+	data := map[string]interface{}{
+		"owner": row{"Ann", 1},
+		"rows":  []row{{"Bob", 2}},
+		"doc":   NewOrderedMap().Set("z", 1).Set("a", 2),
+	}
+	// struct fields keep their order unless sorting is asked for
+	result := ToToon(data)
+	expected := "doc:\n  z: 1\n  a: 2\nowner:\n  name: Ann\n  id: 1\nrows[1]{name,id}:\n  Bob,2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	result = ToToonWithOptions(data, WithSortKeys())
+	expected = "doc:\n  z: 1\n  a: 2\nowner:\n  id: 1\n  name: Ann\nrows[1]{id,name}:\n  2,Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithKeyLess(t *testing.T) {
+	// timestamps last, everything else alphabetical
+	less := func(a, b string) bool {
+		at, bt := strings.HasSuffix(a, "_at"), strings.HasSuffix(b, "_at")
+		if at != bt {
+			return bt
+		}
+		return a < b
+	}
+	data := map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"created_at": "t1", "status": "ok", "detail": "x"},
+		},
+		"id": 1,
+	}
+	result := ToToonWithOptions(data, WithKeyLess(less))
+	expected := "id: 1\nrows[1]{detail,status,created_at}:\n  x,ok,t1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithKeyLess_AfterKeyOrder(t *testing.T) {
+	reverse := func(a, b string) bool { return a > b }
+	data := map[string]interface{}{"a": 1, "b": 2, "c": 3, "id": 0}
+	result := ToToonWithOptions(data, WithKeyOrder("id"), WithKeyLess(reverse))
+	expected := "id: 0\nc: 3\nb: 2\na: 1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithPriorityKeys(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"zeta": 1, "type": "a", "alpha": 2, "id": 7},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithPriorityKeys("id", "name", "type"))
+	expected := "[1]{id,type,alpha,zeta}:\n  7,a,2,1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithPriorityKeys_RestKeepsNormalOrder(t *testing.T) {
+	reverse := func(a, b string) bool { return a > b }
+	data := map[string]interface{}{"a": 1, "b": 2, "id": 0}
+	result := ToToonWithOptions(data, WithKeyLess(reverse), WithPriorityKeys("id"))
+	expected := "id: 0\nb: 2\na: 1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,380 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// OrderedMap is an insertion-ordered object. The encoder writes its keys
+// exactly in insertion order, ignoring key ordering options, and lists of
+// OrderedMaps become tables whose columns follow the order in which fields
+// first appear. Decoding JSON into an OrderedMap keeps the key order of the
+// source, including in nested objects.
+//
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{}
+}
+
+// Set sets the value of key. A new key is appended; an existing key keeps
+// its position.
+func (m *OrderedMap) Set(key string, value interface{}) *OrderedMap {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return m
+}
+
+// Get returns the value of key and whether it is present
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Delete removes key, if present
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in insertion order
+func (m *OrderedMap) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Len returns the number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON writes the map as a JSON object in insertion order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads a JSON object, keeping its key order. Nested objects
+// become *OrderedMap values; other values decode as with encoding/json.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	om, ok := v.(*OrderedMap)
+	if !ok {
+		return errors.New("totoon: OrderedMap can only be decoded from a JSON object")
+	}
+	*m = *om
+	return nil
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return decodeOrderedToken(dec, tok)
+}
+
+// decodeOrderedToken decodes the value that starts with tok, which has
+// already been read from dec
+func decodeOrderedToken(dec *json.Decoder, tok json.Token) (interface{}, error) {
+	switch tok {
+	case json.Delim('{'):
+		m := NewOrderedMap()
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(keyTok.(string), value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	return tok, nil
+}
+
+// encode converts a complete document, first replacing OrderedMaps
+func (e *encoder) encode(data ToonValue) string {
+	out := e.toToon(e.unorder(data), 0)
+	e.encoded(len(out))
+	return out
+}
+
+// unorder replaces every *OrderedMap in v by a plain map whose key order is
+// recorded on the encoder, every ToonMarshaler by its fragment, every value
+// an extension accepts by its conversion, math/big numbers by json.Numbers,
+// times as WithTimeFormat writes them, json.Marshalers and
+// encoding.TextMarshalers by what they marshal to, and structs and typed
+// containers by their generic form (see reflectValue), and drops null
+// entries under WithOmitNulls, copying only the containers on the way to a
+// change. A value that contains itself is cut at the repeated reference,
+// which is written as null.
+func (e *encoder) unorder(v interface{}) interface{} {
+	out, _ := e.unorderValue(v)
+	return out
+}
+
+func (e *encoder) unorderValue(v interface{}) (interface{}, bool) {
+	key, tracked := e.reference(v)
+	if tracked {
+		if e.visiting[key] {
+			return e.cycle(key), true
+		}
+		if e.visiting == nil {
+			e.visiting = make(map[visitKey]bool)
+		}
+		e.visiting[key] = true
+	}
+	e.depth++
+	out, changed := e.convertValue(v)
+	e.depth--
+	if tracked {
+		delete(e.visiting, key)
+	}
+	return out, changed
+}
+
+// convertValue does the work of unorderValue for one value
+func (e *encoder) convertValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case *OrderedMap:
+		if val == nil {
+			return nil, true
+		}
+		m := make(map[string]interface{}, len(val.keys))
+		keys := val.keys
+		if e.omitNulls {
+			keys = make([]string, 0, len(val.keys))
+		}
+		for _, k := range val.keys {
+			out, _ := e.unorderValue(val.values[k])
+			if e.omitted(out) {
+				continue
+			}
+			m[k] = out
+			if e.omitNulls {
+				keys = append(keys, k)
+			}
+		}
+		if e.orders == nil {
+			e.orders = make(map[uintptr]recordedOrder)
+		}
+		e.orders[reflect.ValueOf(m).Pointer()] = recordedOrder{m: m, keys: keys}
+		return m, true
+	case map[string]interface{}:
+		var changed map[string]interface{}
+		for k, child := range val {
+			out, ok := e.unorderValue(child)
+			omit := e.omitted(out)
+			if !ok && !omit {
+				continue
+			}
+			if changed == nil {
+				changed = make(map[string]interface{}, len(val))
+				for k, child := range val {
+					changed[k] = child
+				}
+			}
+			if omit {
+				delete(changed, k)
+			} else {
+				changed[k] = out
+			}
+		}
+		if changed == nil {
+			return val, false
+		}
+		return changed, true
+	case []interface{}:
+		var list []interface{}
+		for i, item := range val {
+			if out, ok := e.unorderValue(item); ok {
+				if list == nil {
+					list = append([]interface{}(nil), val...)
+				}
+				list[i] = out
+			}
+		}
+		if list == nil {
+			return val, false
+		}
+		return list, true
+	}
+	if rows, ok := v.([]map[string]interface{}); ok && e.omitNulls {
+		// the rows need their null fields dropped like any other objects
+		list := make([]interface{}, len(rows))
+		for i, row := range rows {
+			list[i] = row
+		}
+		out, _ := e.unorderValue(list)
+		return out, true
+	}
+	switch v.(type) {
+	case *Value, RawMessage, map[string]RawMessage, map[string]json.RawMessage, []map[string]interface{}:
+		// written by the encoder directly
+		return v, false
+	}
+	if m, ok := v.(ToonMarshaler); ok {
+		return e.marshalTOON(m), true
+	}
+	if out, ok := e.extend(v); ok {
+		// the replacement is not extended again, so an extension that keeps
+		// the original value inside its result cannot recurse forever
+		registered := e.extensions
+		e.extensions = nil
+		out, _ = e.unorderValue(out)
+		e.extensions = registered
+		return out, true
+	}
+	if out, ok := bigNumber(v); ok {
+		return out, true
+	}
+	if out, ok := e.timeValue(v); ok {
+		return out, true
+	}
+	if out, ok := e.marshalSelf(v); ok {
+		out, _ = e.unorderValue(out)
+		return out, true
+	}
+	if out, ok := reflectValue(v); ok {
+		_, isStruct := out.(*OrderedMap)
+		out, _ = e.unorderValue(out)
+		if m, ok := out.(map[string]interface{}); ok && isStruct {
+			order := e.orders[reflect.ValueOf(m).Pointer()]
+			order.fromStruct = true
+			e.orders[reflect.ValueOf(m).Pointer()] = order
+		}
+		return out, true
+	}
+	return v, false
+}
+
+// recordedOrder is the key order of a map made by unorder. Holding the map
+// keeps it alive, so its address cannot be reused by another map while the
+// entry exists.
+type recordedOrder struct {
+	m    map[string]interface{}
+	keys []string
+
+	// fromStruct marks the field order of a struct, which the key ordering
+	// options still apply to
+	fromStruct bool
+}
+
+// recordedKeys returns the key order recorded for m by unorder
+func (e *encoder) recordedKeys(m map[string]interface{}) ([]string, bool) {
+	if e.orders == nil {
+		return nil, false
+	}
+	order, ok := e.orders[reflect.ValueOf(m).Pointer()]
+	return order.keys, ok
+}
+
+// fixedOrder reports whether m came from an OrderedMap, whose key order
+// the key ordering options leave alone
+func (e *encoder) fixedOrder(m map[string]interface{}) bool {
+	if e.orders == nil {
+		return false
+	}
+	order, ok := e.orders[reflect.ValueOf(m).Pointer()]
+	return ok && !order.fromStruct
+}
+
+// recordPart records keys as the order of part, a map holding some of the
+// entries of src, when the order of src was recorded
+func (e *encoder) recordPart(part, src map[string]interface{}, keys []string) {
+	if e.orders == nil {
+		return
+	}
+	order, ok := e.orders[reflect.ValueOf(src).Pointer()]
+	if !ok {
+		return
+	}
+	e.orders[reflect.ValueOf(part).Pointer()] = recordedOrder{m: part, keys: keys, fromStruct: order.fromStruct}
+}
+
+// rowKeys returns the keys of a table row, in recorded order when the row
+// came from an OrderedMap
+func (e *encoder) rowKeys(m map[string]interface{}) []string {
+	if keys, ok := e.recordedKeys(m); ok {
+		return keys
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// orderColumns applies the key ordering options to the columns of a table,
+// unless its rows came from OrderedMaps
+func (e *encoder) orderColumns(rows []interface{}, columns []string) {
+	declared := false
+	if row, ok := rows[0].(map[string]interface{}); ok {
+		if e.fixedOrder(row) {
+			return
+		}
+		_, declared = e.recordedKeys(row)
+	}
+	e.orderKeys(columns, declared)
+}
@@ -0,0 +1,78 @@
+package totoon
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap_EncodesInInsertionOrder(t *testing.T) {
+	m := NewOrderedMap().
+		Set("zeta", 1).
+		Set("alpha", NewOrderedMap().Set("y", true).Set("x", false)).
+		Set("mid", "m")
+	result := ToToonWithOptions(m, withSortedKeys())
+	expected := "zeta: 1\nalpha:\n  y: true\n  x: false\nmid: m"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestOrderedMap_TableColumns(t *testing.T) {
+	data := map[string]interface{}{
+		"rows": []interface{}{
+			NewOrderedMap().Set("id", 1).Set("name", "a"),
+			NewOrderedMap().Set("id", 2).Set("extra", "x").Set("name", "b"),
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys())
+	expected := "rows[2]{id,name,extra}:\n  1,a,\n  2,b,x"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestOrderedMap_SetDeleteKeys(t *testing.T) {
+	m := NewOrderedMap().Set("a", 1).Set("b", 2).Set("c", 3).Set("a", 4)
+	m.Delete("b")
+	m.Delete("missing")
+	if got := strings.Join(m.Keys(), ","); got != "a,c" {
+		t.Errorf("Expected keys a,c, got: %s", got)
+	}
+	if v, ok := m.Get("a"); !ok || v != 4 {
+		t.Errorf("Expected a=4, got: %v", v)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Expected length 2, got: %d", m.Len())
+	}
+}
+
+func TestOrderedMap_JSONRoundTrip(t *testing.T) {
+	src := `{"z":1,"a":{"k2":"v","k1":[1,{"q":null,"p":true}]}}`
+	var m OrderedMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(out) != src {
+		t.Errorf("Expected %s, got: %s", src, out)
+	}
+	if err := json.Unmarshal([]byte(`[1]`), &m); err == nil {
+		t.Errorf("Expected error decoding an array into OrderedMap")
+	}
+}
+
+func TestOrderedMap_Writer(t *testing.T) {
+	var buf strings.Builder
+	w := NewWriter(&buf)
+	w.BeginTable([]string{"meta"})
+	w.Row(NewOrderedMap().Set("b", 1).Set("a", 2))
+	w.EndTable()
+	w.Flush()
+	if buf.String() != "[1]{meta}:\n  {b:1,a:2}" {
+		t.Errorf("Expected ordered inline object, got: %q", buf.String())
+	}
+}
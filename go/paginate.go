@@ -0,0 +1,84 @@
+package totoon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Paginate splits rows into pages of at most rowsPerPage rows and converts
+// each page to a standalone TOON document stored under key. Every page
+// starts with a structured marker recording its position:
+//
+//	page:
+//	  part: 2/5
+//	  rows: 201-400
+//	  total: 1000
+//	users[200]{name,age}:
+//	  ...
+//
+// Rows are converted as ToToon converts values. Sorting and deduplication
+// options are applied to the full set of rows
+// before it is split, so pages line up with the order of a single document.
+// A rowsPerPage of zero or less puts every row on one page, and an empty
+// rows slice yields no pages. Paginate fails when key is "page", which
+// would collide with the marker.
+func Paginate(key string, rows []interface{}, rowsPerPage int, opts ...Option) ([]string, error) {
+	if key == pageMarkerKey {
+		return nil, fmt.Errorf("totoon: key %q collides with the page marker", key)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	e := newEncoder(opts)
+	rows = e.unorder(rows).([]interface{})
+	if len(e.sortRows) > 0 {
+		rows = e.sortedRows(rows)
+		e.sortRows = nil
+	}
+	if e.dedup {
+		rows = e.dedupRows(rows)
+		e.dedup = false
+	}
+
+	if rowsPerPage <= 0 || rowsPerPage > len(rows) {
+		rowsPerPage = len(rows)
+	}
+	parts := (len(rows) + rowsPerPage - 1) / rowsPerPage
+
+	pages := make([]string, 0, parts)
+	for part := 0; part < parts; part++ {
+		start := part * rowsPerPage
+		end := start + rowsPerPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var body string
+		if key != "" {
+			body = e.encode(map[string]interface{}{key: rows[start:end]})
+		} else {
+			body = e.encode(rows[start:end])
+		}
+
+		pages = append(pages, strings.Join([]string{
+			e.pageMarker(part+1, parts, start+1, end, len(rows)),
+			body,
+		}, "\n"))
+	}
+
+	return pages, nil
+}
+
+// pageMarkerKey is the key of the marker that starts every page
+const pageMarkerKey = "page"
+
+func (e *encoder) pageMarker(part, parts, first, last, total int) string {
+	prefix := strings.Repeat(" ", e.indent)
+	return strings.Join([]string{
+		pageMarkerKey + ":",
+		fmt.Sprintf("%spart: %d/%d", prefix, part, parts),
+		fmt.Sprintf("%srows: %d-%d", prefix, first, last),
+		fmt.Sprintf("%stotal: %d", prefix, total),
+	}, "\n")
+}
@@ -0,0 +1,70 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	var rows []interface{}
+	for i := 1; i <= 5; i++ {
+		rows = append(rows, map[string]interface{}{"id": i})
+	}
+	pages, err := Paginate("items", rows, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got: %d", len(pages))
+	}
+	expected := "page:\n  part: 2/3\n  rows: 3-4\n  total: 5\nitems[2]{id}:\n  3\n  4"
+	if pages[1] != expected {
+		t.Errorf("Expected %q, got: %q", expected, pages[1])
+	}
+	if !strings.Contains(pages[2], "rows: 5-5") {
+		t.Errorf("Expected last page to cover row 5, got: %s", pages[2])
+	}
+}
+
+func TestPaginate_SortsBeforeSplitting(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"id": 3},
+		map[string]interface{}{"id": 1},
+		map[string]interface{}{"id": 2},
+	}
+	pages, _ := Paginate("", rows, 2, WithSortRows("id"))
+	if !strings.HasSuffix(pages[0], "[2]{id}:\n  1\n  2") {
+		t.Errorf("Expected first page to hold ids 1 and 2, got: %s", pages[0])
+	}
+}
+
+func TestPaginate_Empty(t *testing.T) {
+	if pages, err := Paginate("items", nil, 10); pages != nil || err != nil {
+		t.Errorf("Expected no pages, got: %v, %v", pages, err)
+	}
+}
+
+func TestPaginate_ConvertsValues(t *testing.T) {
+	rows := []interface{}{
+		NewOrderedMap().Set("name", "Bob").Set("id", 2),
+		NewOrderedMap().Set("name", "Alice").Set("id", 1),
+	}
+	pages, _ := Paginate("users", rows, 1, WithSortRows("id"))
+	if !strings.HasSuffix(pages[0], "users[1]{name,id}:\n  Alice,1") {
+		t.Errorf("Expected the recorded key order and sorted rows, got: %s", pages[0])
+	}
+}
+
+func TestPaginate_MarkerKey(t *testing.T) {
+	rows := []interface{}{map[string]interface{}{"id": 1}}
+	if _, err := Paginate("page", rows, 1); err == nil {
+		t.Errorf("Expected an error for a key that collides with the marker")
+	}
+	pages, err := Paginate("pages", rows, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := FromToon(pages[0]); err != nil {
+		t.Errorf("Expected the page to decode, got: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package totoon
+
+// QuoteStyle selects the quote character used for quoted values
+type QuoteStyle int
+
+const (
+	// DoubleQuotes quotes values as "a, b" (the default)
+	DoubleQuotes QuoteStyle = iota
+	// SingleQuotes quotes values as 'a, b'
+	SingleQuotes
+)
+
+// WithQuoteStyle selects the quote character, for environments where double
+// quotes get mangled by templating layers. Backslashes and the chosen
+// character are escaped with a backslash inside quoted values; the other
+// one is left as is.
+func WithQuoteStyle(style QuoteStyle) Option {
+	return func(o *options) {
+		o.quoteStyle = style
+	}
+}
+
+// quoteChar returns the quote character of the selected style
+func (e *encoder) quoteChar() rune {
+	if e.quoteStyle == SingleQuotes && !e.strict() {
+		return '\''
+	}
+	return '"'
+}
+
+// quote wraps s in quotes, escaping backslashes, the quote character and
+// control characters inside it, so that it reads back unchanged
+func (e *encoder) quote(s string) string {
+	e.unsafeValue(s)
+	e.trace("value %q quoted", s)
+	return escapeQuoted(s, byte(e.quoteChar()))
+}
+
+// stringCell renders the string s in a table cell. It is quoted when it
+// would read as a missing cell or a bool, needs escaping or quoting on its
+// own, or contains a byte for which special reports true.
+func (e *encoder) stringCell(s string, special func(string) bool) string {
+	if e.ambiguousCell(s) || e.readsAsBool(s) {
+		return e.quote(s)
+	}
+	if escaped := e.escapeString(s); escaped != s {
+		return escaped
+	}
+	if special(s) {
+		return e.quote(s)
+	}
+	return s
+}
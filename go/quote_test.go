@@ -0,0 +1,65 @@
+package totoon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithQuoteStyle_SingleQuotes(t *testing.T) {
+	data := map[string]interface{}{
+		"note": "line1\nit's",
+		"rows": []interface{}{
+			map[string]interface{}{"text": `say "hi", 'bye'`},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithQuoteStyle(SingleQuotes))
+	expected := `note: 'line1\nit\'s'
+rows[1]{text}:
+  'say "hi", \'bye\''`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestWithQuoteStyle_DoubleQuotesDefault(t *testing.T) {
+	data := []interface{}{map[string]interface{}{"text": `say "hi", 'bye'`}}
+	expected := `[1]{text}:
+  "say \"hi\", 'bye'"`
+	if result := ToToonWithOptions(data, WithQuoteStyle(DoubleQuotes)); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithQuoteStyle_NestedRows(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"v": "a;b"},
+		}},
+	}
+	expected := "[1]{items}:\n  [1]{v}:'a;b'"
+	if result := ToToonWithOptions(data, WithQuoteStyle(SingleQuotes)); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestQuote_BackslashesRoundTrip(t *testing.T) {
+	values := []interface{}{`x,\`, `a\"b,c`, `\`, `it\'s, ok`, `"a,b"`, `'q'`, `c:\dir;x`}
+	for _, style := range []QuoteStyle{DoubleQuotes, SingleQuotes} {
+		rows := make([]interface{}, len(values))
+		for i, v := range values {
+			rows[i] = map[string]interface{}{
+				"v":      v,
+				"nested": []interface{}{map[string]interface{}{"w": v}},
+			}
+		}
+		data := map[string]interface{}{"rows": rows}
+		doc := ToToonWithOptions(data, WithQuoteStyle(style))
+		decoded, err := FromToon(doc)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %q: %v", doc, err)
+		}
+		if !reflect.DeepEqual(decoded, data) {
+			t.Errorf("Expected %v, got: %v (from %q)", data, decoded, doc)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RawMessage is a pre-encoded TOON fragment. It is inserted into the output
+// verbatim, re-indented to the position where it appears, so documents can be
+// composed from cached per-section encodes without re-parsing them. The
+// fragment must be the output of ToToon (or an equivalent encoder) for a value
+// at the top level.
+type RawMessage []byte
+
+// rawEntryToToon writes a "key: fragment" entry of an object
+func (e *encoder) rawEntryToToon(key string, raw RawMessage, level int) string {
+	prefix := strings.Repeat(" ", e.indent*level)
+	fragment := strings.TrimRight(string(raw), "\n")
+
+	if !strings.Contains(fragment, "\n") && !isObjectLine(fragment) {
+		return fmt.Sprintf("%s%s: %s", prefix, key, fragment)
+	}
+
+	// A top-level table keeps its rows and takes the key in its header
+	if strings.HasPrefix(fragment, "[") {
+		return prefix + key + fragment
+	}
+
+	return fmt.Sprintf("%s%s:\n%s", prefix, key, e.reindent(fragment, level+1))
+}
+
+// rawValueToToon renders a fragment in value position, matching valueToToon
+func (e *encoder) rawValueToToon(raw RawMessage, level int) string {
+	fragment := strings.TrimRight(string(raw), "\n")
+	if !strings.Contains(fragment, "\n") && !isObjectLine(fragment) {
+		return fragment
+	}
+	return "\n" + e.reindent(fragment, level)
+}
+
+// isObjectLine reports whether a single-line fragment is a one-key object
+// ("key: value") rather than a scalar
+func isObjectLine(fragment string) bool {
+	idx := strings.Index(fragment, ": ")
+	if idx <= 0 {
+		return false
+	}
+	key := fragment[:idx]
+	return !strings.ContainsAny(key, " \"") && !strings.HasPrefix(key, "[") && !strings.HasPrefix(key, "-")
+}
+
+// reindent shifts every non-empty line of fragment to the given level
+func (e *encoder) reindent(fragment string, level int) string {
+	prefix := strings.Repeat(" ", e.indent*level)
+	lines := strings.Split(fragment, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rawMapToDict turns a map of TOON or JSON fragments into a regular object.
+// TOON fragments are kept as RawMessage values; each JSON fragment is decoded
+// on its own with its numbers kept as json.Number. A fragment that is not
+// valid JSON is an unsupported value: it falls back to its literal text with
+// a warning, and makes Encode, EncodeTo and Marshal fail.
+func (e *encoder) rawMapToDict(m interface{}) map[string]interface{} {
+	dict := make(map[string]interface{})
+	switch v := m.(type) {
+	case map[string]RawMessage:
+		for k, raw := range v {
+			dict[k] = raw
+		}
+	case map[string]json.RawMessage:
+		parentPath := e.path
+		defer func() { e.path = parentPath }()
+		for k, raw := range v {
+			decoded, err := decodeJSON(bytes.NewReader(raw))
+			if err != nil {
+				e.path = joinPath(parentPath, k)
+				e.warn("invalid JSON fragment rendered as a string: %v", err)
+				if e.err == nil {
+					e.err = &UnsupportedValueError{Path: e.path, Value: fmt.Sprintf("JSON fragment %q", raw)}
+				}
+				dict[k] = string(raw)
+				continue
+			}
+			dict[k] = decoded
+		}
+	}
+	return dict
+}
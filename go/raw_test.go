@@ -0,0 +1,78 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRawMessage_ComposeSections(t *testing.T) {
+	users := ToToon([]interface{}{
+		map[string]interface{}{"name": "Alice"},
+		map[string]interface{}{"name": "Bob"},
+	})
+	meta := ToToon(map[string]interface{}{"count": 2})
+	doc := map[string]RawMessage{
+		"users": RawMessage(users),
+		"meta":  RawMessage(meta),
+		"title": RawMessage("Report"),
+	}
+	result := ToToonWithOptions(doc, withSortedKeys())
+	expected := "meta:\n  count: 2\ntitle: Report\nusers[2]{name}:\n  Alice\n  Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestRawMessage_MatchesDirectEncoding(t *testing.T) {
+	section := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}}
+	direct := ToToonWithOptions(map[string]interface{}{"root": section}, withSortedKeys())
+	raw := ToToonWithOptions(map[string]interface{}{
+		"root": RawMessage(ToToonWithOptions(section, withSortedKeys())),
+	}, withSortedKeys())
+	if direct != raw {
+		t.Errorf("Expected %q, got: %q", direct, raw)
+	}
+}
+
+func TestRawMessage_JSONFragments(t *testing.T) {
+	doc := map[string]json.RawMessage{
+		"name":  json.RawMessage(`"Alice"`),
+		"roles": json.RawMessage(`["admin","dev"]`),
+	}
+	result := ToToon(doc)
+	if !strings.Contains(result, "name: Alice") {
+		t.Errorf("Expected 'name: Alice' in result, got: %s", result)
+	}
+	if !strings.Contains(result, "roles:\n  - admin\n  - dev") {
+		t.Errorf("Expected roles list in result, got: %s", result)
+	}
+}
+
+func TestRawMessage_JSONFragmentNumbers(t *testing.T) {
+	doc := map[string]json.RawMessage{"id": json.RawMessage(`9007199254740993`), "ids": json.RawMessage(`[18446744073709551615]`)}
+	result := ToToonWithOptions(doc, withSortedKeys())
+	if expected := "id: 9007199254740993\nids:\n  - 18446744073709551615"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestRawMessage_InvalidJSONFragment(t *testing.T) {
+	doc := map[string]interface{}{
+		"meta": map[string]json.RawMessage{"bad": json.RawMessage(`{oops`), "ok": json.RawMessage(`1`)},
+	}
+	result, warnings := ToToonWithWarnings(doc)
+	if !strings.Contains(result, "bad: {oops") {
+		t.Errorf("Expected the fragment text in result, got: %s", result)
+	}
+	if len(warnings) != 1 || warnings[0].Path != "meta.bad" {
+		t.Errorf("Expected one warning at meta.bad, got: %v", warnings)
+	}
+
+	_, err := Encode(doc)
+	var unsupported *UnsupportedValueError
+	if !errors.As(err, &unsupported) || unsupported.Path != "meta.bad" {
+		t.Errorf("Expected an *UnsupportedValueError at meta.bad, got: %v", err)
+	}
+}
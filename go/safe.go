@@ -0,0 +1,65 @@
+package totoon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithAssertSafe makes Encode fail instead of quoting or escaping: every
+// value that would have needed quotes or escape sequences is reported in an
+// UnsafeValuesError, for pipelines that must guarantee delimiter-free output.
+// The other conversion functions ignore it.
+func WithAssertSafe() Option {
+	return func(o *options) {
+		o.assertSafe = true
+	}
+}
+
+// UnsafeValuesError lists the values that needed quoting or escaping while
+// encoding with WithAssertSafe
+type UnsafeValuesError struct {
+	Values []Warning
+}
+
+func (err *UnsafeValuesError) Error() string {
+	parts := make([]string, len(err.Values))
+	for i, v := range err.Values {
+		parts[i] = v.String()
+	}
+	if len(parts) == 1 {
+		return "totoon: 1 value needs quoting: " + parts[0]
+	}
+	return fmt.Sprintf("totoon: %d values need quoting: %s", len(parts), strings.Join(parts, "; "))
+}
+
+// Encode converts a Go value to TOON like ToToonWithOptions, returning an
+// error when a marshaling method fails (a *MarshalerError) or an option's
+// requirement is not met (an *UnsafeValuesError under WithAssertSafe, an
+// *UnsupportedValueError under NonFiniteError).
+func Encode(data ToonValue, opts ...Option) (string, error) {
+	e := newEncoder(opts)
+	var unsafe []Warning
+	if e.assertSafe {
+		e.unsafe = &unsafe
+	}
+	out := e.encode(data)
+	if e.err != nil {
+		return "", e.err
+	}
+	if len(unsafe) > 0 {
+		return "", &UnsafeValuesError{Values: unsafe}
+	}
+	return out, nil
+}
+
+// unsafeValue records that the value at the current path needed quoting or
+// escaping, once per path
+func (e *encoder) unsafeValue(s string) {
+	if e.unsafe == nil {
+		return
+	}
+	if n := len(*e.unsafe); n > 0 && (*e.unsafe)[n-1].Path == e.path {
+		return
+	}
+	*e.unsafe = append(*e.unsafe, Warning{Path: e.path, Message: fmt.Sprintf("value %q needs quoting", s)})
+}
@@ -0,0 +1,49 @@
+package totoon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncode_AssertSafe(t *testing.T) {
+	data := map[string]interface{}{
+		"title": "line1\nline2",
+		"users": []interface{}{
+			map[string]interface{}{"name": "Smith, J"},
+			map[string]interface{}{"name": "Alice"},
+		},
+	}
+	_, err := Encode(data, withSortedKeys(), WithAssertSafe())
+	var unsafe *UnsafeValuesError
+	if !errors.As(err, &unsafe) {
+		t.Fatalf("Expected UnsafeValuesError, got: %v", err)
+	}
+	if len(unsafe.Values) != 2 || unsafe.Values[0].Path != "title" || unsafe.Values[1].Path != "users[0].name" {
+		t.Errorf("Expected title and users[0].name, got: %v", unsafe.Values)
+	}
+	if !strings.Contains(err.Error(), "2 values need quoting") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestEncode_SafeOutput(t *testing.T) {
+	data := map[string]interface{}{"name": "Alice"}
+	result, err := Encode(data, WithAssertSafe())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "name: Alice" {
+		t.Errorf("Expected %q, got: %q", "name: Alice", result)
+	}
+}
+
+func TestEncode_WithoutAssertSafeQuotes(t *testing.T) {
+	result, err := Encode([]interface{}{map[string]interface{}{"a": "x,y"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "[1]{a}:\n  \"x,y\"" {
+		t.Errorf("Expected quoted cell, got: %q", result)
+	}
+}
@@ -0,0 +1,134 @@
+package totoon
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Scanner splits a TOON document into its top-level sections without
+// parsing them. A section starts at each line that is not indented and runs
+// until the next such line, so a top-level key and everything nested under
+// it (including table rows) form one section. Consumers can route sections
+// by key or decode only the ones they need.
+//
+// Usage mirrors bufio.Scanner:
+//
+//	s := totoon.NewScanner(r)
+//	for s.Scan() {
+//		fmt.Println(s.Key(), len(s.Bytes()))
+//	}
+//	if err := s.Err(); err != nil { ... }
+type Scanner struct {
+	r       *bufio.Reader
+	next    string // first line of the following section, already read
+	hasNext bool
+	key     string
+	section []byte
+	err     error
+	eof     bool
+}
+
+// NewScanner returns a Scanner reading from r. Lines of any length are
+// supported.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: bufio.NewReader(r)}
+}
+
+// Scan advances to the next section, returning false at the end of the
+// input or on a read error
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	first := true
+	for {
+		line, ok := s.readLine()
+		if !ok {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			if !first {
+				buf.WriteString("\n")
+			}
+			continue
+		}
+		topLevel := line[0] != ' ' && line[0] != '\t'
+		if topLevel && !first {
+			s.next, s.hasNext = line, true
+			break
+		}
+		if first {
+			// Indented content before any section belongs to an unnamed one
+			s.key = ""
+			if topLevel {
+				s.key = sectionKey(line)
+			}
+			first = false
+		} else {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(line)
+	}
+
+	if first {
+		return false
+	}
+	s.section = bytes.TrimRight(buf.Bytes(), "\n")
+	return true
+}
+
+// Key returns the top-level key of the current section. Sections that do not
+// start with a key (list items or a table at the root) have an empty key.
+func (s *Scanner) Key() string {
+	return s.key
+}
+
+// Bytes returns the raw text of the current section without a trailing
+// newline. The slice is only valid until the next call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.section
+}
+
+// Err returns the first non-EOF error encountered while reading
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func (s *Scanner) readLine() (string, bool) {
+	if s.hasNext {
+		s.hasNext = false
+		return s.next, true
+	}
+	if s.eof {
+		return "", false
+	}
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		s.eof = true
+		if err != io.EOF {
+			s.err = err
+			return "", false
+		}
+		if line == "" {
+			return "", false
+		}
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+// sectionKey extracts the key from a top-level line such as "name: Alice",
+// "meta:" or "users[2]{name,age}:"
+func sectionKey(line string) string {
+	if strings.HasPrefix(line, "- ") || line == "-" || strings.HasPrefix(line, "[") {
+		return ""
+	}
+	end := strings.IndexAny(line, ":[{")
+	if end <= 0 {
+		return ""
+	}
+	return line[:end]
+}
@@ -0,0 +1,68 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanner_Sections(t *testing.T) {
+	doc := "title: Report\nmeta:\n  count: 2\n  source: db\nusers[2]{name,age}:\n  Alice,30\n  Bob,25"
+	s := NewScanner(strings.NewReader(doc))
+
+	var keys []string
+	var sections []string
+	for s.Scan() {
+		keys = append(keys, s.Key())
+		sections = append(sections, string(s.Bytes()))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(keys, ",") != "title,meta,users" {
+		t.Errorf("Expected keys title,meta,users, got: %v", keys)
+	}
+	if sections[1] != "meta:\n  count: 2\n  source: db" {
+		t.Errorf("Unexpected meta section: %q", sections[1])
+	}
+	if sections[2] != "users[2]{name,age}:\n  Alice,30\n  Bob,25" {
+		t.Errorf("Unexpected users section: %q", sections[2])
+	}
+}
+
+func TestScanner_RoundTripsEncoderOutput(t *testing.T) {
+	data := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1},
+		"c": []interface{}{1, 2},
+	}
+	doc := ToToonWithOptions(data, withSortedKeys())
+	s := NewScanner(strings.NewReader(doc + "\n"))
+	var parts []string
+	for s.Scan() {
+		parts = append(parts, string(s.Bytes()))
+	}
+	if strings.Join(parts, "\n") != doc {
+		t.Errorf("Expected sections to rebuild the document, got: %v", parts)
+	}
+}
+
+func TestScanner_Empty(t *testing.T) {
+	s := NewScanner(strings.NewReader("\n\n"))
+	if s.Scan() {
+		t.Errorf("Expected no sections")
+	}
+}
+
+func TestSectionKey(t *testing.T) {
+	cases := map[string]string{
+		"name: Alice":         "name",
+		"meta:":               "meta",
+		"users[2]{name,age}:": "users",
+		"- item":              "",
+		"[2]{a}:":             "",
+	}
+	for line, expected := range cases {
+		if got := sectionKey(line); got != expected {
+			t.Errorf("sectionKey(%q): expected %q, got: %q", line, expected, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package totoon
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// sortKey is a single parsed WithSortRows key
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+func parseSortKeys(keys []string) []sortKey {
+	var parsed []sortKey
+	for _, key := range keys {
+		parts := strings.Fields(key)
+		if len(parts) == 0 {
+			continue
+		}
+		sk := sortKey{field: parts[0]}
+		if len(parts) > 1 && strings.EqualFold(parts[1], "desc") {
+			sk.desc = true
+		}
+		parsed = append(parsed, sk)
+	}
+	return parsed
+}
+
+// sortedRows returns a copy of data ordered by the configured sort keys.
+// The input slice is left untouched.
+func (e *encoder) sortedRows(data []interface{}) []interface{} {
+	sorted := make([]interface{}, len(data))
+	copy(sorted, data)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := sorted[i].(map[string]interface{})
+		b, _ := sorted[j].(map[string]interface{})
+		for _, sk := range e.sortRows {
+			c := compareValues(a[sk.field], b[sk.field])
+			if c == 0 {
+				continue
+			}
+			if sk.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	return sorted
+}
+
+// compareValues orders two cell values. Values of different kinds are ordered
+// null < bool < number < string < anything else; within a kind numbers compare
+// numerically, strings lexically and everything else by their JSON form.
+func compareValues(a, b interface{}) int {
+	ra, rb := valueRank(a), valueRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch ra {
+	case 0:
+		return 0
+	case 1:
+		ba, bb := a.(bool), b.(bool)
+		if ba == bb {
+			return 0
+		}
+		if !ba {
+			return -1
+		}
+		return 1
+	case 2:
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	if ra == 3 {
+		return strings.Compare(a.(string), b.(string))
+	}
+	return strings.Compare(rowIdentity(a), rowIdentity(b))
+}
+
+func valueRank(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+	if _, ok := v.(bool); ok {
+		return 1
+	}
+	if _, ok := toFloat(v); ok {
+		return 2
+	}
+	if _, ok := v.(string); ok {
+		return 3
+	}
+	return 4
+}
+
+// toFloat converts any Go numeric value to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithSortRows_MultipleKeys(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"name": "Bob", "age": 25},
+		map[string]interface{}{"name": "Carol", "age": 30},
+		map[string]interface{}{"name": "Alice", "age": 30},
+	}
+	result := ToToonWithOptions(data, WithSortRows("age desc", "name asc"))
+	alice := strings.Index(result, "Alice")
+	carol := strings.Index(result, "Carol")
+	bob := strings.Index(result, "Bob")
+	if !(alice < carol && carol < bob) {
+		t.Errorf("Expected rows ordered Alice, Carol, Bob, got: %s", result)
+	}
+}
+
+func TestWithSortRows_DoesNotMutateInput(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": 2},
+		map[string]interface{}{"id": 1},
+	}
+	result := ToToonWithOptions(data, WithSortRows("id"))
+	if result != "[2]{id}:\n  1\n  2" {
+		t.Errorf("Expected rows sorted by id, got: %q", result)
+	}
+	if data[0].(map[string]interface{})["id"] != 2 {
+		t.Errorf("Expected input slice to be left untouched")
+	}
+}
+
+func TestWithSortRows_MixedKinds(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"v": "text"},
+		map[string]interface{}{"v": 3},
+		map[string]interface{}{"v": nil},
+	}
+	result := ToToonWithOptions(data, WithSortRows("v"))
+	if result != "[3]{v}:\n  null\n  3\n  text" {
+		t.Errorf("Expected null, number, string order, got: %q", result)
+	}
+}
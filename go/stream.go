@@ -0,0 +1,92 @@
+package totoon
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// lineSink receives rendered output one line (or one multi-line block) at a
+// time, in document order
+type lineSink func(line string)
+
+// lineBuffer collects lines for the string-returning conversions
+type lineBuffer struct {
+	lines []string
+}
+
+func (b *lineBuffer) add(line string) {
+	b.lines = append(b.lines, line)
+}
+
+func (b *lineBuffer) String() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// EncodeTo writes data to w in TOON format, producing the same output as
+// ToToonWithOptions without building it in memory: objects, lists and table
+// rows are written as they are rendered, so memory use stays proportional
+// to the input plus a single row. Output is buffered (see WithBufferSize)
+// and flushed before EncodeTo returns.
+//
+// Under WithAssertSafe the document is still written in full and an
+// *UnsafeValuesError is returned afterwards, since the offending values are
+// only known once they have been encoded. A failing MarshalTOON method
+// likewise returns its *MarshalerError after the document, with null
+// written in place of the value.
+func EncodeTo(w io.Writer, data ToonValue, opts ...Option) error {
+	e := newEncoder(opts)
+	var unsafe []Warning
+	if e.assertSafe {
+		e.unsafe = &unsafe
+	}
+
+	counter := &byteCounter{w: w}
+	out := &Writer{e: e, w: e.newBufferedWriter(counter)}
+	e.writeValue(e.unorder(data), 0, out.writeRaw)
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	e.encoded(counter.n)
+	if e.err != nil {
+		return e.err
+	}
+	if len(unsafe) > 0 {
+		return &UnsafeValuesError{Values: unsafe}
+	}
+	return nil
+}
+
+// writeValue is the streaming counterpart of toToon: containers are walked
+// line by line and everything else is emitted as toToon renders it
+func (e *encoder) writeValue(data ToonValue, level int, emit lineSink) {
+	switch v := data.(type) {
+	case *Value:
+		e.writeValue(v.Interface(), level, emit)
+		return
+	case map[string]RawMessage, map[string]json.RawMessage:
+		e.writeDict(e.rawMapToDict(v), level, emit)
+		return
+	case map[string]interface{}:
+		e.writeDict(v, level, emit)
+		return
+	case []interface{}:
+		e.writeRootList(v, level, emit)
+		return
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		e.writeRootList(list, level, emit)
+		return
+	case nil, bool, string, RawMessage,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+	default:
+		if converted, ok := convertJSON(data); ok {
+			e.writeValue(converted, level, emit)
+			return
+		}
+	}
+	emit(e.toToon(data, level))
+}
@@ -0,0 +1,108 @@
+package totoon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEncodeTo_MatchesToToon(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	inputs := []interface{}{
+		nil,
+		"hello, world",
+		map[string]interface{}{},
+		[]interface{}{},
+		map[string]interface{}{
+			"meta":  map[string]interface{}{"count": 2, "tags": []interface{}{"a", "b"}},
+			"empty": map[string]interface{}{},
+			"users": []interface{}{
+				map[string]interface{}{"id": 1, "name": "Alice"},
+				map[string]interface{}{"id": 2, "name": "Bob, Jr.", "note": "x"},
+			},
+			"raw": RawMessage("[1, 2]"),
+		},
+		[]map[string]interface{}{{"id": 1}, {"id": 2}},
+		[]user{{1, "Alice"}, {2, "Bob"}},
+		NewOrderedMap().Set("z", 1).Set("a", []interface{}{1, []interface{}{2, 3}}),
+	}
+	optionSets := [][]Option{
+		nil,
+		{WithIndent(4)},
+		{WithOneValuePerLine()},
+		{WithGroupBy("name"), WithSortRows("id")},
+		{WithSubTables(), WithLengthMarkers(false)},
+	}
+	for i, input := range inputs {
+		for j, opts := range optionSets {
+			opts = append([]Option{withSortedKeys()}, opts...)
+			var buf bytes.Buffer
+			if err := EncodeTo(&buf, input, opts...); err != nil {
+				t.Fatalf("input %d, options %d: unexpected error: %v", i, j, err)
+			}
+			expected := ToToonWithOptions(input, opts...)
+			if buf.String() != expected {
+				t.Errorf("input %d, options %d: Expected %q, got: %q", i, j, expected, buf.String())
+			}
+		}
+	}
+}
+
+type countingWriter struct {
+	writes int
+	bytes  int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.bytes += len(p)
+	return len(p), nil
+}
+
+func TestEncodeTo_StreamsRows(t *testing.T) {
+	rows := make([]interface{}, 1000)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("user%d", i)}
+	}
+	data := map[string]interface{}{"users": rows}
+
+	w := &countingWriter{}
+	if err := EncodeTo(w, data, WithBufferSize(256)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := len(ToToon(data)); w.bytes != expected {
+		t.Errorf("Expected %d bytes, got: %d", expected, w.bytes)
+	}
+	if w.writes < w.bytes/256 {
+		t.Errorf("Expected output to be written as it is rendered, got %d writes for %d bytes", w.writes, w.bytes)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestEncodeTo_WriteError(t *testing.T) {
+	err := EncodeTo(failingWriter{}, map[string]interface{}{"a": 1})
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the write error, got: %v", err)
+	}
+}
+
+func TestEncodeTo_AssertSafe(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeTo(&buf, map[string]interface{}{"a": "line1\nline2"}, WithAssertSafe())
+	var unsafe *UnsafeValuesError
+	if !errors.As(err, &unsafe) || len(unsafe.Values) != 1 || unsafe.Values[0].Path != "a" {
+		t.Fatalf("Expected an UnsafeValuesError for a, got: %v", err)
+	}
+	if expected := `a: "line1\nline2"`; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
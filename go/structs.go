@@ -0,0 +1,409 @@
+package totoon
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// structField is an exported field of a struct type as it is encoded
+type structField struct {
+	name      string
+	index     []int // path through embedded structs, for reflect.Value.FieldByIndex
+	tagged    bool  // named by its tag
+	omitEmpty bool
+	quoted    bool // a scalar written as a string, as with `json:",string"`
+}
+
+// structFieldCache holds the fields of each struct type, by reflect.Type
+var structFieldCache sync.Map
+
+// reflectValue converts a struct, a pointer, a named scalar type or a typed
+// slice, array or map one level down into the generic form: structs become
+// OrderedMaps in field order, slices and arrays []interface{}, maps
+// map[string]interface{} with integer keys written in base 10, and named
+// scalars their underlying bool, number or string. The children are left
+// as they are for unorder to convert in turn, so extensions see them. Types
+// that marshal themselves to JSON or text, byte slices, which encoding/json
+// writes as base64, and maps with other keys are not converted and go
+// through encoding/json as before.
+//
+// Struct fields honor a toon tag, falling back to the json tag:
+// `toon:"name"` renames the field, `toon:",omitempty"` leaves it out when
+// empty, `toon:",string"` writes a bool, number or string field as its
+// JSON text in a string, `toon:"-"` skips it (`toon:"-,"` names it "-") and
+// `toon:",inline"` writes the fields of a struct field as fields of its
+// parent, as untagged embedded structs are. Fields of the same name follow
+// encoding/json: the least nested one wins, or the tagged one among those
+// equally nested, and are all dropped when that leaves a tie.
+func reflectValue(v interface{}) (interface{}, bool) {
+	if v == nil || isPrimitive(v) {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if marshalsItself(rv.Type()) {
+		return nil, false
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true
+	case reflect.Float32:
+		return float32(rv.Float()), true
+	case reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, true
+		}
+		return rv.Elem().Interface(), true
+	case reflect.Struct:
+		fields, ok := cachedFields(rv.Type())
+		if !ok {
+			return nil, false
+		}
+		return structToOrdered(rv, fields), true
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, true
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = rv.Index(i).Interface()
+		}
+		return list, true
+	case reflect.Map:
+		keyType := rv.Type().Key()
+		if marshalsItself(keyType) {
+			return nil, false
+		}
+		formatKey := mapKeyFormatter(keyType.Kind())
+		if formatKey == nil {
+			return nil, false
+		}
+		if rv.IsNil() {
+			return nil, true
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[formatKey(iter.Key())] = iter.Value().Interface()
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// startDetectingCyclesAfter is the nesting from which maps and slices are
+// checked for cycles, as in encoding/json; pointers are checked at any depth
+const startDetectingCyclesAfter = 1000
+
+// visitKey identifies a reference on the way to the value being converted.
+// Slices sharing an array are told apart by their length.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// reference returns the key of v when it is a non-nil pointer, or a map or
+// slice deep enough to be checked, that the conversion follows
+func (e *encoder) reference(v interface{}) (visitKey, bool) {
+	switch v.(type) {
+	case nil, string, bool, int, int64, float64, json.Number:
+		return visitKey{}, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if e.depth <= startDetectingCyclesAfter {
+			return visitKey{}, false
+		}
+	case reflect.Ptr:
+	default:
+		return visitKey{}, false
+	}
+	if rv.IsNil() {
+		return visitKey{}, false
+	}
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+	if rv.Kind() == reflect.Slice {
+		key.len = rv.Len()
+	}
+	return key, true
+}
+
+// cycle records a reference met again inside itself for the functions that
+// return an error, and writes null in its place
+func (e *encoder) cycle(key visitKey) interface{} {
+	e.warn("cycle via %s rendered as null", key.typ)
+	if e.err == nil {
+		e.err = &UnsupportedValueError{Value: key.typ.String(), cycle: true}
+	}
+	return nil
+}
+
+// mapKeyFormatter returns how map keys of the given kind are written, as
+// encoding/json writes them, or nil for kinds that cannot be object keys
+func mapKeyFormatter(kind reflect.Kind) func(reflect.Value) string {
+	switch kind {
+	case reflect.String:
+		return reflect.Value.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(k reflect.Value) string { return strconv.FormatInt(k.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(k reflect.Value) string { return strconv.FormatUint(k.Uint(), 10) }
+	}
+	return nil
+}
+
+// marshalsItself reports whether t or *t implements json.Marshaler or
+// encoding.TextMarshaler
+func marshalsItself(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if t.Kind() != reflect.Ptr {
+		pt := reflect.PtrTo(t)
+		return pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+	}
+	return false
+}
+
+func structToOrdered(rv reflect.Value, fields []structField) *OrderedMap {
+	m := NewOrderedMap()
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		m.Set(f.name, quotedValue(f, fv))
+	}
+	return m
+}
+
+// quotedValue returns the value of field f, written as its JSON text in a
+// string when f is quoted, as encoding/json writes `json:",string"` fields
+func quotedValue(f structField, fv reflect.Value) interface{} {
+	if !f.quoted || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
+		return fv.Interface()
+	}
+	text, err := json.Marshal(fv.Interface())
+	if err != nil {
+		return fv.Interface()
+	}
+	return string(text)
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, reporting false instead of
+// panicking when an embedded struct pointer on the way is nil
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// cachedFields returns the encoded fields of t, reporting false for
+// structs that embed an unexported struct type: reflection cannot read
+// the fields promoted from it, so such structs go through encoding/json
+func cachedFields(t reflect.Type) ([]structField, bool) {
+	cached, ok := structFieldCache.Load(t)
+	if !ok {
+		fields, readable := typeFields(t, nil, map[reflect.Type]bool{})
+		if readable {
+			fields = dominantFields(fields)
+		} else {
+			fields = nil
+		}
+		cached, _ = structFieldCache.LoadOrStore(t, fields)
+	}
+	fields := cached.([]structField)
+	return fields, fields != nil
+}
+
+// typeFields lists the fields of t and of the structs it inlines, at any
+// depth. Fields of inlined structs come at the position of the struct, and
+// dominantFields picks among those of the same name.
+func typeFields(t reflect.Type, index []int, visiting map[reflect.Type]bool) ([]structField, bool) {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, opts, skip := fieldTag(sf)
+		if skip {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		inline := ft.Kind() == reflect.Struct && !marshalsItself(ft) &&
+			(hasOption(opts, "inline") || (sf.Anonymous && name == ""))
+		if inline {
+			if !sf.IsExported() {
+				return nil, false
+			}
+			if visiting[ft] {
+				continue
+			}
+			inner, readable := typeFields(ft, fieldIndex, visiting)
+			if !readable {
+				return nil, false
+			}
+			fields = append(fields, inner...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		f := structField{
+			name:      name,
+			index:     fieldIndex,
+			tagged:    name != "",
+			omitEmpty: hasOption(opts, "omitempty"),
+			quoted:    hasOption(opts, "string") && quotable(sf.Type),
+		}
+		if name == "" {
+			f.name = sf.Name
+		}
+		fields = append(fields, f)
+	}
+	return fields, true
+}
+
+// dominantFields keeps, for each name, the field encoding/json would use:
+// the least nested one, or the only tagged one among the least nested.
+// Names left with a tie are dropped, and the order of fields is kept.
+func dominantFields(fields []structField) []structField {
+	byName := make(map[string][]structField, len(fields))
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	kept := fields[:0]
+	for _, f := range fields {
+		if dominant, ok := dominantField(byName[f.name]); ok && slices.Equal(dominant.index, f.index) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// dominantField picks the field among those of one name, reporting false
+// on a tie
+func dominantField(fields []structField) (structField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		depth = min(depth, len(f.index))
+	}
+	var dominant []structField
+	var tagged []structField
+	for _, f := range fields {
+		if len(f.index) != depth {
+			continue
+		}
+		dominant = append(dominant, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	switch {
+	case len(dominant) == 1:
+		return dominant[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return structField{}, false
+}
+
+// quotable reports whether the string option applies to a field of type t:
+// bools, numbers and strings, or unnamed pointers to them, that do not
+// marshal themselves
+func quotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if marshalsItself(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldTag returns the name and options of the toon tag of a field, or of
+// its json tag when it has none. Only a tag of exactly "-" skips the field;
+// "-," names it "-".
+func fieldTag(sf reflect.StructField) (name, opts string, skip bool) {
+	tag, ok := sf.Tag.Lookup("toon")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", "", true
+	}
+	name, opts, _ = strings.Cut(tag, ",")
+	return name, opts, false
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty in the sense of omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
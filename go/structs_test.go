@@ -0,0 +1,289 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type TagBase struct {
+	ID      int64  `toon:"id"`
+	Created string `toon:"created,omitempty"`
+}
+
+type tagAddress struct {
+	City string `toon:"city"`
+	Zip  string `toon:"zip"`
+}
+
+type tagUser struct {
+	TagBase
+	Name     string     `toon:"name"`
+	Email    string     `toon:"email,omitempty"`
+	Password string     `toon:"-"`
+	Address  tagAddress `toon:",inline"`
+	Role     string     `json:"role"`
+	Manager  *tagUser   `toon:"manager,omitempty"`
+	internal int
+}
+
+func TestStructTags(t *testing.T) {
+	u := tagUser{
+		TagBase:  TagBase{ID: 9007199254740993},
+		Name:     "Ann",
+		Password: "secret",
+		Address:  tagAddress{City: "Paris", Zip: "75001"},
+		Role:     "admin",
+		internal: 1,
+	}
+	result := ToToon(u)
+	expected := "id: 9007199254740993\nname: Ann\ncity: Paris\nzip: 75001\nrole: admin"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestStructTags_Tables(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []tagAddress{{City: "Paris", Zip: "75001"}, {City: "Oslo", Zip: "0150"}},
+	}
+	result := ToToon(data)
+	expected := "users[2]{city,zip}:\n  Paris,75001\n  Oslo,0150"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestStructTags_Nested(t *testing.T) {
+	boss := &tagUser{Name: "Bob", Role: "ceo"}
+	u := tagUser{Name: "Ann", Manager: boss, Email: "ann@example.com"}
+	result := ToToon(u)
+	expected := "id: 0\nname: Ann\nemail: ann@example.com\ncity: \nzip: \nrole: \n" +
+		"manager:\n  id: 0\n  name: Bob\n  city: \n  zip: \n  role: ceo"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestStructTags_KeyOrderOptions(t *testing.T) {
+	result := ToToonWithOptions(tagAddress{City: "Paris", Zip: "75001"}, WithPriorityKeys("zip"))
+	expected := "zip: 75001\ncity: Paris"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+type hiddenBase struct {
+	Visible string `json:"visible"`
+}
+
+type withHiddenBase struct {
+	hiddenBase
+	Name string `toon:"name" json:"json_name"`
+}
+
+func TestStructTags_JSONFallback(t *testing.T) {
+	// time.Time marshals itself, so it is written as its JSON string
+	stamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	result := ToToon(map[string]interface{}{"at": stamp})
+	if expected := "at: 2024-05-01T12:00:00Z"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// fields promoted from an unexported embedded struct are only readable
+	// by encoding/json, so the struct goes through it with its json tags
+	result = ToToonWithOptions(withHiddenBase{hiddenBase{"yes"}, "Ann"}, withSortedKeys())
+	if expected := "json_name: Ann\nvisible: yes"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestReflectValue_Containers(t *testing.T) {
+	var nilUser *tagUser
+	data := map[string]interface{}{
+		"counts": map[string]int{"a": 1},
+		"ids":    [2]uint8{1, 2},
+		"none":   nilUser,
+		"blob":   []byte("hi"),
+	}
+	result := ToToonWithOptions(data, withSortedKeys())
+	expected := "blob: aGk=\ncounts:\n  a: 1\nids:\n  - 1\n  - 2\nnone: null"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+type tagLevel int
+
+type tagStatus string
+
+func TestReflectValue_NamedScalars(t *testing.T) {
+	data := map[string]interface{}{
+		"level":   tagLevel(3),
+		"status":  tagStatus("ok"),
+		"max":     uint64(18446744073709551615),
+		"byLevel": map[tagLevel]string{2: "b", 10: "a"},
+		"ratio":   float32(0.5),
+	}
+	result := ToToon(data)
+	expected := "byLevel:\n  10: a\n  2: b\nlevel: 3\nmax: 18446744073709551615\nratio: 0.5\nstatus: ok"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+type cyclicNode struct {
+	Name string      `toon:"name"`
+	Next *cyclicNode `toon:"next"`
+}
+
+func TestStructs_Cycle(t *testing.T) {
+	n := &cyclicNode{Name: "a"}
+	n.Next = &cyclicNode{Name: "b", Next: n}
+	if result, expected := ToToon(n), "name: a\nnext:\n  name: b\n  next: null"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+	_, err := Marshal(n)
+	var unsupported *UnsupportedValueError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected UnsupportedValueError, got: %v", err)
+	}
+	if expected := "totoon: encountered a cycle via *totoon.cyclicNode"; err.Error() != expected {
+		t.Errorf("Expected %q, got: %q", expected, err.Error())
+	}
+}
+
+func TestStructs_SharedPointer(t *testing.T) {
+	shared := &cyclicNode{Name: "s"}
+	data := []interface{}{map[string]interface{}{"a": shared, "b": shared}}
+	if _, err := Encode(data); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestUnorder_MapCycle(t *testing.T) {
+	m := map[string]interface{}{"a": 1}
+	m["self"] = m
+	if _, err := Encode(m); err == nil {
+		t.Error("Expected a cycle error")
+	}
+}
+
+func BenchmarkToToon_Structs(b *testing.B) {
+	type order struct {
+		ID     int64   `toon:"id"`
+		Status string  `toon:"status"`
+		Total  float64 `toon:"total"`
+		Note   string  `toon:"note,omitempty"`
+	}
+	orders := make([]order, 200)
+	for i := range orders {
+		orders[i] = order{ID: int64(i), Status: "shipped", Total: float64(i) * 1.5}
+	}
+	data := map[string]interface{}{"orders": orders}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToToon(data)
+	}
+}
+
+type DupA struct {
+	X     int
+	Label int `json:"Label"`
+	Both  int `toon:"both"`
+}
+
+type DupB struct {
+	X     int
+	Label int
+	Other int `toon:"both"`
+}
+
+type DupWrap struct {
+	DupB
+	Y int
+}
+
+type dupSameDepth struct {
+	DupA
+	DupB
+	Name string
+}
+
+type dupNested struct {
+	DupA
+	DupWrap
+}
+
+func TestStructTags_DominantFields(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		expected string
+	}{
+		// X ties and is dropped, the tagged Label wins, both tagged "both" tie
+		{dupSameDepth{DupA{1, 2, 3}, DupB{4, 5, 6}, "n"}, "Label: 2\nName: n"},
+		// the fields of DupA are less nested than those of DupB
+		{dupNested{DupA{1, 2, 3}, DupWrap{DupB{4, 5, 6}, 7}}, "Label: 2\nX: 1\nY: 7\nboth: 3"},
+	}
+	for _, tt := range tests {
+		if result := ToToonWithOptions(tt.v, withSortedKeys()); result != tt.expected {
+			t.Errorf("%T: Expected %q, got: %q", tt.v, tt.expected, result)
+		}
+	}
+
+	// without the toon tags, the fields are those encoding/json writes
+	v := dupSameDepth{DupA{1, 2, 3}, DupB{4, 5, 6}, "n"}
+	data, _ := json.Marshal(v)
+	var viaJSON map[string]interface{}
+	json.Unmarshal(data, &viaJSON)
+	delete(viaJSON, "Both")
+	delete(viaJSON, "Other")
+	if expected, result := ToToonWithOptions(viaJSON, withSortedKeys()), ToToonWithOptions(v, withSortedKeys()); result != expected {
+		t.Errorf("Expected the fields of encoding/json %q, got: %q", expected, result)
+	}
+}
+
+type dashField struct {
+	Dash    string `json:"-,"`
+	Skipped string `json:"-"`
+}
+
+func TestStructTags_DashName(t *testing.T) {
+	result := ToToon(dashField{"d", "s"})
+	if expected := "-: d"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+type quotedFields struct {
+	ID    int64   `json:"id,string"`
+	Ratio float64 `json:"ratio,string"`
+	OK    bool    `json:"ok,string"`
+	Name  string  `json:"name,string"`
+	Ptr   *int    `json:"ptr,string"`
+	List  []int   `json:"list,string"`
+}
+
+func TestStructTags_String(t *testing.T) {
+	seven := 7
+	v := quotedFields{ID: 9007199254740993, Ratio: 0.5, OK: true, Name: "Ann", Ptr: &seven, List: []int{1}}
+	data, _ := json.Marshal(v)
+	var viaJSON interface{}
+	json.Unmarshal(data, &viaJSON)
+	for _, dialect := range []Dialect{DialectV1, DialectV2} {
+		expected := ToToonWithOptions(viaJSON, withSortedKeys(), WithDialect(dialect))
+		result := ToToonWithOptions(v, withSortedKeys(), WithDialect(dialect))
+		if result != expected {
+			t.Errorf("Expected the fields of encoding/json %q, got: %q", expected, result)
+		}
+
+		var decoded quotedFields
+		if err := UnmarshalWithOptions([]byte(result), &decoded, WithDialect(dialect)); err != nil {
+			t.Fatalf("Unexpected error: %v\n%s", err, result)
+		}
+		if decoded.ID != v.ID || decoded.Ratio != v.Ratio || !decoded.OK || decoded.Name != "Ann" || decoded.Ptr == nil || *decoded.Ptr != 7 || len(decoded.List) != 1 {
+			t.Errorf("Expected %+v back, got: %+v", v, decoded)
+		}
+	}
+}
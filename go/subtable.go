@@ -0,0 +1,39 @@
+package totoon
+
+import "strings"
+
+// WithSubTables writes a cell holding a list of objects as an indented child
+// table beneath its row instead of the packed inline form
+// ([N]{a,b}:1,x;2,y). The cell keeps only the row count, [N], and the child
+// table is named after the cell's path, such as users[0].orders, so each one
+// refers back to its row. Child tables can nest further, and FromToon reads
+// each one back into the cell of its row.
+func WithSubTables() Option {
+	return func(o *options) {
+		o.subTables = true
+	}
+}
+
+// subTableRows reports whether v is written as a child table, returning its
+// rows
+func (e *encoder) subTableRows(v interface{}) ([]interface{}, bool) {
+	if !e.subTables {
+		return nil, false
+	}
+	rows, ok := v.([]interface{})
+	if !ok || len(rows) == 0 {
+		return nil, false
+	}
+	if _, isObj := rows[0].(map[string]interface{}); !isObj {
+		return nil, false
+	}
+	return rows, true
+}
+
+// subTableToToon renders the child table for the current cell path, indented
+// one level below rows written with rowPrefix
+func (e *encoder) subTableToToon(rows []interface{}, rowPrefix string) string {
+	table := e.listOfObjectsToToon(e.path, rows, 0)
+	prefix := rowPrefix + strings.Repeat(" ", e.indent)
+	return prefix + strings.ReplaceAll(table, "\n", "\n"+prefix)
+}
@@ -0,0 +1,105 @@
+package totoon
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWithSubTables(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"orders": []interface{}{
+					map[string]interface{}{"qty": 1, "sku": "A1"},
+					map[string]interface{}{"qty": 3, "sku": "B2"},
+				},
+			},
+			map[string]interface{}{"id": 2, "orders": []interface{}{}},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithSubTables())
+	expected := `users[2]{id,orders}:
+  1,[2]
+    users[0].orders[2]{qty,sku}:
+      1,A1
+      3,B2
+  2,[]`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestWithSubTables_Nested(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{
+			"a": []interface{}{
+				map[string]interface{}{
+					"b": []interface{}{map[string]interface{}{"c": 1}},
+				},
+			},
+		},
+	}
+	result := ToToonWithOptions(data, WithSubTables())
+	expected := `[1]{a}:
+  [1]
+    [0].a[1]{b}:
+      [1]
+        [0].a[0].b[1]{c}:
+          1`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestWithSubTables_DefaultInline(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"a": []interface{}{map[string]interface{}{"c": 1}}},
+	}
+	if result := ToToon(data); result != "[1]{a}:\n  [1]{c}:1" {
+		t.Errorf("Expected inline nested table, got: %q", result)
+	}
+}
+
+func TestWithSubTables_RoundTrip(t *testing.T) {
+	var data interface{}
+	input := `{"users":[{"id":1,"orders":[{"sku":"a","lines":[{"n":1},{"n":2}]},{"sku":"b","lines":[]}]},{"id":3,"orders":[{"sku":"c","lines":[{"n":5}]}]}],"after":{"x":1}}`
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range [][]Option{
+		{WithSubTables()},
+		{WithSubTables(), WithIndent(4)},
+		{WithSubTables(), WithLengthMarkers(false)},
+		{WithSubTables(), WithHeaderStyle(TwoLineHeader)},
+		{WithSubTables(), WithDialect(DialectV2)},
+	} {
+		toon := ToToonWithOptions(data, opts...)
+		v, err := FromToonWithOptions(toon, opts...)
+		if err != nil {
+			t.Fatalf("FromToon(%q): %v", toon, err)
+		}
+		var back interface{}
+		b, _ := json.Marshal(v)
+		if err := json.Unmarshal(b, &back); err != nil || !reflect.DeepEqual(back, data) {
+			t.Errorf("Round trip of:\n%s\ngot: %s", toon, b)
+		}
+	}
+}
+
+func TestWithSubTables_RootTable(t *testing.T) {
+	toon := `[2]{id,tags}:
+  1,[1]
+    [0].tags[1]{t}:
+      x
+  2,[]`
+	v, err := FromToon(toon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(v)
+	if expected := `[{"id":1,"tags":[{"t":"x"}]},{"id":2,"tags":[]}]`; string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+}
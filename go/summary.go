@@ -0,0 +1,118 @@
+package totoon
+
+import "strings"
+
+// summaryMarker starts the footer row written by WithSummaryRow
+const summaryMarker = "= "
+
+// Aggregate is a value computed over one table column for WithSummaryRow;
+// create it with Sum, Avg, Count or Distinct
+type Aggregate struct {
+	column string
+	name   string
+}
+
+// Sum adds up the numeric values of column
+func Sum(column string) Aggregate {
+	return Aggregate{column: column, name: "sum"}
+}
+
+// Avg averages the numeric values of column
+func Avg(column string) Aggregate {
+	return Aggregate{column: column, name: "avg"}
+}
+
+// Count counts the rows with a non-null value in column
+func Count(column string) Aggregate {
+	return Aggregate{column: column, name: "count"}
+}
+
+// Distinct counts the different non-null values of column
+func Distinct(column string) Aggregate {
+	return Aggregate{column: column, name: "distinct"}
+}
+
+// WithSummaryRow appends a footer row of computed values beneath every
+// table, aligned with its columns and marked with a leading "= ", so
+// models get totals without adding them up:
+//
+//	orders[2]{region,amount}:
+//	  eu,10
+//	  us,32.5
+//	  = distinct:2,sum:42.5
+//
+// Each cell names its aggregates; columns without one, or with no values
+// to aggregate, are left empty. Aggregates cover every row of the table,
+// including those cut by WithMaxRows. Tables that do not have any of the
+// columns get no footer.
+func WithSummaryRow(cols ...Aggregate) Option {
+	return func(o *options) {
+		o.summary = append(o.summary, cols...)
+	}
+}
+
+// summaryRow returns the footer row of a table, if WithSummaryRow applies
+func (e *encoder) summaryRow(data []interface{}, columns []string) (string, bool) {
+	if len(e.summary) == 0 {
+		return "", false
+	}
+	cells := make([]string, len(columns))
+	found := false
+	for i, column := range columns {
+		var parts []string
+		for _, agg := range e.summary {
+			if agg.column != column {
+				continue
+			}
+			found = true
+			if value, ok := e.aggregate(agg, data); ok {
+				parts = append(parts, agg.name+":"+value)
+			}
+		}
+		cells[i] = strings.Join(parts, " ")
+	}
+	if !found {
+		return "", false
+	}
+	return summaryMarker + strings.Join(cells, e.delimiterString()), true
+}
+
+// aggregate computes agg over the rows of data, reporting false when there
+// is nothing to aggregate
+func (e *encoder) aggregate(agg Aggregate, data []interface{}) (string, bool) {
+	var sum float64
+	numbers, count := 0, 0
+	distinct := make(map[string]bool)
+	for _, item := range data {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		v, exists := obj[agg.column]
+		if !exists || v == nil {
+			continue
+		}
+		count++
+		distinct[e.valueToToonInline(v)] = true
+		if f, ok := toFloat(v); ok {
+			sum += f
+			numbers++
+		}
+	}
+
+	switch agg.name {
+	case "sum":
+		if numbers == 0 {
+			return "", false
+		}
+		return formatPlainNumber(sum), true
+	case "avg":
+		if numbers == 0 {
+			return "", false
+		}
+		return formatPlainNumber(sum / float64(numbers)), true
+	case "count":
+		return formatPlainNumber(count), true
+	}
+	return formatPlainNumber(len(distinct)), true
+}
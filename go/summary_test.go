@@ -0,0 +1,38 @@
+package totoon
+
+import "testing"
+
+func TestWithSummaryRow(t *testing.T) {
+	data := map[string]interface{}{
+		"orders": []interface{}{
+			map[string]interface{}{"id": 1, "region": "eu", "amount": 10},
+			map[string]interface{}{"id": 2, "region": "us", "amount": 20.5},
+			map[string]interface{}{"id": 3, "region": "eu", "amount": nil},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(),
+		WithSummaryRow(Sum("amount"), Avg("amount"), Count("amount"), Distinct("region")))
+	expected := "orders[3]{amount,id,region}:\n  10,1,eu\n  20.5,2,us\n  null,3,eu\n  = sum:30.5 avg:15.25 count:2,,distinct:2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithSummaryRow_CoversCutRows(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"n": 1},
+		map[string]interface{}{"n": 2},
+		map[string]interface{}{"n": "x"},
+	}
+	result := ToToonWithOptions(rows, WithMaxRows(1), WithSummaryRow(Sum("n"), Count("n")))
+	expected := "[3]{n}:\n  1\n  … +2 more rows\n  = sum:3 count:3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// tables without any of the columns get no footer
+	result = ToToonWithOptions(rows, WithSummaryRow(Sum("other")))
+	if expected := "[3]{n}:\n  1\n  2\n  x"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
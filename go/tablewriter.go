@@ -0,0 +1,182 @@
+package totoon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// countWidth is the number of digits reserved for a back-patched row count
+const countWidth = 10
+
+// TableWriter streams a single table whose row count is not known upfront.
+// The header is written as soon as the TableWriter is created and each row
+// as it arrives.
+//
+// When the destination implements io.WriteSeeker (an *os.File, for example)
+// the header reserves a zero-padded count, key[0000000000]{a,b}:, which Close
+// back-patches with the final number of rows. Other destinations, and files
+// opened with O_APPEND, get a header with an empty count, key[]{a,b}:, since
+// it cannot be rewritten once sent; it reads back without a count check.
+type TableWriter struct {
+	e       *encoder
+	dst     io.Writer
+	w       *bufio.Writer
+	fields  []string
+	rows    int
+	countAt int64 // offset of the reserved count, -1 when not back-patching
+	closed  bool
+	err     error
+}
+
+// NewTableWriter writes the header of a table under key (or a root table
+// when key is empty) to w and returns a TableWriter for its rows
+func NewTableWriter(w io.Writer, key string, fields []string, opts ...Option) (*TableWriter, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("totoon: a table needs at least one field")
+	}
+
+	e := newEncoder(opts)
+	t := &TableWriter{
+		e:       e,
+		dst:     w,
+		w:       e.newBufferedWriter(w),
+		fields:  fields,
+		countAt: -1,
+	}
+
+	labels := e.columnLabels(fields)
+	if key != "" {
+		key = e.headerKey(key)
+	}
+	var start int64
+	patch := false
+	if !e.noLengths {
+		start, patch = t.patchable()
+	}
+
+	var header string
+	switch {
+	case patch:
+		// Every header style writes the count right after the key and an
+		// opening bracket or parenthesis
+		header = e.formatHeader(key, fmt.Sprintf("%0*d", countWidth, 0), labels)
+		t.countAt = start + int64(len(key)) + 1
+	case e.noLengths:
+		header = e.formatHeader(key, "", labels)
+	default:
+		header = e.openHeader(key, labels)
+	}
+	if _, err := t.w.WriteString(header); err != nil {
+		return nil, err
+	}
+	if !patch {
+		return t, nil
+	}
+
+	// Check that the header landed where the count is expected
+	if err := t.w.Flush(); err != nil {
+		return nil, err
+	}
+	end, err := t.dst.(io.Seeker).Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if end != start+int64(len(header)) {
+		return nil, fmt.Errorf("totoon: table header not written at offset %d, its count cannot be back-patched", start)
+	}
+	return t, nil
+}
+
+// patchable reports whether the row count can be back-patched, with the
+// offset the header will be written at. Destinations that implement
+// io.WriterAt are probed with an empty write, which an *os.File opened with
+// O_APPEND refuses, since its writes would land at the end instead.
+func (t *TableWriter) patchable() (int64, bool) {
+	seeker, ok := t.dst.(io.WriteSeeker)
+	if !ok {
+		return 0, false
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	if at, ok := t.dst.(io.WriterAt); ok {
+		if _, err := at.WriteAt(nil, start); err != nil {
+			return 0, false
+		}
+	}
+	return start, true
+}
+
+// WriteRow writes one row; values are matched to the fields by position
+func (t *TableWriter) WriteRow(values ...interface{}) error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.closed {
+		return errors.New("totoon: WriteRow called on a closed TableWriter")
+	}
+	if len(values) != len(t.fields) {
+		return fmt.Errorf("totoon: row has %d values, table has %d fields", len(values), len(t.fields))
+	}
+
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = t.e.columnCellToToon(t.fields[i], t.e.unorder(v))
+	}
+	t.e.orders = nil
+	if _, err := t.w.WriteString("\n" + t.e.rowPrefix(0) + strings.Join(cells, t.e.delimiterString())); err != nil {
+		t.err = err
+		return err
+	}
+	t.rows++
+	return nil
+}
+
+// Flush writes buffered rows to the underlying writer. The row count is
+// only back-patched by Close.
+func (t *TableWriter) Flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.w.Flush(); err != nil {
+		t.err = err
+	}
+	return t.err
+}
+
+// Close flushes the remaining rows and, when possible, back-patches the row
+// count in the header. It does not close the underlying writer.
+func (t *TableWriter) Close() error {
+	if t.closed {
+		return t.err
+	}
+	t.closed = true
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.w.Flush(); err != nil {
+		t.err = err
+		return err
+	}
+	if t.countAt < 0 {
+		return nil
+	}
+
+	seeker := t.dst.(io.WriteSeeker)
+	end, err := seeker.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = seeker.Seek(t.countAt, io.SeekStart)
+	}
+	if err == nil {
+		_, err = fmt.Fprintf(seeker, "%0*d", countWidth, t.rows)
+	}
+	if err == nil {
+		_, err = seeker.Seek(end, io.SeekStart)
+	}
+	t.err = err
+	return err
+}
@@ -0,0 +1,155 @@
+package totoon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableWriter_NonSeekable(t *testing.T) {
+	var buf bytes.Buffer
+	tw, err := NewTableWriter(&buf, "events", []string{"id", "msg"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw.WriteRow(1, "start")
+	tw.WriteRow(2, "a, b")
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "events[]{id,msg}:\n  1,start\n  2,\"a, b\""
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestTableWriter_BackPatchesCount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.toon")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.WriteString("title: Log\n")
+	tw, err := NewTableWriter(f, "events", []string{"id"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		tw.WriteRow(i)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.WriteString("\nfooter: end")
+	f.Close()
+
+	content, _ := os.ReadFile(path)
+	expected := "title: Log\nevents[0000000003]{id}:\n  1\n  2\n  3\nfooter: end"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(content))
+	}
+}
+
+func TestTableWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewTableWriter(&buf, "t", nil); err == nil {
+		t.Errorf("Expected error for table without fields")
+	}
+	tw, _ := NewTableWriter(&buf, "t", []string{"a", "b"})
+	if err := tw.WriteRow(1); err == nil {
+		t.Errorf("Expected error for short row")
+	}
+	tw.Close()
+	if err := tw.WriteRow(1, 2); err == nil {
+		t.Errorf("Expected error after Close")
+	}
+}
+
+func TestTableWriter_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	tw, _ := NewTableWriter(&buf, "events", []string{"id"}, WithBufferSize(1024))
+	tw.WriteRow(1)
+	if buf.Len() != 0 {
+		t.Errorf("Expected output to stay buffered, got: %q", buf.String())
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buf.String() != "events[]{id}:\n  1" {
+		t.Errorf("Expected flushed header and row, got: %q", buf.String())
+	}
+}
+
+func TestTableWriter_OpenHeaderDecodes(t *testing.T) {
+	for _, style := range []HeaderStyle{BracketHeader, ParenHeader, TwoLineHeader} {
+		var buf bytes.Buffer
+		tw, err := NewTableWriter(&buf, "events", []string{"id", "msg"}, WithHeaderStyle(style))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tw.WriteRow(1, "start")
+		tw.WriteRow(2, "stop")
+		tw.Close()
+
+		if style == BracketHeader && !Valid(buf.Bytes()) {
+			t.Errorf("Expected valid document, got: %q", buf.String())
+		}
+		decoded, err := FromToonWithOptions(buf.String(), WithHeaderStyle(style))
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", buf.String(), err)
+		}
+		events := decoded.(map[string]interface{})["events"].([]interface{})
+		if len(events) != 2 {
+			t.Errorf("Expected 2 rows from %q, got: %v", buf.String(), events)
+		}
+	}
+}
+
+func TestTableWriter_AppendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.toon")
+	os.WriteFile(path, []byte("title: Log\n"), 0o644)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw, err := NewTableWriter(f, "events", []string{"id"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw.WriteRow(1)
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.Close()
+
+	content, _ := os.ReadFile(path)
+	expected := "title: Log\nevents[]{id}:\n  1"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(content))
+	}
+}
+
+func TestTableWriter_QuotedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.toon")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw, err := NewTableWriter(f, "my events", []string{"id"}, WithDialect(DialectV2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tw.WriteRow(1)
+	tw.WriteRow(2)
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.Close()
+
+	content, _ := os.ReadFile(path)
+	expected := "\"my events\"[0000000002]{id}:\n  1\n  2"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(content))
+	}
+}
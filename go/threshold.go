@@ -0,0 +1,80 @@
+package totoon
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithTableMinRows writes lists of fewer than n objects as list items
+// instead of tables, since a header for one or two rows costs more than it
+// saves. Tables nested inside cells are not affected.
+func WithTableMinRows(n int) Option {
+	return func(o *options) {
+		o.tableMinRows = n
+	}
+}
+
+// WithTableMaxMissingFieldRatio writes lists of objects as list items
+// instead of tables when the share of missing cells, those whose row lacks
+// the column, is above ratio: 0 only allows tables whose rows all have the
+// same fields, and 0.5 tables with at most half of their cells missing.
+// Tables nested inside cells are not affected. Negative ratios are ignored.
+func WithTableMaxMissingFieldRatio(ratio float64) Option {
+	return func(o *options) {
+		if ratio < 0 {
+			return
+		}
+		o.maxMissing = ratio
+		o.maxMissingSet = true
+	}
+}
+
+// tableTooSparse reports whether data is too short or too sparse for the
+// table thresholds, and why
+func (e *encoder) tableTooSparse(data []interface{}) (string, bool) {
+	if len(data) < e.tableMinRows {
+		return "it has fewer than " + strconv.Itoa(e.tableMinRows) + " rows", true
+	}
+	if !e.maxMissingSet {
+		return "", false
+	}
+	columns := map[string]bool{}
+	present := 0
+	for _, item := range data {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k := range obj {
+			columns[k] = true
+		}
+		present += len(obj)
+	}
+	cells := len(columns) * len(data)
+	if cells == 0 {
+		return "", false
+	}
+	ratio := float64(cells-present) / float64(cells)
+	if ratio <= e.maxMissing {
+		return "", false
+	}
+	return "too many of its cells would be missing", true
+}
+
+// writeSparseList writes a list of objects that the table thresholds keep
+// from being a table as list items under key, as DialectV1 writes lists
+// with WithOneValuePerLine and DialectV2 writes rows that are not uniform
+func (e *encoder) writeSparseList(key string, data []interface{}, level int, emit lineSink) {
+	prefix := strings.Repeat(" ", e.indent*level)
+	if e.strict() {
+		emit(prefix + key + "[" + strconv.Itoa(len(data)) + "]:")
+		e.writeSpecItems(data, level+1, emit)
+		return
+	}
+	if key == "" {
+		emit(e.expandedListToToon(data, level))
+		return
+	}
+	emit(prefix + key + ":")
+	emit(e.expandedListToToon(data, level+1))
+}
@@ -0,0 +1,65 @@
+package totoon
+
+import "testing"
+
+func TestWithTableMinRows(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{map[string]interface{}{"id": 1, "name": "Alice"}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithTableMinRows(2))
+	expected := "users:\n  - id: 1\n    name: Alice"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	data["users"] = append(data["users"].([]interface{}), map[string]interface{}{"id": 2, "name": "Bob"})
+	result = ToToonWithOptions(data, withSortedKeys(), WithTableMinRows(2))
+	expected = "users[2]{id,name}:\n  1,Alice\n  2,Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithTableMinRows_Root(t *testing.T) {
+	data := []interface{}{map[string]interface{}{"id": 1}}
+	if result := ToToonWithOptions(data, WithTableMinRows(2)); result != "- id: 1" {
+		t.Errorf("Expected %q, got: %q", "- id: 1", result)
+	}
+	result := ToToonWithOptions(data, WithTableMinRows(2), WithDialect(DialectV2))
+	if expected := "[1]:\n  - id: 1"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithTableMaxMissingFieldRatio(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"a": 1, "b": 2},
+		map[string]interface{}{"a": 3},
+	}
+	// one of the four cells is missing
+	tests := []struct {
+		ratio    float64
+		expected string
+	}{
+		{0.25, "[2]{a,b}:\n  1,2\n  3,"},
+		{0.2, "- a: 1\n  b: 2\n- a: 3"},
+		{0, "- a: 1\n  b: 2\n- a: 3"},
+	}
+	for _, tt := range tests {
+		result := ToToonWithOptions(data, withSortedKeys(), WithTableMaxMissingFieldRatio(tt.ratio))
+		if result != tt.expected {
+			t.Errorf("ratio %v: Expected %q, got: %q", tt.ratio, tt.expected, result)
+		}
+	}
+}
+
+func TestWithTableMaxMissingFieldRatio_Unset(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"a": 1},
+		map[string]interface{}{"b": 2},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithTableMaxMissingFieldRatio(-1))
+	if expected := "[2]{a,b}:\n  1,\n  ,2"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
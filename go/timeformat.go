@@ -0,0 +1,61 @@
+package totoon
+
+import "time"
+
+// TimeFormat selects how time.Time values are written
+type TimeFormat int
+
+const (
+	// TimeRFC3339 writes RFC 3339 with as many fractional second digits as
+	// needed, as encoding/json does (the default)
+	TimeRFC3339 TimeFormat = iota
+	// TimeUnix writes whole seconds since the Unix epoch
+	TimeUnix
+	// TimeUnixMilli writes milliseconds since the Unix epoch
+	TimeUnixMilli
+	// TimeLayout writes the layout set by WithTimeLayout
+	TimeLayout
+)
+
+// WithTimeFormat selects how time.Time values are written. The Unix
+// formats write plain integers, the most compact cells for timestamp
+// columns.
+func WithTimeFormat(format TimeFormat) Option {
+	return func(o *options) {
+		o.timeFormat = format
+	}
+}
+
+// WithTimeLayout writes time.Time values with a time.Format layout, such as
+// time.DateOnly or "2006-01-02 15:04"
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = TimeLayout
+		o.timeLayout = layout
+	}
+}
+
+// timeValue converts a time.Time or *time.Time to the value written for it
+func (e *encoder) timeValue(v interface{}) (interface{}, bool) {
+	var t time.Time
+	switch val := v.(type) {
+	case time.Time:
+		t = val
+	case *time.Time:
+		if val == nil {
+			return nil, true
+		}
+		t = *val
+	default:
+		return nil, false
+	}
+	switch e.timeFormat {
+	case TimeUnix:
+		return t.Unix(), true
+	case TimeUnixMilli:
+		return t.UnixMilli(), true
+	case TimeLayout:
+		return t.Format(e.timeLayout), true
+	}
+	return t.Format(time.RFC3339Nano), true
+}
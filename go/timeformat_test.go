@@ -0,0 +1,39 @@
+package totoon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithTimeFormat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 500000000, time.UTC)
+	type event struct {
+		Name string    `toon:"name"`
+		At   time.Time `toon:"at"`
+	}
+	data := map[string]interface{}{
+		"events": []event{{"start", at}, {"stop", at.Add(time.Second)}},
+		"none":   (*time.Time)(nil),
+	}
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, "events[2]{name,at}:\n  start,\"2024-05-01T12:30:00.5Z\"\n  stop,\"2024-05-01T12:30:01.5Z\"\nnone: null"},
+		{[]Option{WithTimeFormat(TimeUnix)}, "events[2]{name,at}:\n  start,1714566600\n  stop,1714566601\nnone: null"},
+		{[]Option{WithTimeFormat(TimeUnixMilli)}, "events[2]{name,at}:\n  start,1714566600500\n  stop,1714566601500\nnone: null"},
+		{[]Option{WithTimeLayout("2006-01-02")}, "events[2]{name,at}:\n  start,2024-05-01\n  stop,2024-05-01\nnone: null"},
+	}
+	for _, tt := range tests {
+		if result := ToToonWithOptions(data, tt.opts...); result != tt.expected {
+			t.Errorf("Expected %q, got: %q", tt.expected, result)
+		}
+	}
+}
+
+func TestWithTimeFormat_MatchesJSONByDefault(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	if result := ToToon(at); result != "2024-05-01T12:30:00+02:00" {
+		t.Errorf("Expected %q, got: %q", "2024-05-01T12:30:00+02:00", result)
+	}
+}
@@ -0,0 +1,79 @@
+package totoon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// WithJSONIndent makes ToonToJSON write indented JSON with indent spaces
+// per level; by default it writes compact JSON
+func WithJSONIndent(indent int) Option {
+	return func(o *options) {
+		o.jsonIndent = indent
+	}
+}
+
+// ToonToJSON converts a TOON document to JSON, the inverse of JSONToToon.
+// The document is parsed as by FromToonWithOptions with opts, and objects
+// keep the key order of the document.
+func ToonToJSON(toonStr string, opts ...Option) (string, error) {
+	e := newEncoder(opts)
+	v, err := newDecoder(toonStr, e).document()
+	if err != nil {
+		return "", err
+	}
+	out, err := e.marshalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// marshalJSON writes a parsed document as JSON, indented under
+// WithJSONIndent
+func (e *encoder) marshalJSON(v interface{}) ([]byte, error) {
+	if e.jsonIndent > 0 {
+		return json.MarshalIndent(v, "", strings.Repeat(" ", e.jsonIndent))
+	}
+	return json.Marshal(v)
+}
+
+// NewJSONReader returns a reader of the JSON form of the TOON read from
+// toonSource, converted as ToonToJSON converts it, for feeding TOON to
+// libraries that only read JSON. Documents are converted one at a time as
+// the consumer reads, so only the current one is held in memory; a stream
+// of documents separated as Encoder writes them yields a JSON value per
+// document, each followed by a newline as json.Encoder writes them. Parse
+// errors are returned by Read, with lines counted as Decoder counts them.
+func NewJSONReader(toonSource io.Reader, opts ...Option) io.Reader {
+	dec := NewDecoder(toonSource, opts...)
+	return &jsonReader{dec: dec}
+}
+
+type jsonReader struct {
+	dec *Decoder
+	buf []byte // converted output not read yet
+	err error
+}
+
+func (r *jsonReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 && r.err == nil {
+		doc, err := r.dec.document(context.Background())
+		if err == nil {
+			r.buf, err = r.dec.e.marshalJSON(doc)
+		}
+		if err != nil {
+			r.buf, r.err = nil, err
+			break
+		}
+		r.buf = append(r.buf, '\n')
+	}
+	if len(r.buf) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
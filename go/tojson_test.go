@@ -0,0 +1,86 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestToonToJSON(t *testing.T) {
+	input := "name: Ann\nusers[2]{id,admin}:\n  1,true\n  2,false\nempty: \n{}"
+	result, err := ToonToJSON(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"name":"Ann","users":[{"id":1,"admin":true},{"id":2,"admin":false}],"empty":{}}`
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestToonToJSON_Indent(t *testing.T) {
+	result, err := ToonToJSON("b: 1\na:\n  - x", WithJSONIndent(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "{\n  \"b\": 1,\n  \"a\": [\n    \"x\"\n  ]\n}"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestToonToJSON_RoundTrip(t *testing.T) {
+	input := `{"z":1,"a":{"y":[1,2.5,null],"b":"a, b"},"rows":[{"id":1,"tags":["x"]}]}`
+	toon, err := JSONToToon(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := ToonToJSON(toon)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// key order is lost on the way in, so compare the values
+	if want, got := mustJSON(t, input), mustJSON(t, result); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got: %v", want, got)
+	}
+}
+
+func TestToonToJSON_Error(t *testing.T) {
+	_, err := ToonToJSON("ids[2]: 1")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 1 {
+		t.Errorf("Expected a *SyntaxError on line 1, got: %v", err)
+	}
+}
+
+func TestNewJSONReader(t *testing.T) {
+	input := "b: 1\na[2]: x,y\n---\n[1]{id}:\n  7\n"
+	out, err := io.ReadAll(iotest.OneByteReader(NewJSONReader(iotest.OneByteReader(strings.NewReader(input)))))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "{\"b\":1,\"a\":[\"x\",\"y\"]}\n[{\"id\":7}]\n"; string(out) != expected {
+		t.Errorf("Expected %q, got: %q", expected, string(out))
+	}
+
+	dec := json.NewDecoder(NewJSONReader(strings.NewReader("a: 1"), WithJSONIndent(2)))
+	var v map[string]int
+	if err := dec.Decode(&v); err != nil || v["a"] != 1 {
+		t.Errorf("Expected {a:1}, got: %v (%v)", v, err)
+	}
+}
+
+func TestNewJSONReader_SyntaxError(t *testing.T) {
+	out, err := io.ReadAll(NewJSONReader(strings.NewReader("a: 1\n---\nids[2]: 1\n")))
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+		t.Errorf("Expected a *SyntaxError on line 3, got: %v", err)
+	}
+	if expected := "{\"a\":1}\n"; string(out) != expected {
+		t.Errorf("Expected %q before the error, got: %q", expected, string(out))
+	}
+}
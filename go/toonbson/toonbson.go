@@ -0,0 +1,167 @@
+// Package toonbson converts BSON documents, such as those read from MongoDB,
+// to TOON. It reads bson.Raw directly, so documents keep their field order
+// at every level without being decoded into Go maps first.
+package toonbson
+
+import (
+	"bytes"
+	"time"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// BSONToToon converts a raw BSON document to TOON, keeping the field order
+// of the document. BSON-specific types are rendered as plain scalars:
+//
+//   - ObjectIDs as their 24-character hex string
+//   - Decimal128 values as exact decimal numbers
+//   - datetimes as RFC 3339 timestamps in UTC
+//   - other special types (binary, regex, timestamps, ...) in their Extended
+//     JSON form
+//
+// Arrays whose elements are all documents become tables.
+func BSONToToon(doc bson.Raw, opts ...totoon.Option) (string, error) {
+	if err := doc.Validate(); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := writeDocument(w, doc); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeDocument(w *totoon.Writer, doc bson.Raw) error {
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+	if err := w.BeginObject(); err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if err := w.Key(element.Key()); err != nil {
+			return err
+		}
+		if err := writeValue(w, element.Value()); err != nil {
+			return err
+		}
+	}
+	return w.EndObject()
+}
+
+func writeValue(w *totoon.Writer, v bson.RawValue) error {
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		return writeDocument(w, v.Document())
+	case bsontype.Array:
+		values, err := v.Array().Values()
+		if err != nil {
+			return err
+		}
+		if isTable(values) {
+			return writeTable(w, values)
+		}
+		if err := w.BeginList(); err != nil {
+			return err
+		}
+		for _, item := range values {
+			if err := writeValue(w, item); err != nil {
+				return err
+			}
+		}
+		return w.EndList()
+	}
+	return w.Scalar(scalarValue(v))
+}
+
+// writeTable writes an array of documents as one table. Its columns are the
+// element keys in order of first appearance, and each cell holds the
+// genericValue of its element.
+func writeTable(w *totoon.Writer, values []bson.RawValue) error {
+	rows := make([]*totoon.OrderedMap, len(values))
+	for i, value := range values {
+		elements, err := value.Document().Elements()
+		if err != nil {
+			return err
+		}
+		row := totoon.NewOrderedMap()
+		for _, element := range elements {
+			row.Set(element.Key(), genericValue(element.Value()))
+		}
+		rows[i] = row
+	}
+	return w.Objects(rows)
+}
+
+func isTable(values []bson.RawValue) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		if value.Type != bsontype.EmbeddedDocument {
+			return false
+		}
+		if elements, err := value.Document().Elements(); err != nil || len(elements) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// genericValue converts a value into the *OrderedMap and slice form used for
+// table cells, so embedded documents keep the order of their fields
+func genericValue(v bson.RawValue) interface{} {
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		elements, _ := v.Document().Elements()
+		obj := totoon.NewOrderedMap()
+		for _, element := range elements {
+			obj.Set(element.Key(), genericValue(element.Value()))
+		}
+		return obj
+	case bsontype.Array:
+		values, _ := v.Array().Values()
+		list := make([]interface{}, len(values))
+		for i, item := range values {
+			list[i] = genericValue(item)
+		}
+		return list
+	}
+	return scalarValue(v)
+}
+
+func scalarValue(v bson.RawValue) interface{} {
+	switch v.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return nil
+	case bsontype.Boolean:
+		return v.Boolean()
+	case bsontype.String:
+		return v.StringValue()
+	case bsontype.Int32:
+		return v.Int32()
+	case bsontype.Int64:
+		return v.Int64()
+	case bsontype.Double:
+		return v.Double()
+	case bsontype.ObjectID:
+		return v.ObjectID().Hex()
+	case bsontype.Decimal128:
+		d := v.Decimal128()
+		if d.IsNaN() || d.IsInf() != 0 {
+			return d.String()
+		}
+		return totoon.RawMessage(d.String())
+	case bsontype.DateTime:
+		return time.UnixMilli(v.DateTime()).UTC().Format(time.RFC3339Nano)
+	}
+	return v.String()
+}
@@ -0,0 +1,104 @@
+package toonbson
+
+import (
+	"testing"
+	"time"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func marshal(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return raw
+}
+
+func TestBSONToToon_SpecialTypes(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("64b7f0c2a1b2c3d4e5f60718")
+	price, _ := primitive.ParseDecimal128("12345678901234567890.12")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	raw := marshal(t, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "price", Value: price},
+		{Key: "created", Value: primitive.NewDateTimeFromTime(created)},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "meta", Value: bson.D{{Key: "v", Value: int32(2)}}},
+	})
+	result, err := BSONToToon(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "_id: 64b7f0c2a1b2c3d4e5f60718\nprice: 12345678901234567890.12\ncreated: 2024-01-02T03:04:05Z\ntags:\n  - a\n  - b\nmeta:\n  v: 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBSONToToon_ArrayOfDocuments(t *testing.T) {
+	raw := marshal(t, bson.D{
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "A1"}, {Key: "qty", Value: int64(2)}},
+			bson.D{{Key: "sku", Value: "B2"}, {Key: "note", Value: "rush"}},
+		}},
+	})
+	result, err := BSONToToon(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "items[2]{sku,qty,note}:\n  A1,2,\n  B2,,rush"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBSONToToon_NestedCellOrder(t *testing.T) {
+	raw := marshal(t, bson.D{
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "A1"}, {Key: "dims", Value: bson.D{{Key: "w", Value: int32(2)}, {Key: "h", Value: int32(1)}}}},
+		}},
+	})
+	result, err := BSONToToon(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "items[1]{sku,dims}:\n  A1,{w:2,h:1}"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBSONToToon_MissingFields(t *testing.T) {
+	raw := marshal(t, bson.D{
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "A1"}, {Key: "qty", Value: int64(2)}},
+			bson.D{{Key: "sku", Value: "B2"}},
+		}},
+	})
+	result, err := BSONToToon(raw, totoon.WithMissingCell("-"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "items[2]{sku,qty}:\n  A1,2\n  B2,-"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	result, err = BSONToToon(raw, totoon.WithTableMinRows(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "items:\n  - sku: A1\n    qty: 2\n  - sku: B2"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestBSONToToon_Invalid(t *testing.T) {
+	if _, err := BSONToToon(bson.Raw{0x01, 0x02}); err == nil {
+		t.Errorf("Expected error for invalid BSON")
+	}
+}
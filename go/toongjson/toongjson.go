@@ -0,0 +1,117 @@
+// Package toongjson encodes gjson query results as TOON, so services that
+// already slice JSON with github.com/tidwall/gjson can render the selected
+// fragments without re-parsing them. Results are walked as gjson returns
+// them, and their numbers are copied from the source text.
+package toongjson
+
+import (
+	"bytes"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"github.com/tidwall/gjson"
+)
+
+// ResultToToon converts a gjson result to TOON. Object keys keep their order
+// in the source JSON, arrays of objects become tables, and numbers are
+// written exactly as they appear in the source so large integers keep their
+// precision. A result that does not exist encodes as null.
+func ResultToToon(r gjson.Result, opts ...totoon.Option) (string, error) {
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := writeResult(w, r); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GetToon runs a gjson path query against json and converts the result
+func GetToon(json, path string, opts ...totoon.Option) (string, error) {
+	return ResultToToon(gjson.Get(json, path), opts...)
+}
+
+func writeResult(w *totoon.Writer, r gjson.Result) error {
+	switch {
+	case r.IsObject():
+		if err := w.BeginObject(); err != nil {
+			return err
+		}
+		var err error
+		r.ForEach(func(key, value gjson.Result) bool {
+			if err = w.Key(key.String()); err == nil {
+				err = writeResult(w, value)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+		return w.EndObject()
+	case r.IsArray():
+		items := r.Array()
+		if isTable(items) {
+			return writeTable(w, items)
+		}
+		if err := w.BeginList(); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := writeResult(w, item); err != nil {
+				return err
+			}
+		}
+		return w.EndList()
+	}
+	return w.Scalar(scalarValue(r))
+}
+
+// writeTable writes an array of objects as totoon.ToToon writes a list of
+// objects, with columns in order of first appearance
+func writeTable(w *totoon.Writer, items []gjson.Result) error {
+	rows := make([]*totoon.OrderedMap, len(items))
+	for i, item := range items {
+		row := totoon.NewOrderedMap()
+		item.ForEach(func(key, value gjson.Result) bool {
+			if value.IsObject() || value.IsArray() {
+				row.Set(key.String(), value.Value())
+			} else {
+				row.Set(key.String(), scalarValue(value))
+			}
+			return true
+		})
+		rows[i] = row
+	}
+	return w.Objects(rows)
+}
+
+// isTable reports whether every item is a non-empty object
+func isTable(items []gjson.Result) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !item.IsObject() || len(item.Map()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func scalarValue(r gjson.Result) interface{} {
+	switch r.Type {
+	case gjson.Null:
+		return nil
+	case gjson.True:
+		return true
+	case gjson.False:
+		return false
+	case gjson.Number:
+		return totoon.RawMessage(r.Raw)
+	}
+	if !r.Exists() {
+		return nil
+	}
+	return r.String()
+}
@@ -0,0 +1,56 @@
+package toongjson
+
+import (
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"github.com/tidwall/gjson"
+)
+
+const sample = `{
+	"service": "api",
+	"stats": {"requests": 12, "id": 9007199254740993},
+	"users": [
+		{"name": "Alice", "age": 30},
+		{"name": "Bob", "role": "admin"}
+	],
+	"tags": ["a", "b"]
+}`
+
+func TestResultToToon_FullDocument(t *testing.T) {
+	result, err := ResultToToon(gjson.Parse(sample))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "service: api\nstats:\n  requests: 12\n  id: 9007199254740993\nusers[2]{name,age,role}:\n  Alice,30,\n  Bob,,admin\ntags:\n  - a\n  - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestResultToToon_MissingFields(t *testing.T) {
+	result, err := ResultToToon(gjson.Get(sample, "users"), totoon.WithMissingCell("-"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "[2]{name,age,role}:\n  Alice,30,-\n  Bob,-,admin"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestGetToon_Fragment(t *testing.T) {
+	result, err := GetToon(sample, "users.#.name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "- Alice\n- Bob" {
+		t.Errorf("Expected list of names, got: %q", result)
+	}
+}
+
+func TestGetToon_Missing(t *testing.T) {
+	result, err := GetToon(sample, "nope")
+	if err != nil || result != "null" {
+		t.Errorf("Expected 'null', got: %q (%v)", result, err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package toonhcl converts HCL configuration, such as Terraform files, to
+// TOON. Expressions are evaluated without variables or functions, so a file
+// converts without its inputs or provider schemas.
+package toonhcl
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCLToToon parses an HCL body in native syntax and converts it to TOON,
+// keeping attributes and blocks in source order.
+//
+// A block becomes an object nested under its type and then each of its
+// labels, so `resource "aws_instance" "web" { ... }` is written as
+// resource.aws_instance.web. Repeated blocks at the same place (several
+// ingress blocks, say) become a table. Attributes holding literal values are
+// written as values, numbers kept exact; attributes that need evaluation
+// (references, function calls) are written as their source expression.
+func HCLToToon(src []byte, opts ...totoon.Option) (string, error) {
+	file, diags := hclsyntax.ParseConfig(src, "input.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", errors.New("toonhcl: " + diags.Error())
+	}
+
+	root := convertBody(file.Body.(*hclsyntax.Body), src)
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := writeValue(w, root); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// object is an ordered map built while walking a body
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]interface{})}
+}
+
+func (o *object) set(key string, v interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+// child returns the object stored under key, creating it if needed
+func (o *object) child(key string) *object {
+	if c, ok := o.values[key].(*object); ok {
+		return c
+	}
+	c := newObject()
+	o.set(key, c)
+	return c
+}
+
+// add stores a block body under key, turning a repeated key into a list
+func (o *object) add(key string, body *object) {
+	switch existing := o.values[key].(type) {
+	case nil:
+		o.set(key, body)
+	case []interface{}:
+		o.values[key] = append(existing, body)
+	default:
+		o.values[key] = []interface{}{existing, body}
+	}
+}
+
+func convertBody(body *hclsyntax.Body, src []byte) *object {
+	type item struct {
+		offset int
+		attr   *hclsyntax.Attribute
+		block  *hclsyntax.Block
+	}
+	var items []item
+	for _, attr := range body.Attributes {
+		items = append(items, item{offset: attr.SrcRange.Start.Byte, attr: attr})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, item{offset: block.TypeRange.Start.Byte, block: block})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].offset < items[j].offset })
+
+	obj := newObject()
+	for _, it := range items {
+		if it.attr != nil {
+			obj.set(it.attr.Name, convertExpr(it.attr.Expr, src))
+			continue
+		}
+		parent, key := obj, it.block.Type
+		for _, label := range it.block.Labels {
+			parent, key = parent.child(key), label
+		}
+		parent.add(key, convertBody(it.block.Body, src))
+	}
+	return obj
+}
+
+func convertExpr(expr hclsyntax.Expression, src []byte) interface{} {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		rng := expr.Range()
+		return string(src[rng.Start.Byte:rng.End.Byte])
+	}
+	return convertValue(value)
+}
+
+func convertValue(v cty.Value) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Bool:
+		return v.True()
+	case t == cty.Number:
+		return totoon.RawMessage(v.AsBigFloat().Text('f', -1))
+	case t.IsObjectType() || t.IsMapType():
+		obj := newObject()
+		for it := v.ElementIterator(); it.Next(); {
+			k, elem := it.Element()
+			obj.set(k.AsString(), convertValue(elem))
+		}
+		return obj
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		list := make([]interface{}, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			list = append(list, convertValue(elem))
+		}
+		return list
+	}
+	return v.GoString()
+}
+
+func writeValue(w *totoon.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case *object:
+		if err := w.BeginObject(); err != nil {
+			return err
+		}
+		for _, k := range val.keys {
+			if err := w.Key(k); err != nil {
+				return err
+			}
+			if err := writeValue(w, val.values[k]); err != nil {
+				return err
+			}
+		}
+		return w.EndObject()
+	case []interface{}:
+		if isTable(val) {
+			return writeTable(w, val)
+		}
+		if err := w.BeginList(); err != nil {
+			return err
+		}
+		for _, item := range val {
+			if err := writeValue(w, item); err != nil {
+				return err
+			}
+		}
+		return w.EndList()
+	}
+	return w.Scalar(v)
+}
+
+// writeTable writes a list of objects as totoon.ToToon writes one, with
+// columns in order of first appearance
+func writeTable(w *totoon.Writer, list []interface{}) error {
+	rows := make([]*totoon.OrderedMap, len(list))
+	for i, item := range list {
+		obj := item.(*object)
+		row := totoon.NewOrderedMap()
+		for _, k := range obj.keys {
+			row.Set(k, generic(obj.values[k]))
+		}
+		rows[i] = row
+	}
+	return w.Objects(rows)
+}
+
+func isTable(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		obj, ok := item.(*object)
+		if !ok || len(obj.keys) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// generic converts ordered objects into the map form used for table cells
+func generic(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *object:
+		m := make(map[string]interface{}, len(val.keys))
+		for _, k := range val.keys {
+			m[k] = generic(val.values[k])
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			list[i] = generic(item)
+		}
+		return list
+	}
+	return v
+}
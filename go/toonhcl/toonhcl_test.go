@@ -0,0 +1,97 @@
+package toonhcl
+
+import (
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+)
+
+func TestHCLToToon_Blocks(t *testing.T) {
+	src := []byte(`
+region = "eu-west-1"
+
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+  count         = 2
+  subnet_id     = var.subnet
+}
+`)
+	result, err := HCLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `region: eu-west-1
+resource:
+  aws_instance:
+    web:
+      ami: ami-123
+      instance_type: t3.micro
+      count: 2
+      subnet_id: var.subnet`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestHCLToToon_RepeatedBlocks(t *testing.T) {
+	src := []byte(`
+security_group {
+  ingress {
+    port = 80
+  }
+  ingress {
+    port = 443
+    cidr = "10.0.0.0/8"
+  }
+}
+`)
+	result, err := HCLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "security_group:\n  ingress[2]{port,cidr}:\n  80,\n  443,10.0.0.0/8"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHCLToToon_MissingFields(t *testing.T) {
+	src := []byte(`
+ingress {
+  port = 80
+}
+ingress {
+  port = 443
+  cidr = "10.0.0.0/8"
+}
+`)
+	result, err := HCLToToon(src, totoon.WithMissingCell("-"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "ingress[2]{port,cidr}:\n  80,-\n  443,10.0.0.0/8"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHCLToToon_LiteralCollections(t *testing.T) {
+	src := []byte(`
+tags  = ["a", "b"]
+ratio = 0.1
+`)
+	result, err := HCLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "tags:\n  - a\n  - b\nratio: 0.1"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHCLToToon_InvalidSyntax(t *testing.T) {
+	if _, err := HCLToToon([]byte(`resource {`)); err == nil {
+		t.Errorf("Expected error for invalid HCL")
+	}
+}
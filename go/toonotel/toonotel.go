@@ -0,0 +1,149 @@
+// Package toonotel converts OpenTelemetry spans and attributes to TOON, so
+// tracing data can be attached to prompts compactly. Spans are taken as the
+// ReadOnlySpan values the SDK hands to exporters and span processors.
+package toonotel
+
+import (
+	"bytes"
+	"time"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// AttributesToToon converts attributes to a TOON object, keeping their order.
+// Slice attributes become lists.
+func AttributesToToon(attrs []attribute.KeyValue, opts ...totoon.Option) (string, error) {
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := writeAttributes(w, attrs); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SpansToToon converts finished spans, such as those handed to a
+// SpanExporter, to a TOON list with one object per span. Each span carries
+// its identifiers, kind, start time, duration, status and attributes; its
+// events are written as an events table with name and time columns followed
+// by the event attributes.
+func SpansToToon(spans []sdktrace.ReadOnlySpan, opts ...totoon.Option) (string, error) {
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := w.BeginList(); err != nil {
+		return "", err
+	}
+	for _, span := range spans {
+		if err := writeSpan(w, span); err != nil {
+			return "", err
+		}
+	}
+	if err := w.EndList(); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeSpan(w *totoon.Writer, span sdktrace.ReadOnlySpan) error {
+	w.BeginObject()
+	writeField(w, "name", span.Name())
+	writeField(w, "trace_id", span.SpanContext().TraceID().String())
+	writeField(w, "span_id", span.SpanContext().SpanID().String())
+	if parent := span.Parent(); parent.HasSpanID() {
+		writeField(w, "parent_span_id", parent.SpanID().String())
+	}
+	writeField(w, "kind", span.SpanKind().String())
+	writeField(w, "start", formatTime(span.StartTime()))
+	writeField(w, "duration", span.EndTime().Sub(span.StartTime()).String())
+
+	status := span.Status()
+	writeField(w, "status", status.Code.String())
+	if status.Description != "" {
+		writeField(w, "status_message", status.Description)
+	}
+
+	if attrs := span.Attributes(); len(attrs) > 0 {
+		w.Key("attributes")
+		writeAttributes(w, attrs)
+	}
+	if events := span.Events(); len(events) > 0 {
+		w.Key("events")
+		writeEvents(w, events)
+	}
+	return w.EndObject()
+}
+
+func writeField(w *totoon.Writer, key string, v interface{}) {
+	w.Key(key)
+	w.Scalar(v)
+}
+
+func writeAttributes(w *totoon.Writer, attrs []attribute.KeyValue) error {
+	w.BeginObject()
+	for _, kv := range attrs {
+		w.Key(string(kv.Key))
+		if list, ok := attributeValue(kv.Value).([]interface{}); ok {
+			w.BeginList()
+			for _, item := range list {
+				w.Scalar(item)
+			}
+			w.EndList()
+			continue
+		}
+		w.Scalar(attributeValue(kv.Value))
+	}
+	return w.EndObject()
+}
+
+// writeEvents writes span events as a table of their name, time and
+// attributes, whose columns follow the order in which keys first appear
+func writeEvents(w *totoon.Writer, events []sdktrace.Event) error {
+	rows := make([]*totoon.OrderedMap, len(events))
+	for i, event := range events {
+		row := totoon.NewOrderedMap()
+		row.Set("name", event.Name)
+		row.Set("time", formatTime(event.Time))
+		for _, kv := range event.Attributes {
+			if key := string(kv.Key); key != "name" && key != "time" {
+				row.Set(key, attributeValue(kv.Value))
+			}
+		}
+		rows[i] = row
+	}
+	return w.Objects(rows)
+}
+
+// attributeValue converts an attribute value to a scalar, or to a list for
+// the slice types
+func attributeValue(v attribute.Value) interface{} {
+	switch v.Type() {
+	case attribute.BOOLSLICE:
+		return toList(v.AsBoolSlice())
+	case attribute.INT64SLICE:
+		return toList(v.AsInt64Slice())
+	case attribute.FLOAT64SLICE:
+		return toList(v.AsFloat64Slice())
+	case attribute.STRINGSLICE:
+		return toList(v.AsStringSlice())
+	}
+	return v.AsInterface()
+}
+
+func toList[T any](values []T) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
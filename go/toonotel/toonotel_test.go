@@ -0,0 +1,93 @@
+package toonotel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAttributesToToon(t *testing.T) {
+	result, err := AttributesToToon([]attribute.KeyValue{
+		attribute.String("http.method", "GET"),
+		attribute.Int("http.status_code", 503),
+		attribute.StringSlice("tags", []string{"a", "b"}),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "http.method: GET\nhttp.status_code: 503\ntags:\n  - a\n  - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestSpansToToon(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	stub := tracetest.SpanStub{
+		Name: "GET /users",
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}),
+		SpanKind:   trace.SpanKindServer,
+		StartTime:  start,
+		EndTime:    start.Add(250 * time.Millisecond),
+		Status:     sdktrace.Status{Code: codes.Error, Description: "upstream down"},
+		Attributes: []attribute.KeyValue{attribute.Int("http.status_code", 503)},
+		Events: []sdktrace.Event{
+			{Name: "retry", Time: start.Add(100 * time.Millisecond), Attributes: []attribute.KeyValue{attribute.Int("attempt", 1)}},
+			{Name: "timeout", Time: start.Add(200 * time.Millisecond)},
+		},
+	}
+
+	result, err := SpansToToon([]sdktrace.ReadOnlySpan{stub.Snapshot()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `- name: GET /users
+  trace_id: 0102030405060708090a0b0c0d0e0f10
+  span_id: 0102030405060708
+  kind: server
+  start: 2024-05-01T12:00:00Z
+  duration: 250ms
+  status: Error
+  status_message: upstream down
+  attributes:
+    http.status_code: 503
+  events[2]{name,time,attempt}:
+  retry,"2024-05-01T12:00:00.1Z",1
+  timeout,"2024-05-01T12:00:00.2Z",`
+	if result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestSpansToToon_MissingAttributes(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stub := tracetest.SpanStub{
+		Name:      "job",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Events: []sdktrace.Event{
+			{Name: "retry", Time: start, Attributes: []attribute.KeyValue{attribute.Int("attempt", 1)}},
+			{Name: "done", Time: start},
+		},
+	}
+	result, err := SpansToToon([]sdktrace.ReadOnlySpan{stub.Snapshot()}, totoon.WithMissingCell("-"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "  events[2]{name,time,attempt}:\n  retry,\"2024-05-01T12:00:00Z\",1\n  done,\"2024-05-01T12:00:00Z\",-"
+	if !strings.HasSuffix(result, expected) {
+		t.Errorf("Expected a suffix %q, got: %q", expected, result)
+	}
+}
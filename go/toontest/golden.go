@@ -0,0 +1,58 @@
+package toontest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+)
+
+// update is the -toontest.update flag of test binaries that import this
+// package. It is namespaced so that it does not clash with an -update flag
+// the package under test defines for its own golden files.
+var update = flag.Bool("toontest.update", false, "rewrite toontest golden files")
+
+// goldenDir is where golden files are kept, relative to the package under
+// test
+var goldenDir = "testdata"
+
+// Golden compares the canonical TOON encoding of v with the golden file
+// testdata/<name>.toon and reports a test error with a line diff when they
+// differ. Running the tests with -toontest.update writes the file instead,
+// creating testdata if needed. Since both sides are canonical encodings, map
+// order and numeric types of v do not cause spurious differences.
+func Golden(t testing.TB, name string, v interface{}) {
+	t.Helper()
+	got, err := totoon.CanonicalBytes(v)
+	if err != nil {
+		t.Errorf("toontest: cannot encode %s: %v", name, err)
+		return
+	}
+	path := filepath.Join(goldenDir, name+".toon")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("toontest: %v", err)
+		}
+		if err := os.WriteFile(path, append(got, '\n'), 0o644); err != nil {
+			t.Fatalf("toontest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Errorf("toontest: golden file %s does not exist, run the tests with -toontest.update to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("toontest: %v", err)
+	}
+	want = bytes.TrimSuffix(want, []byte("\n"))
+	if !bytes.Equal(want, got) {
+		t.Errorf("toontest: %s differs from %s (-want +got):\n%s", name, path, diffLines(string(want), string(got)))
+	}
+}
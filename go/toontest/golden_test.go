@@ -0,0 +1,63 @@
+package toontest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// an -update flag of the package under test must not clash with the one of
+// toontest, which would panic when the test binary starts
+var _ = flag.Bool("update", false, "rewrite the golden files of the package")
+
+func useGoldenDir(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "testdata")
+	previous := goldenDir
+	goldenDir = dir
+	t.Cleanup(func() { goldenDir = previous })
+	return dir
+}
+
+func setUpdate(t *testing.T, value bool) {
+	previous := *update
+	*update = value
+	t.Cleanup(func() { *update = previous })
+}
+
+func TestGolden(t *testing.T) {
+	dir := useGoldenDir(t)
+	payload := map[string]interface{}{"b": []interface{}{1, 2}, "a": "x"}
+
+	setUpdate(t, true)
+	Golden(t, "payload", payload)
+	data, err := os.ReadFile(filepath.Join(dir, "payload.toon"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "a: x\nb:\n  - 1\n  - 2\n"; string(data) != expected {
+		t.Errorf("Expected %q, got: %q", expected, data)
+	}
+
+	setUpdate(t, false)
+	r := &recorder{TB: t}
+	Golden(r, "payload", map[string]interface{}{"a": "x", "b": []float64{1, 2}})
+	if len(r.errors) != 0 {
+		t.Errorf("Expected no differences, got: %v", r.errors)
+	}
+
+	Golden(r, "payload", map[string]interface{}{"a": "y", "b": []interface{}{1, 2}})
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "- a: x\n+ a: y\n") {
+		t.Errorf("Expected a diff, got: %v", r.errors)
+	}
+}
+
+func TestGolden_Missing(t *testing.T) {
+	useGoldenDir(t)
+	r := &recorder{TB: t}
+	Golden(r, "absent", 1)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "run the tests with -toontest.update") {
+		t.Errorf("Expected a missing file error, got: %v", r.errors)
+	}
+}
@@ -0,0 +1,78 @@
+// Package toontest provides assertion helpers for tests that compare values
+// through their TOON encoding.
+package toontest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+)
+
+// AssertEqual reports a test error unless want and got are structurally
+// equal, and returns whether they are. Values are compared through their
+// canonical TOON encoding (see totoon.CanonicalBytes), so map order and
+// numeric types do not matter: int 1 and float64 1 are equal, as are a
+// struct and the map of its JSON fields. The failure message is a line
+// diff of the two encodings.
+func AssertEqual(t testing.TB, want, got interface{}) bool {
+	t.Helper()
+	wantDoc, err := totoon.CanonicalBytes(want)
+	if err != nil {
+		t.Errorf("toontest: cannot encode want: %v", err)
+		return false
+	}
+	gotDoc, err := totoon.CanonicalBytes(got)
+	if err != nil {
+		t.Errorf("toontest: cannot encode got: %v", err)
+		return false
+	}
+	if bytes.Equal(wantDoc, gotDoc) {
+		return true
+	}
+	t.Errorf("toontest: values differ (-want +got):\n%s", diffLines(string(wantDoc), string(gotDoc)))
+	return false
+}
+
+// diffLines compares want and got line by line, keeping the lines of their
+// longest common subsequence and marking the others with - and +, so a line
+// added or removed does not shift every line after it
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	// common[i][j] is the length of the longest common subsequence of
+	// wantLines[i:] and gotLines[j:]
+	common := make([][]int, len(wantLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(gotLines)+1)
+	}
+	for i := len(wantLines) - 1; i >= 0; i-- {
+		for j := len(gotLines) - 1; j >= 0; j-- {
+			if wantLines[i] == gotLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(wantLines) || j < len(gotLines) {
+		switch {
+		case i < len(wantLines) && j < len(gotLines) && wantLines[i] == gotLines[j]:
+			fmt.Fprintf(&b, "  %s\n", wantLines[i])
+			i, j = i+1, j+1
+		case j == len(gotLines) || i < len(wantLines) && common[i+1][j] >= common[i][j+1]:
+			fmt.Fprintf(&b, "- %s\n", wantLines[i])
+			i++
+		default:
+			fmt.Fprintf(&b, "+ %s\n", gotLines[j])
+			j++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package toontest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recorder captures the errors reported by the helpers
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqual_Structural(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	r := &recorder{TB: t}
+	ok := AssertEqual(r,
+		map[string]interface{}{"users": []interface{}{map[string]interface{}{"name": "Alice", "id": 1.0}}},
+		map[string]interface{}{"users": []user{{ID: 1, Name: "Alice"}}},
+	)
+	if !ok || len(r.errors) != 0 {
+		t.Errorf("Expected the values to be equal, got: %v", r.errors)
+	}
+}
+
+func TestAssertEqual_Diff(t *testing.T) {
+	r := &recorder{TB: t}
+	ok := AssertEqual(r,
+		map[string]interface{}{"a": 1, "b": "x"},
+		map[string]interface{}{"a": 1, "b": "y", "c": true},
+	)
+	if ok || len(r.errors) != 1 {
+		t.Fatalf("Expected one error, got: %v", r.errors)
+	}
+	expected := "toontest: values differ (-want +got):\n  a: 1\n- b: x\n+ b: y\n+ c: true\n"
+	if r.errors[0] != expected {
+		t.Errorf("Expected %q, got: %q", expected, r.errors[0])
+	}
+}
+
+func TestAssertEqual_DiffInsertedLine(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertEqual(r,
+		map[string]interface{}{"a": 1, "c": 3, "d": 4},
+		map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4},
+	)
+	expected := "toontest: values differ (-want +got):\n  a: 1\n+ b: 2\n  c: 3\n  d: 4\n"
+	if len(r.errors) != 1 || r.errors[0] != expected {
+		t.Errorf("Expected %q, got: %q", expected, r.errors)
+	}
+}
+
+func TestAssertEqual_Unencodable(t *testing.T) {
+	r := &recorder{TB: t}
+	if AssertEqual(r, make(chan int), 1) || len(r.errors) != 1 {
+		t.Errorf("Expected an encoding error, got: %v", r.errors)
+	}
+}
+
+func TestAssertEqual_LargeIntegers(t *testing.T) {
+	r := &recorder{TB: t}
+	if AssertEqual(r, map[string]interface{}{"id": int64(9007199254740993)}, map[string]interface{}{"id": int64(9007199254740992)}) {
+		t.Errorf("Expected distinct integers above 2^53 to differ")
+	}
+}
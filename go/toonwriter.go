@@ -0,0 +1,115 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// NewToonWriter returns a WriteCloser that accepts a JSON document and
+// writes it to dst as TOON, so code that already produces JSON can be
+// redirected without changes. The JSON is parsed as it arrives: each entry
+// of a top-level object is written as soon as it is complete, and rows and
+// list items stream like EncodeTo. Keys keep their JSON order.
+//
+// Close must be called once the document is complete; it flushes the
+// output and returns any parse or write error. A parse error also fails the
+// Write calls that follow it.
+func NewToonWriter(dst io.Writer, opts ...Option) io.WriteCloser {
+	pr, pw := io.Pipe()
+	w := &toonWriter{pw: pw, done: make(chan error, 1)}
+	e := newEncoder(opts)
+	go func() {
+		err := e.convertJSONStream(pr, dst)
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+type toonWriter struct {
+	pw     *io.PipeWriter
+	done   chan error
+	closed bool
+	err    error
+}
+
+func (w *toonWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("totoon: write to a closed ToonWriter")
+	}
+	return w.pw.Write(p)
+}
+
+func (w *toonWriter) Close() error {
+	if !w.closed {
+		w.closed = true
+		w.pw.Close()
+		w.err = <-w.done
+	}
+	return w.err
+}
+
+// convertJSONStream reads one JSON document from r and writes it to dst as
+// TOON, holding at most one top-level entry in memory
+func (e *encoder) convertJSONStream(r io.Reader, dst io.Writer) error {
+	in := &byteReader{r: r}
+	counter := &byteCounter{w: dst}
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	out := &Writer{e: e, w: e.newBufferedWriter(counter)}
+
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return errors.New("totoon: no JSON document was written")
+	}
+	if err != nil {
+		return err
+	}
+
+	if tok == json.Delim('{') {
+		entries := 0
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return err
+			}
+			key := keyTok.(string)
+			e.writeDict(map[string]interface{}{key: e.unorder(value)}, 0, out.writeRaw)
+			e.orders = nil
+			if out.err != nil {
+				return out.err
+			}
+			entries++
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		if entries == 0 {
+			out.writeRaw("{}")
+		}
+	} else {
+		value, err := decodeOrderedToken(dec, tok)
+		if err != nil {
+			return err
+		}
+		e.writeValue(e.unorder(value), 0, out.writeRaw)
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errors.New("totoon: unexpected data after the JSON document")
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	e.count(MetricBytesIn, in.n)
+	e.encoded(counter.n)
+	return nil
+}
@@ -0,0 +1,109 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToonWriter_MatchesJSONToToon(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewToonWriter(&buf)
+	doc := map[string]interface{}{
+		"name": "Alice",
+		"tags": []interface{}{"a", "b"},
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Alice"},
+			map[string]interface{}{"id": 2, "name": "Bob"},
+		},
+	}
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// encoding/json writes keys sorted, and the writer keeps JSON order
+	var data interface{}
+	b, _ := json.Marshal(doc)
+	json.Unmarshal(b, &data)
+	expected := ToToonWithOptions(data, withSortedKeys())
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestToonWriter_KeepsKeyOrder(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewToonWriter(&buf)
+	for _, part := range []string{`{"z": 1, "a": {"y"`, `: true, "b": null}, "rows": [{"id": 1}`, `, {"id": 2}]}`} {
+		if _, err := w.Write([]byte(part)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "z: 1\na:\n  y: true\n  b: null\nrows[2]{id}:\n  1\n  2"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestToonWriter_KeepsNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewToonWriter(&buf)
+	if _, err := w.Write([]byte(`{"id": 9007199254740993, "price": 10.10}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "id: 9007199254740993\nprice: 10.10"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestToonWriter_Scalars(t *testing.T) {
+	tests := map[string]string{
+		`{}`:     "{}",
+		`[]`:     "[]",
+		`"x"`:    "x",
+		`[1, 2]`: "- 1\n- 2",
+	}
+	for input, expected := range tests {
+		var buf bytes.Buffer
+		w := NewToonWriter(&buf)
+		w.Write([]byte(input))
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%s: Expected %q, got: %q", input, expected, buf.String())
+		}
+	}
+}
+
+func TestToonWriter_Errors(t *testing.T) {
+	tests := map[string]string{
+		``:           "no JSON document",
+		`{"a": }`:    "",
+		`{"a": 1`:    "",
+		`{"a": 1} 2`: "unexpected data",
+	}
+	// messages from encoding/json are only checked for being errors
+	for input, message := range tests {
+		w := NewToonWriter(&bytes.Buffer{})
+		w.Write([]byte(input))
+		err := w.Close()
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected an error containing %q, got: %v", input, message, err)
+		}
+		if _, err := w.Write([]byte("{}")); err == nil {
+			t.Errorf("%q: expected an error writing after Close", input)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package toonxlsx converts the sheets of XLSX workbooks to TOON tables,
+// typing their columns as totoon.FromCSVReader types CSV columns.
+package toonxlsx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"github.com/xuri/excelize/v2"
+)
+
+// SheetToToon reads a sheet of the XLSX file at path and converts it to a
+// root TOON table. The first row holds the column names unless
+// totoon.WithCSVHeader is given, and column types are inferred the same way
+// as totoon.FromCSVReader does for CSV input. An empty sheet name selects the
+// first sheet of the workbook. Cells are read with their display formatting,
+// so dates appear as shown in the spreadsheet.
+func SheetToToon(path, sheet string, opts ...totoon.Option) (string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return "", errors.New("toonxlsx: workbook has no sheets")
+		}
+		sheet = sheets[0]
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return "", fmt.Errorf("toonxlsx: sheet %q: %w", sheet, err)
+	}
+
+	// Rows are handed to the CSV converter so both inputs share one type
+	// inference implementation
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	r := csv.NewReader(&buf)
+	r.FieldsPerRecord = -1
+	return totoon.FromCSVReader(r, opts...)
+}
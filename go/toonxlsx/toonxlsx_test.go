@@ -0,0 +1,67 @@
+package toonxlsx
+
+import (
+	"path/filepath"
+	"testing"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"github.com/xuri/excelize/v2"
+)
+
+func writeWorkbook(t *testing.T, sheet string, rows [][]interface{}) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if sheet != "Sheet1" {
+		f.SetSheetName("Sheet1", sheet)
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "data.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestSheetToToon(t *testing.T) {
+	path := writeWorkbook(t, "Sales", [][]interface{}{
+		{"region", "units", "price"},
+		{"north", 12, 2.5},
+		{"south", 7, 3},
+	})
+	result, err := SheetToToon(path, "Sales")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[2]{region,units,price}:\n  north,12,2.5\n  south,7,3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestSheetToToon_FirstSheetAndHeaderOverride(t *testing.T) {
+	path := writeWorkbook(t, "Sheet1", [][]interface{}{
+		{1, "Alice"},
+		{2, "Bob"},
+	})
+	result, err := SheetToToon(path, "", totoon.WithCSVHeader("id", "name"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[2]{id,name}:\n  1,Alice\n  2,Bob"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestSheetToToon_MissingSheet(t *testing.T) {
+	path := writeWorkbook(t, "Sheet1", [][]interface{}{{"a"}, {1}})
+	if _, err := SheetToToon(path, "Nope"); err == nil {
+		t.Errorf("Expected error for missing sheet")
+	}
+}
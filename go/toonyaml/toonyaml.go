@@ -0,0 +1,158 @@
+// Package toonyaml converts YAML documents to TOON directly from yaml.v3
+// node trees, preserving the key order of the source document. Anchors and
+// aliases are resolved, so an aliased value is written out at each place
+// that refers to it.
+package toonyaml
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLToToon parses a YAML document and converts it to TOON, keeping keys in
+// the order they appear in src. Comments are dropped, as NodeToToon drops
+// them.
+func YAMLToToon(src []byte, opts ...totoon.Option) (string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(src, &node); err != nil {
+		return "", err
+	}
+	return NodeToToon(&node, opts...)
+}
+
+// NodeToToon converts a yaml.v3 node tree to TOON. Mapping keys keep their
+// source order and sequences of mappings become tables whose columns follow
+// the order in which fields first appear. Aliases are resolved. TOON has no
+// comment syntax, so YAML comments are dropped.
+func NodeToToon(node *yaml.Node, opts ...totoon.Option) (string, error) {
+	var buf bytes.Buffer
+	w := totoon.NewWriter(&buf, opts...)
+	if err := writeNode(w, node); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeNode(w *totoon.Writer, node *yaml.Node) error {
+	node = resolve(node)
+	if node == nil {
+		return w.Scalar(nil)
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if err := w.BeginObject(); err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := writeEntry(w, node.Content[i].Value, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		return w.EndObject()
+	case yaml.SequenceNode:
+		if isTable(node) {
+			return writeTable(w, node)
+		}
+		if err := w.BeginList(); err != nil {
+			return err
+		}
+		for _, item := range node.Content {
+			if err := writeNode(w, item); err != nil {
+				return err
+			}
+		}
+		return w.EndList()
+	case yaml.ScalarNode:
+		value, err := decodeValue(node)
+		if err != nil {
+			return err
+		}
+		return w.Scalar(value)
+	}
+	return fmt.Errorf("toonyaml: unsupported node kind %d at line %d", node.Kind, node.Line)
+}
+
+func writeEntry(w *totoon.Writer, key string, value *yaml.Node) error {
+	if err := w.Key(key); err != nil {
+		return err
+	}
+	return writeNode(w, value)
+}
+
+// writeTable writes a sequence of mappings, aliases resolved, as a table
+// whose cells hold the values yaml.v3 decodes from their nodes
+func writeTable(w *totoon.Writer, node *yaml.Node) error {
+	rows := make([]*totoon.OrderedMap, len(node.Content))
+	for r, item := range node.Content {
+		item = resolve(item)
+		row := totoon.NewOrderedMap()
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			value, err := decodeValue(item.Content[i+1])
+			if err != nil {
+				return err
+			}
+			row.Set(item.Content[i].Value, value)
+		}
+		rows[r] = row
+	}
+	return w.Objects(rows)
+}
+
+// isTable reports whether a sequence holds only (non-empty) mappings
+func isTable(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		item = resolve(item)
+		if item == nil || item.Kind != yaml.MappingNode || len(item.Content) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeValue converts a node into the generic Go value yaml.v3 would decode
+func decodeValue(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	if err := resolve(node).Decode(&value); err != nil {
+		return nil, fmt.Errorf("toonyaml: line %d: %w", node.Line, err)
+	}
+	return value, nil
+}
+
+// resolve unwraps document nodes and aliases
+func resolve(node *yaml.Node) *yaml.Node {
+	for node != nil {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yaml.AliasNode:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// ConvertFS is totoon.ConvertFS with YAMLToToon registered for ".yaml" and
+// ".yml" files, so a tree of JSON and YAML documents converts in one call
+func ConvertFS(src fs.FS, dst totoon.WriterFS, opts ...totoon.Option) error {
+	opts = append([]totoon.Option{
+		totoon.WithFileConverter(".yaml", YAMLToToon),
+		totoon.WithFileConverter(".yml", YAMLToToon),
+	}, opts...)
+	return totoon.ConvertFS(src, dst, opts...)
+}
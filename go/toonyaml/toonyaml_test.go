@@ -0,0 +1,142 @@
+package toonyaml
+
+import (
+	"testing"
+	"testing/fstest"
+
+	totoon "github.com/bug4fix/totoon/go"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLToToon_PreservesOrder(t *testing.T) {
+	src := []byte(`
+zone: eu-west
+name: api
+replicas: 3
+labels:
+  tier: backend
+  app: api
+`)
+	result, err := YAMLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "zone: eu-west\nname: api\nreplicas: 3\nlabels:\n  tier: backend\n  app: api"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestYAMLToToon_DropsComments(t *testing.T) {
+	src := []byte(`
+# deployment settings
+name: api # the service name
+# scaled by the autoscaler
+replicas: 3
+`)
+	result, err := YAMLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "name: api\nreplicas: 3"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestYAMLToToon_SequenceOfMappings(t *testing.T) {
+	src := []byte(`
+users:
+  - name: Alice
+    age: 30
+  - name: Bob
+    active: false
+tags: [a, b]
+`)
+	result, err := YAMLToToon(src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "users[2]{name,age,active}:\n  Alice,30,\n  Bob,,false\ntags:\n  - a\n  - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestYAMLToToon_MissingFields(t *testing.T) {
+	src := []byte(`
+users:
+  - name: Alice
+    age: 30
+  - name: Bob
+`)
+	result, err := YAMLToToon(src, totoon.WithMissingCell("-"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "users[2]{name,age}:\n  Alice,30\n  Bob,-"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	result, err = YAMLToToon(src, totoon.WithTableMaxMissingFieldRatio(0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "users:\n  - name: Alice\n    age: 30\n  - name: Bob"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestNodeToToon_Aliases(t *testing.T) {
+	src := []byte(`
+base: &base
+  retries: 3
+service: *base
+`)
+	var node yaml.Node
+	if err := yaml.Unmarshal(src, &node); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := NodeToToon(&node)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "base:\n  retries: 3\nservice:\n  retries: 3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestYAMLToToon_InvalidYAML(t *testing.T) {
+	if _, err := YAMLToToon([]byte("a: [")); err == nil {
+		t.Errorf("Expected error for invalid YAML")
+	}
+}
+
+type mapWriterFS map[string]string
+
+func (m mapWriterFS) WriteFile(name string, data []byte) error {
+	m[name] = string(data)
+	return nil
+}
+
+func TestConvertFS_JSONAndYAML(t *testing.T) {
+	src := fstest.MapFS{
+		"a.json":    {Data: []byte(`{"name": "api"}`)},
+		"b.yaml":    {Data: []byte("zone: eu\nname: web\n")},
+		"sub/c.yml": {Data: []byte("- 1\n- 2\n")},
+	}
+	dst := mapWriterFS{}
+	if err := ConvertFS(src, dst); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"a.toon":     "name: api\n",
+		"b.toon":     "zone: eu\nname: web\n",
+		"sub/c.toon": "- 1\n- 2\n",
+	}
+	for name, content := range expected {
+		if dst[name] != content {
+			t.Errorf("%s: Expected %q, got: %q", name, content, dst[name])
+		}
+	}
+}
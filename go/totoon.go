@@ -2,7 +2,10 @@ package totoon
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 )
 
@@ -11,24 +14,50 @@ type ToonValue interface{}
 
 // ToToon converts a Go value to TOON format string
 func ToToon(data ToonValue) string {
-	return toToon(data, 2, 0)
+	return newEncoder(nil).encode(data)
 }
 
 // ToToonWithIndent converts a Go value to TOON format with custom indentation
 func ToToonWithIndent(data ToonValue, indent int) string {
-	return toToon(data, indent, 0)
+	return newEncoder([]Option{WithIndent(indent)}).encode(data)
 }
 
-// JSONToToon converts JSON string to TOON format
+// ToToonWithOptions converts a Go value to TOON format using the given options
+func ToToonWithOptions(data ToonValue, opts ...Option) string {
+	return newEncoder(opts).encode(data)
+}
+
+// JSONToToon converts JSON string to TOON format. Numbers are written as
+// they appear in the JSON, so large integers keep every digit.
 func JSONToToon(jsonStr string) (string, error) {
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+	e := newEncoder(nil)
+	e.count(MetricBytesIn, len(jsonStr))
+	data, err := decodeJSON(strings.NewReader(jsonStr))
+	if err != nil {
 		return "", err
 	}
-	return ToToon(data), nil
+	return e.encode(data), nil
+}
+
+// decodeJSON decodes a single JSON document from r, keeping its numbers as
+// json.Number
+func decodeJSON(r io.Reader) (interface{}, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("totoon: unexpected data after the JSON document")
+	}
+	return data, nil
 }
 
-func toToon(data ToonValue, indent int, level int) string {
+func (e *encoder) toToon(data ToonValue, level int) string {
 	if data == nil {
 		return "null"
 	}
@@ -39,45 +68,86 @@ func toToon(data ToonValue, indent int, level int) string {
 			return "true"
 		}
 		return "false"
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return fmt.Sprintf("%v", v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return e.formatNumber(v)
 	case string:
-		return escapeString(v)
+		return e.escapeString(v)
+	case *Value:
+		return e.toToon(v.Interface(), level)
+	case RawMessage:
+		return strings.TrimRight(string(v), "\n")
+	case map[string]RawMessage, map[string]json.RawMessage:
+		return e.dictToToon(e.rawMapToDict(v), level)
 	case []interface{}:
-		return listToToon(v, indent, level)
+		return e.rootListToToon(v, level)
 	case map[string]interface{}:
-		return dictToToon(v, indent, level)
+		return e.dictToToon(v, level)
 	case []map[string]interface{}:
 		// Convert to []interface{} for processing
 		list := make([]interface{}, len(v))
 		for i, item := range v {
 			list[i] = item
 		}
-		return listToToon(list, indent, level)
+		return e.rootListToToon(list, level)
 	default:
-		// Try to convert to JSON and back to handle custom types
-		jsonBytes, err := json.Marshal(data)
-		if err != nil {
-			return fmt.Sprintf("%v", data)
-		}
-		var converted interface{}
-		if err := json.Unmarshal(jsonBytes, &converted); err != nil {
-			return fmt.Sprintf("%v", data)
+		converted, ok := convertJSON(data)
+		if !ok {
+			return e.unsupported(data)
 		}
-		return toToon(converted, indent, level)
+		return e.toToon(converted, level)
+	}
+}
+
+// convertJSON handles custom types by converting them to JSON and back
+func convertJSON(data ToonValue) (interface{}, bool) {
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, false
 	}
+	var converted interface{}
+	if err := json.Unmarshal(jsonBytes, &converted); err != nil {
+		return nil, false
+	}
+	return converted, true
+}
+
+func (e *encoder) dictToToon(data map[string]interface{}, level int) string {
+	var lines lineBuffer
+	e.writeDict(data, level, lines.add)
+	return lines.String()
 }
 
-func dictToToon(data map[string]interface{}, indent int, level int) string {
+// writeDict emits the lines of an object one at a time, so nested objects
+// and tables reach the sink without being joined into a string first
+func (e *encoder) writeDict(data map[string]interface{}, level int, emit lineSink) {
 	if len(data) == 0 {
-		return "{}"
+		if !e.strict() {
+			emit("{}")
+		}
+		return
 	}
 
-	var lines []string
-	prefix := strings.Repeat(" ", indent*level)
+	prefix := strings.Repeat(" ", e.indent*level)
+
+	parentPath := e.path
+	defer func() { e.path = parentPath }()
+
+	for _, key := range e.objectKeys(data) {
+		value := data[key]
+		keyStr := e.specKey(key)
+		e.path = joinPath(parentPath, key)
 
-	for key, value := range data {
-		keyStr := key
+		if e.strict() && e.writeSpecEntry(keyStr, value, level, emit) {
+			continue
+		}
+
+		switch val := value.(type) {
+		case RawMessage:
+			emit(e.rawEntryToToon(keyStr, val, level))
+			continue
+		case map[string]RawMessage, map[string]json.RawMessage:
+			value = e.rawMapToDict(val)
+		}
 
 		// Check if value is complex
 		isComplex := false
@@ -109,58 +179,133 @@ func dictToToon(data map[string]interface{}, indent int, level int) string {
 						list[i] = item
 					}
 				}
-				lines = append(lines, listOfObjectsToToon(keyStr, list, indent, level))
+				if e.expanded {
+					e.trace("list of objects written one value per line")
+					emit(fmt.Sprintf("%s%s:", prefix, keyStr))
+					emit(e.expandedListToToon(list, level+1))
+				} else {
+					e.writeTable(keyStr, list, level, emit)
+				}
 			} else if _, ok := value.(map[string]interface{}); ok {
-				lines = append(lines, fmt.Sprintf("%s%s:", prefix, keyStr))
-				lines = append(lines, dictToToon(value.(map[string]interface{}), indent, level+1))
+				emit(fmt.Sprintf("%s%s:", prefix, keyStr))
+				e.writeDict(value.(map[string]interface{}), level+1, emit)
 			} else {
-				lines = append(lines, fmt.Sprintf("%s%s:", prefix, keyStr))
-				lines = append(lines, listToToon(value.([]interface{}), indent, level+1))
+				emit(fmt.Sprintf("%s%s:", prefix, keyStr))
+				e.writeList(value.([]interface{}), level+1, emit)
 			}
 		} else {
-			valueStr := valueToToon(value, indent, level+1)
-			lines = append(lines, fmt.Sprintf("%s%s: %s", prefix, keyStr, valueStr))
+			valueStr := e.valueToToon(e.format(value), level+1)
+			emit(fmt.Sprintf("%s%s: %s", prefix, keyStr, valueStr))
 		}
 	}
+}
 
-	return strings.Join(lines, "\n")
+func (e *encoder) listToToon(data []interface{}, level int) string {
+	var lines lineBuffer
+	e.writeList(data, level, lines.add)
+	return lines.String()
 }
 
-func listToToon(data []interface{}, indent int, level int) string {
+// writeList emits a list one item (or table row) at a time
+func (e *encoder) writeList(data []interface{}, level int, emit lineSink) {
 	if len(data) == 0 {
-		return "[]"
+		emit("[]")
+		return
 	}
 
 	// Check if it's a list of objects (use tabular format)
 	if len(data) > 0 {
 		if _, ok := data[0].(map[string]interface{}); ok {
-			return listOfObjectsToToon("", data, indent, level)
+			if e.expanded {
+				e.trace("list of objects written one value per line")
+				emit(e.expandedListToToon(data, level))
+				return
+			}
+			e.writeTable("", data, level, emit)
+			return
 		}
 	}
 
+	if e.strict() {
+		e.writeSpecItems(data, level, emit)
+		return
+	}
+
 	// Simple list
-	var lines []string
-	prefix := strings.Repeat(" ", indent*level)
-	for _, item := range data {
-		valueStr := valueToToon(item, indent, level)
-		lines = append(lines, fmt.Sprintf("%s- %s", prefix, valueStr))
+	if e.tracing() && hasObject(data) {
+		e.trace("list written item by item, not as a table, because its first item is %s", describeKind(data[0]))
+	}
+	prefix := strings.Repeat(" ", e.indent*level)
+	parentPath := e.path
+	defer func() { e.path = parentPath }()
+	for i, item := range data {
+		e.path = indexPath(parentPath, i)
+		item = e.format(item)
+		var valueStr string
+		if s, ok := item.(string); ok && e.useBlock(s) {
+			valueStr = e.blockToToon(s, level+1)
+		} else {
+			valueStr = e.valueToToon(item, level)
+		}
+		emit(fmt.Sprintf("%s- %s", prefix, valueStr))
 	}
+}
 
-	return strings.Join(lines, "\n")
+func (e *encoder) listOfObjectsToToon(key string, data []interface{}, level int) string {
+	var lines lineBuffer
+	e.writeTable(key, data, level, lines.add)
+	return lines.String()
 }
 
-func listOfObjectsToToon(key string, data []interface{}, indent int, level int) string {
+// writeTable emits the header of a table and then each row as it is
+// rendered, so only one row is held in memory on top of the input
+func (e *encoder) writeTable(key string, data []interface{}, level int, emit lineSink) {
 	if len(data) == 0 {
-		return "[]"
+		emit("[]")
+		return
 	}
 
 	// Verify first element is an object
 	if _, ok := data[0].(map[string]interface{}); !ok {
-		return listToToon(data, indent, level)
+		e.writeList(data, level, emit)
+		return
+	}
+
+	if e.rowFilter != nil {
+		if data = e.filterRows(data); len(data) == 0 {
+			e.emptyTable(key, level, emit)
+			return
+		}
+	}
+
+	if len(e.sortRows) > 0 {
+		data = e.sortedRows(data)
+	}
+
+	if e.dedup {
+		data = e.dedupRows(data)
+	}
+
+	if e.strict() && !specTabular(data) {
+		e.trace("list of objects written as list items, its rows are not uniform")
+		emit(strings.Repeat(" ", e.indent*level) + key + "[" + strconv.Itoa(len(data)) + "]:")
+		e.writeSpecItems(data, level+1, emit)
+		return
+	}
+
+	if reason, sparse := e.tableTooSparse(data); sparse {
+		e.trace("list of objects written as list items, %s", reason)
+		e.writeSparseList(key, data, level, emit)
+		return
+	}
+
+	if e.groupBy != "" && hasField(data, e.groupBy) {
+		e.trace("rows grouped by %q", e.groupBy)
+		emit(e.groupedListToToon(key, data, level))
+		return
 	}
 
-	var lines []string
-	prefix := strings.Repeat(" ", indent*level)
+	prefix := strings.Repeat(" ", e.indent*level)
 
 	// Get all unique keys from all objects, preserving order
 	allKeysMap := make(map[string]bool)
@@ -168,7 +313,7 @@ func listOfObjectsToToon(key string, data []interface{}, indent int, level int)
 
 	for _, item := range data {
 		if obj, ok := item.(map[string]interface{}); ok {
-			for k := range obj {
+			for _, k := range e.rowKeys(obj) {
 				if !allKeysMap[k] {
 					allKeysMap[k] = true
 					allKeys = append(allKeys, k)
@@ -178,125 +323,156 @@ func listOfObjectsToToon(key string, data []interface{}, indent int, level int)
 	}
 
 	if len(allKeys) == 0 {
-		return "[]"
+		emit("[]")
+		return
 	}
-
-	// Header format: key[count]{field1,field2,field3}:
-	count := len(data)
-	fields := strings.Join(allKeys, ",")
-	if key != "" {
-		lines = append(lines, fmt.Sprintf("%s%s[%d]{%s}:", prefix, key, count, fields))
-	} else {
-		lines = append(lines, fmt.Sprintf("%s[%d]{%s}:", prefix, count, fields))
+	e.orderColumns(data, allKeys)
+	e.traceTable(data, allKeys)
+	if e.transpose {
+		e.writeTransposed(key, data, allKeys, level, emit)
+		return
+	}
+	if e.isWide(allKeys) {
+		e.writeWideTable(key, data, allKeys, level, emit)
+		return
 	}
 
-	// Data rows: comma-separated values with 2 spaces indentation
-	dataPrefix := "  " // Two spaces for data rows
-	for _, item := range data {
+	// Header format: key[count]{field1,field2,field3}: (see tableHeader)
+	emit(prefix + e.tableHeader(key, len(data), allKeys))
+
+	// Data rows: delimiter-separated values with 2 spaces indentation
+	dataPrefix := e.rowPrefix(level) // two spaces for data rows, see rowPrefix
+	tablePath := e.path
+	defer func() { e.path = tablePath }()
+	for row, item := range data {
+		if footer, cut := e.rowOverflow(row, len(data)); cut {
+			emit(dataPrefix + footer)
+			break
+		}
 		obj, ok := item.(map[string]interface{})
 		if !ok {
 			continue
 		}
 
 		rowValues := make([]string, len(allKeys))
+		var subTables []string
 		for i, k := range allKeys {
-			value := ""
+			value := e.missingCell()
+			e.path = joinPath(indexPath(tablePath, row), k)
 			if v, exists := obj[k]; exists {
-				// Handle nested structures specially
-				switch val := v.(type) {
-				case []interface{}:
-					if len(val) > 0 {
-						if _, isObj := val[0].(map[string]interface{}); isObj {
-							// Array of objects: use compact inline tabular format
-							nestedKeysMap := make(map[string]bool)
-							var nestedKeys []string
-							for _, nestedItem := range val {
-								if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
-									for nk := range nestedObj {
-										if !nestedKeysMap[nk] {
-											nestedKeysMap[nk] = true
-											nestedKeys = append(nestedKeys, nk)
-										}
-									}
-								}
-							}
-							nestedFields := strings.Join(nestedKeys, ",")
-							nestedCount := len(val)
-							
-							// Build compact data rows separated by semicolons
-							var nestedRows []string
-							for _, nestedItem := range val {
-								if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
-									var nestedRowValues []string
-									for _, nk := range nestedKeys {
-										nv := ""
-										if nvVal, exists := nestedObj[nk]; exists {
-											nv = valueToToonInline(nvVal)
-											if strings.Contains(nv, ",") || strings.Contains(nv, ";") || strings.Contains(nv, ":") {
-												if strings.Contains(nv, `"`) {
-													nv = strings.ReplaceAll(nv, `"`, `\"`)
-												}
-												nv = fmt.Sprintf(`"%s"`, nv)
-											}
-										}
-										nestedRowValues = append(nestedRowValues, nv)
-									}
-									nestedRows = append(nestedRows, strings.Join(nestedRowValues, ","))
-								}
-							}
-							value = fmt.Sprintf("[%d]{%s}:%s", nestedCount, nestedFields, strings.Join(nestedRows, ";"))
-						} else {
-							// Array of primitives: use bracket notation
-							items := make([]string, len(val))
-							for j, item := range val {
-								items[j] = valueToToonInline(item)
-							}
-							value = fmt.Sprintf("[%s]", strings.Join(items, ","))
-						}
-					} else {
-						value = "[]"
-					}
-				case map[string]interface{}:
-					// Nested object: use compact key:value format (inline, no newlines)
-					var nestedItems []string
-					for nk, nv := range val {
-						nvStr := valueToToonInline(nv)
-						// Quote if contains special chars that would break the format
-						if strings.Contains(nvStr, ",") || strings.Contains(nvStr, ":") || strings.Contains(nvStr, ";") || strings.Contains(nvStr, "\n") {
-							// Escape quotes if present
-							if strings.Contains(nvStr, `"`) {
-								nvStr = strings.ReplaceAll(nvStr, `"`, `\"`)
+				v = e.format(v)
+				if rows, ok := e.subTableRows(v); ok {
+					value = fmt.Sprintf("[%d]", len(rows))
+					subTables = append(subTables, e.subTableToToon(rows, dataPrefix))
+				} else {
+					value = e.columnCellToToon(k, v)
+				}
+			}
+			rowValues[i] = value
+		}
+		row := strings.Join(rowValues, e.delimiterString())
+		emit(fmt.Sprintf("%s%s", dataPrefix, row))
+		for _, table := range subTables {
+			emit(table)
+		}
+	}
+	if footer, ok := e.summaryRow(data, allKeys); ok {
+		emit(dataPrefix + footer)
+	}
+}
+
+// cellToToon renders a single table cell. Nested lists and objects use the
+// compact inline forms and values containing delimiters are quoted.
+func (e *encoder) cellToToon(v interface{}) string {
+	value := ""
+	// Handle nested structures specially
+	switch val := v.(type) {
+	case []interface{}:
+		if e.rowFilter != nil && hasObject(val) {
+			val = e.filterRows(val)
+		}
+		if len(val) > 0 {
+			if _, isObj := val[0].(map[string]interface{}); isObj {
+				// Array of objects: use compact inline tabular format
+				nestedKeysMap := make(map[string]bool)
+				var nestedKeys []string
+				for _, nestedItem := range val {
+					if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
+						for _, nk := range e.rowKeys(nestedObj) {
+							if !nestedKeysMap[nk] {
+								nestedKeysMap[nk] = true
+								nestedKeys = append(nestedKeys, nk)
 							}
-							nvStr = fmt.Sprintf(`"%s"`, nvStr)
 						}
-						nestedItems = append(nestedItems, fmt.Sprintf("%s:%s", nk, nvStr))
 					}
-					value = fmt.Sprintf("{%s}", strings.Join(nestedItems, ","))
-				default:
-					value = valueToToon(v, 0, 0)
-					// Handle values with commas, newlines, colons, or semicolons
-					// Only quote if not already quoted and contains special chars
-					if !(strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)) {
-						if strings.Contains(value, ",") || strings.Contains(value, "\n") || strings.Contains(value, ":") || strings.Contains(value, ";") {
-							// Escape quotes if present
-							if strings.Contains(value, `"`) {
-								value = strings.ReplaceAll(value, `"`, `\"`)
+				}
+				e.orderColumns(val, nestedKeys)
+				nestedCount := len(val)
+
+				// Build compact data rows separated by semicolons (see WithNestedRowSeparator)
+				var nestedRows []string
+				for _, nestedItem := range val {
+					if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
+						var nestedRowValues []string
+						for _, nk := range nestedKeys {
+							nv := e.missingCell()
+							if nvVal, exists := nestedObj[nk]; exists {
+								nv = e.nestedCellToToon(nvVal)
 							}
-							value = fmt.Sprintf(`"%s"`, value)
+							nestedRowValues = append(nestedRowValues, nv)
 						}
+						nestedRows = append(nestedRows, strings.Join(nestedRowValues, ","))
 					}
 				}
+				value = e.inlineTableHeader(nestedCount, nestedKeys) + strings.Join(nestedRows, e.nestedRowSeparator())
+			} else {
+				// Array of primitives: use bracket notation
+				items := make([]string, len(val))
+				for j, item := range val {
+					items[j] = e.valueToToonInline(item)
+				}
+				value = fmt.Sprintf("[%s]", strings.Join(items, ","))
 			}
-			rowValues[i] = value
+		} else {
+			value = "[]"
 		}
-		row := strings.Join(rowValues, ",")
-		lines = append(lines, fmt.Sprintf("%s%s", dataPrefix, row))
+	case map[string]interface{}:
+		// Nested object: use compact key:value format (inline, no newlines)
+		var nestedItems []string
+		for _, nk := range e.objectKeys(val) {
+			nv := val[nk]
+			nvStr := e.valueToToonInline(nv)
+			// Quote if contains special chars that would break the format
+			if cellDelimiters.containsAny(nvStr) {
+				nvStr = e.quote(nvStr)
+			}
+			nestedItems = append(nestedItems, fmt.Sprintf("%s:%s", nk, nvStr))
+		}
+		value = fmt.Sprintf("{%s}", strings.Join(nestedItems, ","))
+	case string:
+		value = e.stringCell(val, e.cellSpecial().containsAny)
+	case bool:
+		value = e.boolCell(val)
+	default:
+		value = e.quoteCell(e.valueToToon(v, 0))
 	}
+	return value
+}
 
-	return strings.Join(lines, "\n")
+// quoteCell quotes a rendered cell value that contains delimiters
+func (e *encoder) quoteCell(value string) string {
+	// Handle values with commas, newlines, colons, or semicolons
+	// Only quote if not already quoted and contains special chars
+	q := string(e.quoteChar())
+	if !(strings.HasPrefix(value, q) && strings.HasSuffix(value, q)) {
+		if e.cellSpecial().containsAny(value) {
+			value = e.quote(value)
+		}
+	}
+	return value
 }
 
-func valueToToon(value ToonValue, indent int, level int) string {
+func (e *encoder) valueToToon(value ToonValue, level int) string {
 	if value == nil {
 		return "null"
 	}
@@ -307,68 +483,66 @@ func valueToToon(value ToonValue, indent int, level int) string {
 			return "true"
 		}
 		return "false"
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return fmt.Sprintf("%v", v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return e.formatNumber(v)
 	case string:
-		return escapeString(v)
+		if e.useBlock(v) {
+			return e.blockToToon(v, level)
+		}
+		return e.escapeString(v)
+	case *Value:
+		return e.valueToToon(v.Interface(), level)
+	case RawMessage:
+		return e.rawValueToToon(v, level)
 	case []interface{}:
-		return "\n" + listToToon(v, indent, level)
+		return "\n" + e.listToToon(v, level)
 	case map[string]interface{}:
-		return "\n" + dictToToon(v, indent, level)
+		return "\n" + e.dictToToon(v, level)
 	default:
 		// Try JSON conversion for custom types
 		jsonBytes, err := json.Marshal(value)
 		if err != nil {
-			return fmt.Sprintf("%v", value)
+			return e.unsupported(value)
 		}
 		var converted interface{}
 		if err := json.Unmarshal(jsonBytes, &converted); err != nil {
-			return fmt.Sprintf("%v", value)
+			return e.unsupported(value)
 		}
-		return valueToToon(converted, indent, level)
+		return e.valueToToon(converted, level)
 	}
 }
 
-func escapeString(s string) string {
+func (e *encoder) escapeString(s string) string {
+	if e.strict() {
+		return e.specString(s)
+	}
 	// Only escape actual control characters (newlines, tabs, etc.)
 	// Let the caller decide if quoting is needed for other special chars
-	needsEscaping := false
-	for _, char := range s {
-		if char == '\n' || char == '\t' || char == '\r' {
-			needsEscaping = true
-			break
+	if !controlChars.containsAny(s) {
+		if startsQuoted(s) || s == DocumentSeparator || readsAsMarker(s) || (e.canonical && readsAsLiteral(s)) {
+			return e.quote(s)
 		}
-	}
-
-	if !needsEscaping {
 		return s
 	}
+	e.unsafeValue(s)
+	e.trace("value %q quoted to escape control characters", s)
+	return escapeQuoted(s, byte(e.quoteChar()))
+}
+
+// startsQuoted reports whether s starts with a quote character, which the
+// decoder would take for the start of a quoted string
+func startsQuoted(s string) bool {
+	return s != "" && (s[0] == '"' || s[0] == '\'')
+}
 
-	// Escape control characters
-	var builder strings.Builder
-	builder.WriteRune('"')
-	for _, char := range s {
-		switch char {
-		case '\\':
-			builder.WriteString("\\\\")
-		case '"':
-			builder.WriteString("\\\"")
-		case '\n':
-			builder.WriteString("\\n")
-		case '\r':
-			builder.WriteString("\\r")
-		case '\t':
-			builder.WriteString("\\t")
-		default:
-			builder.WriteRune(char)
-		}
-	}
-	builder.WriteRune('"')
-	return builder.String()
+// readsAsMarker reports whether s, written bare as a value, would be taken
+// for the marker of a block scalar or of a raw <<TAG value
+func readsAsMarker(s string) bool {
+	return isBlockMarker(s) || heredocMarker.MatchString(s)
 }
 
 // valueToToonInline converts a value to TOON format without newlines (for inline use)
-func valueToToonInline(value ToonValue) string {
+func (e *encoder) valueToToonInline(value ToonValue) string {
 	if value == nil {
 		return "null"
 	}
@@ -379,11 +553,20 @@ func valueToToonInline(value ToonValue) string {
 			return "true"
 		}
 		return "false"
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return fmt.Sprintf("%v", v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return e.formatNumber(v)
 	case string:
-		return escapeString(v)
+		return e.escapeString(v)
+	case RawMessage:
+		raw := strings.TrimRight(string(v), "\n")
+		if strings.Contains(raw, "\n") {
+			return e.escapeString(raw)
+		}
+		return raw
 	case []interface{}:
+		if e.rowFilter != nil && hasObject(v) {
+			v = e.filterRows(v)
+		}
 		if len(v) == 0 {
 			return "[]"
 		}
@@ -394,7 +577,7 @@ func valueToToonInline(value ToonValue) string {
 			var nestedKeys []string
 			for _, nestedItem := range v {
 				if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
-					for nk := range nestedObj {
+					for _, nk := range e.rowKeys(nestedObj) {
 						if !nestedKeysMap[nk] {
 							nestedKeysMap[nk] = true
 							nestedKeys = append(nestedKeys, nk)
@@ -402,48 +585,40 @@ func valueToToonInline(value ToonValue) string {
 					}
 				}
 			}
-			nestedFields := strings.Join(nestedKeys, ",")
+			e.orderColumns(v, nestedKeys)
 			nestedCount := len(v)
-			
+
 			var nestedRows []string
 			for _, nestedItem := range v {
 				if nestedObj, ok := nestedItem.(map[string]interface{}); ok {
 					var nestedRowValues []string
 					for _, nk := range nestedKeys {
-						nv := ""
+						nv := e.missingCell()
 						if nvVal, exists := nestedObj[nk]; exists {
-							nv = valueToToonInline(nvVal)
-							if strings.Contains(nv, ",") || strings.Contains(nv, ";") || strings.Contains(nv, ":") {
-								if strings.Contains(nv, `"`) {
-									nv = strings.ReplaceAll(nv, `"`, `\"`)
-								}
-								nv = fmt.Sprintf(`"%s"`, nv)
-							}
+							nv = e.nestedCellToToon(nvVal)
 						}
 						nestedRowValues = append(nestedRowValues, nv)
 					}
 					nestedRows = append(nestedRows, strings.Join(nestedRowValues, ","))
 				}
 			}
-			return fmt.Sprintf("[%d]{%s}:%s", nestedCount, nestedFields, strings.Join(nestedRows, ";"))
+			return e.inlineTableHeader(nestedCount, nestedKeys) + strings.Join(nestedRows, e.nestedRowSeparator())
 		} else {
 			// Array of primitives: use bracket notation
 			items := make([]string, len(v))
 			for j, item := range v {
-				items[j] = valueToToonInline(item)
+				items[j] = e.valueToToonInline(item)
 			}
 			return fmt.Sprintf("[%s]", strings.Join(items, ","))
 		}
 	case map[string]interface{}:
 		// Nested object: use compact key:value format (recursive, but inline)
 		var nestedItems []string
-		for nk, nv := range v {
-			nvStr := valueToToonInline(nv)
-			if strings.Contains(nvStr, ",") || strings.Contains(nvStr, ":") || strings.Contains(nvStr, ";") || strings.Contains(nvStr, "\n") {
-				if strings.Contains(nvStr, `"`) {
-					nvStr = strings.ReplaceAll(nvStr, `"`, `\"`)
-				}
-				nvStr = fmt.Sprintf(`"%s"`, nvStr)
+		for _, nk := range e.objectKeys(v) {
+			nv := v[nk]
+			nvStr := e.valueToToonInline(nv)
+			if cellDelimiters.containsAny(nvStr) {
+				nvStr = e.quote(nvStr)
 			}
 			nestedItems = append(nestedItems, fmt.Sprintf("%s:%s", nk, nvStr))
 		}
@@ -452,13 +627,12 @@ func valueToToonInline(value ToonValue) string {
 		// Try JSON conversion for custom types
 		jsonBytes, err := json.Marshal(value)
 		if err != nil {
-			return fmt.Sprintf("%v", value)
+			return e.unsupported(value)
 		}
 		var converted interface{}
 		if err := json.Unmarshal(jsonBytes, &converted); err != nil {
-			return fmt.Sprintf("%v", value)
+			return e.unsupported(value)
 		}
-		return valueToToonInline(converted)
+		return e.valueToToonInline(converted)
 	}
 }
-
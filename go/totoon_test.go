@@ -1,6 +1,7 @@
 package totoon
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -168,6 +169,31 @@ func TestJSONToToon(t *testing.T) {
 	}
 }
 
+func TestJSONToToon_LargeNumbers(t *testing.T) {
+	jsonStr := `{"id": 9007199254740993, "rows": [{"n": 12345678901234567890}], "ratio": 1.50, "tiny": 1e-7}`
+	result, err := JSONToToon(jsonStr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "id: 9007199254740993\nratio: 1.50\nrows[1]{n}:\n  12345678901234567890\ntiny: 1e-7"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	if _, err := JSONToToon(`{"a": 1} {"b": 2}`); err == nil {
+		t.Error("Expected an error for data after the document")
+	}
+}
+
+func TestToToon_JSONNumber(t *testing.T) {
+	data := map[string]interface{}{"big": json.Number("-12345678901234567890"), "neg": json.Number("-0"), "exp": json.Number("1.5e3")}
+	result := ToToonWithOptions(data, WithDialect(DialectV2))
+	expected := "big: -12345678901234567890\nexp: 1500\nneg: 0"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
 func TestJSONToToon_Complex(t *testing.T) {
 	jsonStr := `{"users": [{"name": "Alice", "age": 30}, {"name": "Bob", "age": 25}]}`
 	result, err := JSONToToon(jsonStr)
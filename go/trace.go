@@ -0,0 +1,69 @@
+package totoon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithTrace writes a line to w for each structural decision made while
+// encoding: which lists became tables and with which columns, where columns
+// were unioned from rows with different keys, which rows were grouped,
+// expanded or left out, and which values were quoted. Lines start with the
+// path of the value they are about. It is meant for understanding
+// surprising output, not for production use.
+func WithTrace(w io.Writer) Option {
+	return func(o *options) {
+		o.traceTo = w
+	}
+}
+
+// tracing reports whether WithTrace is enabled, for callers that would
+// otherwise compute trace details for nothing
+func (e *encoder) tracing() bool {
+	return e.traceTo != nil
+}
+
+// trace writes one line about the value at the current path
+func (e *encoder) trace(format string, args ...interface{}) {
+	if e.traceTo == nil {
+		return
+	}
+	line := fmt.Sprintf(format, args...)
+	if e.path != "" {
+		line = e.path + ": " + line
+	}
+	fmt.Fprintln(e.traceTo, line)
+}
+
+// traceTable explains how a list of objects was laid out as a table
+func (e *encoder) traceTable(data []interface{}, columns []string) {
+	if !e.tracing() {
+		return
+	}
+	e.trace("list of %d items written as a table with columns %s", len(data), strings.Join(columns, ","))
+
+	partial := 0
+	for i, item := range data {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			e.trace("item %d is %s, not an object, and is left out of the table", i, describeKind(item))
+			continue
+		}
+		if len(obj) < len(columns) {
+			partial++
+		}
+	}
+	if partial > 0 {
+		e.trace("columns are the union of all rows' keys, %d of %d rows have empty cells", partial, len(data))
+	}
+}
+
+func hasObject(data []interface{}) bool {
+	for _, item := range data {
+		if _, ok := item.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
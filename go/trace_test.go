@@ -0,0 +1,52 @@
+package totoon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWithTrace(t *testing.T) {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Smith, J"},
+			map[string]interface{}{"id": 2},
+			"oops",
+		},
+		"mixed": []interface{}{1, map[string]interface{}{"a": 1}},
+		"note":  "a\nb",
+	}
+	ToToonWithOptions(data, withSortedKeys(), WithTrace(&buf))
+
+	expected := strings.Join([]string{
+		"mixed: list written item by item, not as a table, because its first item is a number",
+		`note: value "a\nb" quoted to escape control characters`,
+		"users: list of 3 items written as a table with columns id,name",
+		"users: item 2 is a string, not an object, and is left out of the table",
+		"users: columns are the union of all rows' keys, 1 of 3 rows have empty cells",
+		`users[0].name: value "Smith, J" quoted`,
+	}, "\n") + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestWithTrace_GroupedAndExpanded(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"team": "a", "id": 1},
+		map[string]interface{}{"team": "b", "id": 2},
+	}
+
+	var buf bytes.Buffer
+	ToToonWithOptions(map[string]interface{}{"rows": rows}, WithGroupBy("team"), WithTrace(&buf))
+	if !strings.HasPrefix(buf.String(), "rows: rows grouped by \"team\"\n") {
+		t.Errorf("Expected the grouping to be traced, got: %q", buf.String())
+	}
+
+	buf.Reset()
+	ToToonWithOptions(rows, WithOneValuePerLine(), WithTrace(&buf))
+	if expected := "list of objects written one value per line\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
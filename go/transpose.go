@@ -0,0 +1,53 @@
+package totoon
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithTranspose writes tables with their fields as rows and their records
+// as columns, which reads better for a few records with many fields, such
+// as records being compared side by side. The first column holds the field
+// names and the others are headed by the record's index in the list:
+//
+//	users[2]{field,0,1}:
+//	  id,1,2
+//	  name,Alice,Bob
+//
+// The row count in the header is the number of fields.
+func WithTranspose() Option {
+	return func(o *options) {
+		o.transpose = true
+	}
+}
+
+// writeTransposed emits a table of data with one row per field in columns
+func (e *encoder) writeTransposed(key string, data []interface{}, columns []string, level int, emit lineSink) {
+	prefix := strings.Repeat(" ", e.indent*level)
+	header := []string{"field"}
+	var records []int
+	for i, item := range data {
+		if _, ok := item.(map[string]interface{}); ok {
+			header = append(header, strconv.Itoa(i))
+			records = append(records, i)
+		}
+	}
+	emit(prefix + e.formatHeader(key, e.countText(len(columns)), header))
+
+	dataPrefix := e.rowPrefix(level)
+	tablePath := e.path
+	defer func() { e.path = tablePath }()
+	for _, k := range columns {
+		e.path = tablePath
+		cells := []string{e.cellToToon(e.columnLabel(k))}
+		for _, i := range records {
+			value := e.missingCell()
+			e.path = joinPath(indexPath(tablePath, i), k)
+			if v, exists := data[i].(map[string]interface{})[k]; exists {
+				value = e.columnCellToToon(k, e.format(v))
+			}
+			cells = append(cells, value)
+		}
+		emit(dataPrefix + strings.Join(cells, e.delimiterString()))
+	}
+}
@@ -0,0 +1,28 @@
+package totoon
+
+import "testing"
+
+func TestWithTranspose(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Alice", "role": "admin"},
+			map[string]interface{}{"id": 2, "name": "Smith, J"},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithTranspose())
+	expected := "users[3]{field,0,1}:\n  id,1,2\n  name,Alice,\"Smith, J\"\n  role,admin,"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithTranspose_RootAndNested(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"a": 1, "b": []interface{}{1, 2}},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithTranspose(), WithIndent(4))
+	expected := "[2]{field,0}:\n  a,1\n  b,[1,2]"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
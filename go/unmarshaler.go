@@ -0,0 +1,373 @@
+package totoon
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ToonUnmarshaler is implemented by types that parse their own TOON, as
+// json.Unmarshaler is for JSON, so domain types such as IDs, timestamps and
+// enums can read their own representation when Unmarshal or Decoder.Decode
+// stores into them. UnmarshalTOON receives the value re-encoded as a TOON
+// fragment in DialectV2: the text of a scalar, quoted where the dialect
+// requires it, or a whole document for objects and lists. It must copy the
+// data if it keeps it.
+type ToonUnmarshaler interface {
+	UnmarshalTOON([]byte) error
+}
+
+var (
+	toonUnmarshalerType = reflect.TypeOf((*ToonUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// nativeStoreCache records, by reflect.Type, whether values of the type are
+// stored by storeValue rather than through encoding/json
+var nativeStoreCache sync.Map
+
+// storesNatively reports whether t, or a type reachable from it, implements
+// ToonUnmarshaler or has toon struct tags, which encoding/json knows nothing
+// about
+func storesNatively(t reflect.Type) bool {
+	if cached, ok := nativeStoreCache.Load(t); ok {
+		return cached.(bool)
+	}
+	// only the outer result is cached: types inside a cycle are judged
+	// while the cycle is being visited
+	native := needsNativeStore(t, map[reflect.Type]bool{})
+	nativeStoreCache.Store(t, native)
+	return native
+}
+
+func needsNativeStore(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if reflect.PtrTo(t).Implements(toonUnmarshalerType) || t.Implements(toonUnmarshalerType) {
+		return true
+	}
+	if visiting[t] || decodesItself(t) {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return needsNativeStore(t.Elem(), visiting)
+	case reflect.Map:
+		return needsNativeStore(t.Elem(), visiting)
+	case reflect.Struct:
+		fields, ok := cachedFields(t)
+		if !ok {
+			return false
+		}
+		for _, f := range fields {
+			sf := t.FieldByIndex(f.index)
+			if _, tagged := sf.Tag.Lookup("toon"); tagged || f.quoted || needsNativeStore(sf.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodesItself reports whether t or *t implements json.Unmarshaler or
+// encoding.TextUnmarshaler
+func decodesItself(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return t.Implements(jsonUnmarshalerType) || t.Implements(textUnmarshalerType) ||
+		pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// storeValue stores the decoded value doc in rv, which must be settable.
+// Values that storesNatively rejects go through encoding/json. e.path is
+// the location of doc in the document.
+func (e *encoder) storeValue(doc interface{}, rv reflect.Value) error {
+	if doc == nil && rv.Kind() == reflect.Ptr {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if u, ok := toonUnmarshaler(rv); ok {
+		return u.UnmarshalTOON([]byte(fragmentOf(doc)))
+	}
+	if !storesNatively(rv.Type()) {
+		return e.storeJSON(doc, rv)
+	}
+	if doc == nil {
+		if rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice {
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+
+	parentPath := e.path
+	defer func() { e.path = parentPath }()
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return e.storeValue(doc, rv.Elem())
+	case reflect.Struct:
+		obj, ok := doc.(*OrderedMap)
+		if !ok {
+			return e.mismatch(doc, rv.Type())
+		}
+		fields, _ := cachedFields(rv.Type())
+		for _, k := range obj.keys {
+			e.path = joinPath(parentPath, k)
+			f, ok := matchField(fields, k)
+			if !ok {
+				if e.disallowUnknown {
+					return fmt.Errorf("totoon: unknown field %q", e.path)
+				}
+				continue
+			}
+			if err := e.storeValue(unquotedValue(f, obj.values[k]), settableField(rv, f.index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		list, ok := doc.([]interface{})
+		if !ok {
+			return e.mismatch(doc, rv.Type())
+		}
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), len(list), len(list)))
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(list) {
+				rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+				continue
+			}
+			e.path = indexPath(parentPath, i)
+			if err := e.storeValue(list[i], rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		obj, ok := doc.(*OrderedMap)
+		if !ok {
+			return e.mismatch(doc, rv.Type())
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), obj.Len()))
+		}
+		for _, k := range obj.keys {
+			e.path = joinPath(parentPath, k)
+			key, err := e.parseMapKey(k, rv.Type().Key())
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := e.storeValue(obj.values[k], elem); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+	return e.storeJSON(doc, rv)
+}
+
+// toonUnmarshaler returns the ToonUnmarshaler of rv, allocating a nil
+// pointer first
+func toonUnmarshaler(rv reflect.Value) (ToonUnmarshaler, bool) {
+	if rv.Kind() == reflect.Ptr && rv.Type().Implements(toonUnmarshalerType) {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return rv.Interface().(ToonUnmarshaler), true
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(toonUnmarshalerType) {
+		return rv.Addr().Interface().(ToonUnmarshaler), true
+	}
+	return nil, false
+}
+
+// fragmentOf re-encodes a decoded value for UnmarshalTOON. The encoder is
+// built directly so that SetDefaults cannot change the fragment.
+func fragmentOf(doc interface{}) string {
+	e := &encoder{options: defaultOptions()}
+	e.dialect = DialectV2
+	return e.encode(doc)
+}
+
+// storeJSON stores doc in rv through encoding/json
+func (e *encoder) storeJSON(doc interface{}, rv reflect.Value) error {
+	return e.unmarshalJSON(doc, rv.Addr().Interface())
+}
+
+// unmarshalJSON stores doc in the value pointed to by v through
+// encoding/json, rejecting unknown fields under WithDisallowUnknownFields
+func (e *encoder) unmarshalJSON(doc, v interface{}) error {
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if !e.disallowUnknown {
+		return e.typeError(doc, json.Unmarshal(jsonBytes, v))
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.DisallowUnknownFields()
+	return e.typeError(doc, dec.Decode(v))
+}
+
+// matchField finds the field for key, preferring an exact match over a
+// case-insensitive one, as encoding/json does
+func matchField(fields []structField, key string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+// unquotedValue reads the JSON text in the string of a quoted field back as
+// the scalar it holds. Fields written by DialectV1 can come back as a bare
+// number or bool, and are left so, like strings that hold no scalar, for
+// storeValue to store or reject.
+func unquotedValue(f structField, doc interface{}) interface{} {
+	text, ok := doc.(string)
+	if !f.quoted || !ok {
+		return doc
+	}
+	v, err := decodeJSON(strings.NewReader(text))
+	if err != nil || !isPrimitive(v) {
+		return doc
+	}
+	return v
+}
+
+// settableField is reflect.Value.FieldByIndex, allocating the nil embedded
+// struct pointers on the way
+func settableField(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// parseMapKey converts an object key to a map key of type t
+func (e *encoder) parseMapKey(k string, t reflect.Type) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, t.Bits())
+		if err != nil {
+			return key, e.mismatch(k, t)
+		}
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, t.Bits())
+		if err != nil {
+			return key, e.mismatch(k, t)
+		}
+		key.SetUint(n)
+	default:
+		return key, fmt.Errorf("totoon: cannot use map key type %s", t)
+	}
+	return key, nil
+}
+
+// TypeError reports a decoded value that does not fit the Go value it is
+// stored in. It unwraps to the *json.UnmarshalTypeError it describes.
+type TypeError struct {
+	Path     string       // location of the value, "" for the root
+	Expected reflect.Type // the type of the Go value
+	Actual   string       // the kind of TOON value, such as "string" or "number 300"
+
+	err *json.UnmarshalTypeError
+}
+
+func (err *TypeError) Error() string {
+	msg := "totoon: cannot store " + err.Actual + " in a Go value of type " + err.Expected.String()
+	if err.Path != "" {
+		msg += " at " + err.Path
+	}
+	return msg
+}
+
+func (err *TypeError) Unwrap() error {
+	return err.err
+}
+
+// typeError wraps an error of encoding/json raised while storing doc, the
+// value at e.path, into a *TypeError
+func (e *encoder) typeError(doc interface{}, err error) error {
+	var jsonErr *json.UnmarshalTypeError
+	if !errors.As(err, &jsonErr) {
+		return err
+	}
+	path := e.path
+	if jsonErr.Field != "" {
+		// encoding/json writes list indexes and keys alike as path
+		// segments, so the segments are followed through doc
+		for _, segment := range strings.Split(jsonErr.Field, ".") {
+			if list, ok := doc.([]interface{}); ok {
+				if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(list) {
+					path, doc = indexPath(path, i), list[i]
+					continue
+				}
+			}
+			path, doc = joinPath(path, segment), childValue(doc, segment)
+		}
+	}
+	return &TypeError{Path: path, Expected: jsonErr.Type, Actual: jsonErr.Value, err: jsonErr}
+}
+
+// childValue returns the value of key in obj, matched as encoding/json
+// matches struct fields, or nil
+func childValue(obj interface{}, key string) interface{} {
+	m, ok := obj.(*OrderedMap)
+	if !ok {
+		return nil
+	}
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	for _, k := range m.keys {
+		if strings.EqualFold(k, key) {
+			return m.values[k]
+		}
+	}
+	return nil
+}
+
+// mismatch reports a decoded value at e.path that does not fit a Go type
+func (e *encoder) mismatch(doc interface{}, t reflect.Type) error {
+	kind := "string"
+	switch doc.(type) {
+	case *OrderedMap:
+		kind = "object"
+	case []interface{}:
+		kind = "array"
+	case json.Number:
+		kind = "number"
+	case bool:
+		kind = "bool"
+	}
+	return e.typeError(doc, &json.UnmarshalTypeError{Value: kind, Type: t})
+}
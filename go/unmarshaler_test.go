@@ -0,0 +1,108 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type accountID int
+
+func (id accountID) MarshalTOON() ([]byte, error) {
+	return []byte(fmt.Sprintf("acct-%d", int(id))), nil
+}
+
+func (id *accountID) UnmarshalTOON(data []byte) error {
+	s := string(data)
+	if !strings.HasPrefix(s, "acct-") {
+		return fmt.Errorf("invalid account ID %q", s)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "acct-"))
+	if err != nil {
+		return err
+	}
+	*id = accountID(n)
+	return nil
+}
+
+type account struct {
+	ID       accountID            `toon:"id"`
+	Owner    string               `toon:"owner_name"`
+	Parent   *accountID           `toon:"parent"`
+	Linked   []accountID          `toon:"linked"`
+	ByRegion map[string]accountID `toon:"by_region"`
+	Limits   map[int]int          `json:"limits"`
+}
+
+func TestToonUnmarshaler(t *testing.T) {
+	parent := accountID(1)
+	in := account{
+		ID:       42,
+		Owner:    "Ann",
+		Parent:   &parent,
+		Linked:   []accountID{7, 8},
+		ByRegion: map[string]accountID{"eu": 3},
+		Limits:   map[int]int{10: 100},
+	}
+	doc, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "id: acct-42\nowner_name: Ann\nparent: acct-1\nlinked:\n  - acct-7\n  - acct-8\nby_region:\n  eu: acct-3\nlimits:\n  10: 100"
+	if string(doc) != expected {
+		t.Errorf("Expected %q, got: %q", expected, doc)
+	}
+
+	var out account
+	if err := Unmarshal(doc, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Expected %+v, got: %+v", in, out)
+	}
+}
+
+func TestToonUnmarshaler_Null(t *testing.T) {
+	parent := accountID(5)
+	out := account{Parent: &parent}
+	if err := Unmarshal([]byte("id: acct-1\nparent: null"), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.ID != 1 || out.Parent != nil {
+		t.Errorf("Expected ID 1 and no parent, got: %+v", out)
+	}
+}
+
+func TestToonUnmarshaler_Error(t *testing.T) {
+	var out account
+	err := Unmarshal([]byte("id: 42"), &out)
+	if err == nil || err.Error() != `invalid account ID "42"` {
+		t.Errorf("Expected the UnmarshalTOON error, got: %v", err)
+	}
+
+	err = Unmarshal([]byte("linked: acct-1"), &out)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Value != "string" {
+		t.Errorf("Expected a type error, got: %v", err)
+	}
+}
+
+func TestUnmarshal_ToonTags(t *testing.T) {
+	// toon tags are honored without a ToonUnmarshaler in sight
+	type row struct {
+		UserID int64  `toon:"user_id"`
+		Name   string `toon:"name" json:"display"`
+	}
+	var rows []row
+	if err := Unmarshal([]byte("[2]{name,user_id}:\n  Ann,9007199254740993\n  Bob,2"), &rows); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []row{{9007199254740993, "Ann"}, {2, "Bob"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got: %v", expected, rows)
+	}
+}
@@ -0,0 +1,208 @@
+package totoon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Value is a node in a mutable document tree. It wraps the generic
+// map[string]interface{} / []interface{} form used throughout the package so
+// documents can be inspected and edited without defining structs.
+//
+// Lookups never fail: Get and Index return nil for missing entries, and every
+// accessor is safe to call on a nil *Value, returning the zero value. This
+// allows chains such as doc.Get("users").Index(0).Get("name").String().
+type Value struct {
+	data interface{}
+}
+
+// NewValue wraps v in a Value. Structs, typed slices and typed maps are
+// normalized through encoding/json into the generic form first, with their
+// numbers as json.Numbers so large integers stay exact; values that cannot
+// be normalized are wrapped as-is.
+func NewValue(v interface{}) *Value {
+	if inner, ok := v.(*Value); ok {
+		return inner
+	}
+	if data, err := normalize(v); err == nil {
+		v = data
+	}
+	return &Value{data: v}
+}
+
+// Exists reports whether the value is present
+func (v *Value) Exists() bool {
+	return v != nil
+}
+
+// Interface returns the underlying generic value
+func (v *Value) Interface() interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.data
+}
+
+// IsObject reports whether the value is an object
+func (v *Value) IsObject() bool {
+	_, ok := v.Interface().(map[string]interface{})
+	return ok
+}
+
+// IsList reports whether the value is a list
+func (v *Value) IsList() bool {
+	_, ok := v.Interface().([]interface{})
+	return ok
+}
+
+// IsNull reports whether the value is present and null
+func (v *Value) IsNull() bool {
+	return v != nil && v.data == nil
+}
+
+// Get returns the value stored under key, or nil if v is not an object or
+// has no such key
+func (v *Value) Get(key string) *Value {
+	obj, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	child, exists := obj[key]
+	if !exists {
+		return nil
+	}
+	return &Value{data: child}
+}
+
+// Index returns the i-th item of a list, or nil if v is not a list or i is
+// out of range
+func (v *Value) Index(i int) *Value {
+	list, ok := v.Interface().([]interface{})
+	if !ok || i < 0 || i >= len(list) {
+		return nil
+	}
+	return &Value{data: list[i]}
+}
+
+// Len returns the number of entries of an object or items of a list
+func (v *Value) Len() int {
+	switch val := v.Interface().(type) {
+	case map[string]interface{}:
+		return len(val)
+	case []interface{}:
+		return len(val)
+	}
+	return 0
+}
+
+// Keys returns the sorted keys of an object
+func (v *Value) Keys() []string {
+	obj, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set stores value under key in an object. Objects and lists inside value
+// are shared, not copied.
+func (v *Value) Set(key string, value interface{}) error {
+	obj, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return errors.New("totoon: Set called on a value that is not an object")
+	}
+	obj[key] = NewValue(value).data
+	return nil
+}
+
+// SetIndex replaces the i-th item of a list
+func (v *Value) SetIndex(i int, value interface{}) error {
+	list, ok := v.Interface().([]interface{})
+	if !ok {
+		return errors.New("totoon: SetIndex called on a value that is not a list")
+	}
+	if i < 0 || i >= len(list) {
+		return fmt.Errorf("totoon: index %d out of range for list of length %d", i, len(list))
+	}
+	list[i] = NewValue(value).data
+	return nil
+}
+
+// Delete removes key from an object and reports whether it was present
+func (v *Value) Delete(key string) bool {
+	obj, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, exists := obj[key]
+	delete(obj, key)
+	return exists
+}
+
+// String returns the value as a string. Strings are returned as-is and
+// other scalars in their TOON form; objects and lists return "".
+func (v *Value) String() string {
+	switch val := v.Interface().(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	case string:
+		return val
+	default:
+		return newEncoder(nil).valueToToonInline(val)
+	}
+}
+
+// Int returns the value as an int64. Integers and integer json.Numbers are
+// returned exactly, other numbers are truncated towards zero and numeric
+// strings are parsed; anything else returns 0.
+func (v *Value) Int() int64 {
+	switch val := v.Interface().(type) {
+	case string:
+		n, _ := strconv.ParseInt(val, 10, 64)
+		return n
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+	case int:
+		return int64(val)
+	case int64:
+		return val
+	case uint64:
+		return int64(val)
+	}
+	f, _ := toFloat(v.Interface())
+	return int64(f)
+}
+
+// Float returns the value as a float64. Numeric strings are parsed;
+// anything else returns 0.
+func (v *Value) Float() float64 {
+	if s, ok := v.Interface().(string); ok {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+	f, _ := toFloat(v.Interface())
+	return f
+}
+
+// Bool returns the value as a bool. The strings "true" and "false" are
+// parsed; anything else returns false.
+func (v *Value) Bool() bool {
+	switch val := v.Interface().(type) {
+	case bool:
+		return val
+	case string:
+		b, _ := strconv.ParseBool(val)
+		return b
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package totoon
+
+import (
+	"testing"
+)
+
+func TestValue_Accessors(t *testing.T) {
+	doc := NewValue(map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice", "age": 30, "admin": true},
+		},
+		"count": "2",
+	})
+	user := doc.Get("users").Index(0)
+	if user.Get("name").String() != "Alice" {
+		t.Errorf("Expected 'Alice', got: %s", user.Get("name").String())
+	}
+	if user.Get("age").Int() != 30 {
+		t.Errorf("Expected 30, got: %d", user.Get("age").Int())
+	}
+	if !user.Get("admin").Bool() {
+		t.Errorf("Expected admin to be true")
+	}
+	if doc.Get("count").Int() != 2 {
+		t.Errorf("Expected numeric string to parse, got: %d", doc.Get("count").Int())
+	}
+	if doc.Get("users").Len() != 1 {
+		t.Errorf("Expected 1 user, got: %d", doc.Get("users").Len())
+	}
+}
+
+func TestValue_MissingIsNilSafe(t *testing.T) {
+	doc := NewValue(map[string]interface{}{"a": 1})
+	missing := doc.Get("b").Index(3).Get("c")
+	if missing.Exists() {
+		t.Errorf("Expected missing value")
+	}
+	if missing.String() != "" || missing.Int() != 0 || missing.Bool() {
+		t.Errorf("Expected zero values from missing value")
+	}
+}
+
+func TestValue_Mutation(t *testing.T) {
+	doc := NewValue(map[string]interface{}{
+		"meta":  map[string]interface{}{"count": 1},
+		"debug": true,
+	})
+	if err := doc.Get("meta").Set("count", 2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !doc.Delete("debug") {
+		t.Errorf("Expected debug key to be deleted")
+	}
+	if err := doc.Set("tags", []string{"a"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := doc.Get("tags").SetIndex(0, "b"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := ToToonWithOptions(doc, withSortedKeys())
+	expected := "meta:\n  count: 2\ntags:\n  - b"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestValue_SetOnScalar(t *testing.T) {
+	if err := NewValue(1).Set("a", 1); err == nil {
+		t.Errorf("Expected error when setting a key on a scalar")
+	}
+	if err := NewValue([]interface{}{1}).SetIndex(5, 1); err == nil {
+		t.Errorf("Expected error for out of range index")
+	}
+}
+
+func TestValue_LargeIntegers(t *testing.T) {
+	type record struct {
+		ID   int64  `json:"id"`
+		Big  uint64 `json:"big"`
+		Name string `json:"name"`
+	}
+	doc := NewValue(record{ID: 9007199254740993, Big: 18446744073709551615, Name: "x"})
+	if id := doc.Get("id").Int(); id != 9007199254740993 {
+		t.Errorf("Expected 9007199254740993, got: %d", id)
+	}
+	if big := doc.Get("big").String(); big != "18446744073709551615" {
+		t.Errorf("Expected 18446744073709551615, got: %s", big)
+	}
+	if id := NewValue(map[string]interface{}{"id": int64(9007199254740993)}).Get("id").Int(); id != 9007199254740993 {
+		t.Errorf("Expected 9007199254740993, got: %d", id)
+	}
+	if s := ToToon(doc.Interface()); s != "big: 18446744073709551615\nid: 9007199254740993\nname: x" {
+		t.Errorf("Unexpected TOON: %q", s)
+	}
+}
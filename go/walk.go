@@ -0,0 +1,87 @@
+package totoon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SkipValue can be returned by a WalkFunc to skip the children of the
+// value it was called with
+var SkipValue = errors.New("skip this value")
+
+// WalkFunc is called by WalkValue for every value in a tree. path locates
+// the value from the root, using dots between object keys and brackets for
+// list indexes (for example "users[2].name"); the root itself has an empty
+// path.
+type WalkFunc func(path string, v ToonValue) error
+
+// WalkValue traverses a tree of map[string]interface{}, *OrderedMap,
+// []interface{} and scalar values (or the tree wrapped by a *Value)
+// depth-first, calling fn for each value before its children. Map keys are
+// visited in sorted order and the keys of an *OrderedMap in their recorded
+// order. Returning SkipValue from fn skips the children of the current
+// value; any other error stops the walk and is returned.
+func WalkValue(v ToonValue, fn WalkFunc) error {
+	if doc, ok := v.(*Value); ok {
+		v = doc.Interface()
+	}
+	err := walkValue("", v, fn)
+	if err == SkipValue {
+		return nil
+	}
+	return err
+}
+
+func walkValue(path string, v ToonValue, fn WalkFunc) error {
+	if err := fn(path, v); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := walkChild(joinPath(path, k), val[k], fn); err != nil {
+				return err
+			}
+		}
+	case *OrderedMap:
+		for _, k := range val.Keys() {
+			child, _ := val.Get(k)
+			if err := walkChild(joinPath(path, k), child, fn); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			if err := walkChild(indexPath(path, i), item, fn); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for i, item := range val {
+			if err := walkChild(indexPath(path, i), item, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indexPath appends a list index to path
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func walkChild(path string, v ToonValue, fn WalkFunc) error {
+	err := walkValue(path, v, fn)
+	if err == SkipValue {
+		return nil
+	}
+	return err
+}
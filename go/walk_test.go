@@ -0,0 +1,83 @@
+package totoon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWalkValue_Paths(t *testing.T) {
+	data := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "Alice"},
+		},
+		"count": 1,
+	}
+	var paths []string
+	err := WalkValue(data, func(path string, v ToonValue) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"", "count", "users", "users[0]", "users[0].name"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got: %v", expected, paths)
+	}
+}
+
+func TestWalkValue_OrderedMap(t *testing.T) {
+	data := NewOrderedMap().
+		Set("z", 1).
+		Set("users", []interface{}{NewOrderedMap().Set("name", "Alice").Set("age", 30)})
+	var paths []string
+	err := WalkValue(data, func(path string, v ToonValue) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"", "z", "users", "users[0]", "users[0].name", "users[0].age"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got: %v", expected, paths)
+	}
+}
+
+func TestWalkValue_SkipValue(t *testing.T) {
+	data := map[string]interface{}{
+		"secret": map[string]interface{}{"token": "x"},
+		"public": map[string]interface{}{"name": "y"},
+	}
+	var paths []string
+	WalkValue(data, func(path string, v ToonValue) error {
+		paths = append(paths, path)
+		if path == "secret" {
+			return SkipValue
+		}
+		return nil
+	})
+	expected := []string{"", "public", "public.name", "secret"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got: %v", expected, paths)
+	}
+}
+
+func TestWalkValue_StopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := WalkValue([]interface{}{1, 2, 3}, func(path string, v ToonValue) error {
+		calls++
+		if path == "[1]" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Expected stop error, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected walk to stop after 3 calls, got: %d", calls)
+	}
+}
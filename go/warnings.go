@@ -0,0 +1,57 @@
+package totoon
+
+import (
+	"fmt"
+	"math"
+)
+
+// Warning describes a lossy decision made while encoding, such as a value
+// that had to be rendered with a fallback representation
+type Warning struct {
+	Path    string // location of the affected value, "" for the root
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Path == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// ToToonWithWarnings converts a Go value to TOON like ToToonWithOptions and
+// also returns the warnings raised along the way: numbers that may have lost
+// precision, unsupported values rendered with a fallback, and truncations
+// applied by options. The output is identical to ToToonWithOptions.
+func ToToonWithWarnings(data ToonValue, opts ...Option) (string, []Warning) {
+	e := newEncoder(opts)
+	var warnings []Warning
+	e.warnings = &warnings
+	return e.encode(data), warnings
+}
+
+// warn records a warning for the value currently being encoded
+func (e *encoder) warn(format string, args ...interface{}) {
+	if e.warnings == nil {
+		return
+	}
+	*e.warnings = append(*e.warnings, Warning{Path: e.path, Message: fmt.Sprintf(format, args...)})
+}
+
+// unsupported renders a value the encoder cannot represent using %v
+func (e *encoder) unsupported(v interface{}) string {
+	e.warn("unsupported value of type %T rendered with %%v", v)
+	return fmt.Sprintf("%v", v)
+}
+
+// maxExactFloat is the magnitude from which float64 can no longer represent
+// every integer
+const maxExactFloat = 1 << 53
+
+// checkPrecision warns about integral floats too large to be exact, which
+// usually means an integer was decoded into a float64 along the way
+func (e *encoder) checkPrecision(v interface{}) {
+	if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) >= maxExactFloat {
+		e.warn("integer %v exceeds float64 precision and may have been rounded", v)
+	}
+}
@@ -0,0 +1,44 @@
+package totoon
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToToonWithWarnings_NoWarnings(t *testing.T) {
+	data := map[string]interface{}{"name": "Alice", "age": 30}
+	result, warnings := ToToonWithWarnings(data)
+	if !strings.Contains(result, "name: Alice") {
+		t.Errorf("Unexpected output: %s", result)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got: %v", warnings)
+	}
+}
+
+func TestToToonWithWarnings_PrecisionLoss(t *testing.T) {
+	var data interface{}
+	json.Unmarshal([]byte(`{"users": [{"id": 9007199254740993}]}`), &data)
+	_, warnings := ToToonWithWarnings(data)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got: %v", warnings)
+	}
+	if warnings[0].Path != "users[0].id" {
+		t.Errorf("Expected path 'users[0].id', got: %s", warnings[0].Path)
+	}
+	if !strings.Contains(warnings[0].String(), "precision") {
+		t.Errorf("Expected precision warning, got: %s", warnings[0])
+	}
+}
+
+func TestToToonWithWarnings_UnsupportedValue(t *testing.T) {
+	data := map[string]interface{}{"callback": func() {}}
+	result, warnings := ToToonWithWarnings(data)
+	if len(warnings) != 1 || warnings[0].Path != "callback" {
+		t.Fatalf("Expected 1 warning for 'callback', got: %v", warnings)
+	}
+	if !strings.Contains(result, "callback: ") {
+		t.Errorf("Expected the value to still be rendered, got: %s", result)
+	}
+}
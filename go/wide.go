@@ -0,0 +1,108 @@
+package totoon
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WideTableMode selects how WithMaxColumns rewrites tables that are too wide
+type WideTableMode int
+
+const (
+	// SplitWideTables splits the columns over several tables that repeat
+	// the id column, written as key.1, key.2 and so on
+	SplitWideTables WideTableMode = iota
+	// ExpandWideTables writes each record as its own block of key: value
+	// lines, as WithOneValuePerLine does
+	ExpandWideTables
+)
+
+// WithMaxColumns rewrites tables with more than n columns according to mode
+// instead of emitting very long rows. When splitting, the id column is the
+// "id" field if there is one and the first column otherwise, and every
+// table holds it plus up to n-1 other columns; n is raised to 2 if lower.
+func WithMaxColumns(n int, mode WideTableMode) Option {
+	return func(o *options) {
+		o.maxColumns = n
+		o.wideMode = mode
+	}
+}
+
+// isWide reports whether a table with the given columns exceeds
+// WithMaxColumns
+func (e *encoder) isWide(columns []string) bool {
+	return e.maxColumns > 0 && len(columns) > e.maxColumns
+}
+
+// writeWideTable emits a table that exceeds WithMaxColumns
+func (e *encoder) writeWideTable(key string, data []interface{}, columns []string, level int, emit lineSink) {
+	prefix := strings.Repeat(" ", e.indent*level)
+	if e.wideMode == ExpandWideTables {
+		e.trace("table has %d columns, more than %d, so each record is written as a block", len(columns), e.maxColumns)
+		if key == "" {
+			emit(e.expandedListToToon(data, level))
+			return
+		}
+		emit(prefix + key + ":")
+		emit(e.expandedListToToon(data, level+1))
+		return
+	}
+
+	id := columns[0]
+	for _, k := range columns {
+		if k == "id" {
+			id = k
+		}
+	}
+	var rest []string
+	for _, k := range columns {
+		if k != id {
+			rest = append(rest, k)
+		}
+	}
+	width := e.maxColumns - 1
+	if width < 1 {
+		width = 1
+	}
+	e.trace("table has %d columns, more than %d, so it is split into %d tables sharing %s", len(columns), e.maxColumns, (len(rest)+width-1)/width, id)
+
+	var objects []int
+	for i, item := range data {
+		if _, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, i)
+		}
+	}
+
+	dataPrefix := e.rowPrefix(level)
+	tablePath := e.path
+	defer func() { e.path = tablePath }()
+	for part := 0; part*width < len(rest); part++ {
+		end := (part + 1) * width
+		if end > len(rest) {
+			end = len(rest)
+		}
+		fields := append([]string{id}, rest[part*width:end]...)
+		partKey := strconv.Itoa(part + 1)
+		if key != "" {
+			partKey = key + "." + partKey
+		}
+		emit(prefix + e.tableHeader(partKey, len(objects), fields))
+
+		for n, i := range objects {
+			if footer, cut := e.rowOverflow(n, len(objects)); cut {
+				emit(dataPrefix + footer)
+				break
+			}
+			obj := data[i].(map[string]interface{})
+			cells := make([]string, len(fields))
+			for c, k := range fields {
+				e.path = joinPath(indexPath(tablePath, i), k)
+				cells[c] = e.missingCell()
+				if v, exists := obj[k]; exists {
+					cells[c] = e.columnCellToToon(k, e.format(v))
+				}
+			}
+			emit(dataPrefix + strings.Join(cells, e.delimiterString()))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package totoon
+
+import "testing"
+
+func wideRows() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"id": 1, "a": "x", "b": true, "c": 3, "d": "y"},
+		map[string]interface{}{"id": 2, "a": "z", "c": 4},
+	}
+}
+
+func TestWithMaxColumns_Split(t *testing.T) {
+	data := map[string]interface{}{"rows": wideRows()}
+	result := ToToonWithOptions(data, withSortedKeys(), WithMaxColumns(3, SplitWideTables))
+	expected := "rows.1[2]{id,a,b}:\n  1,x,true\n  2,z,\nrows.2[2]{id,c,d}:\n  1,3,y\n  2,4,"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	// tables within the limit are left alone
+	result = ToToonWithOptions(data, withSortedKeys(), WithMaxColumns(5, SplitWideTables))
+	if expected := ToToonWithOptions(data, withSortedKeys()); result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithMaxColumns_SplitFirstColumnAsID(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"a": 1, "b": 2, "c": 3},
+	}
+	result := ToToonWithOptions(rows, withSortedKeys(), WithMaxColumns(1, SplitWideTables))
+	expected := "1[1]{a,b}:\n  1,2\n2[1]{a,c}:\n  1,3"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithMaxColumns_Expand(t *testing.T) {
+	data := map[string]interface{}{"rows": wideRows()[1:]}
+	result := ToToonWithOptions(data, withSortedKeys(), WithMaxColumns(2, ExpandWideTables))
+	expected := "rows:\n  - a: z\n    c: 4\n    id: 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -0,0 +1,51 @@
+package totoon
+
+import "unicode/utf8"
+
+// ellipsis marks a cell value cut short by WithColumnMaxWidth
+const ellipsis = "…"
+
+// WithColumnMaxWidth caps the width, in characters, of the cells of the named
+// table columns. Longer string values are cut and end with "…", so wide
+// free-text columns do not dominate a table. Nested objects and lists are
+// measured in their inline form and truncated as text; numbers and booleans
+// are never cut. Widths below one are ignored.
+func WithColumnMaxWidth(widths map[string]int) Option {
+	return func(o *options) {
+		o.maxWidths = widths
+	}
+}
+
+// columnCellToToon renders a cell of the given table column, applying its
+// maximum width
+func (e *encoder) columnCellToToon(field string, v interface{}) string {
+	width := e.maxWidths[field]
+	if width < 1 {
+		return e.cellToToon(v)
+	}
+
+	switch val := v.(type) {
+	case string:
+		return e.cellToToon(e.truncate(val, width))
+	case map[string]interface{}, []interface{}:
+		inline := e.cellToToon(val)
+		if utf8.RuneCountInString(inline) > width {
+			return e.cellToToon(e.truncate(inline, width))
+		}
+		return inline
+	}
+	return e.cellToToon(v)
+}
+
+// truncate shortens s to at most width characters, the last being the
+// ellipsis, and records a warning when it does
+func (e *encoder) truncate(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= width {
+		return s
+	}
+	e.warn("value of %d characters truncated to %d", n, width)
+	e.count(MetricTruncations, 1)
+	runes := []rune(s)
+	return string(runes[:width-1]) + ellipsis
+}
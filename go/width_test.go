@@ -0,0 +1,50 @@
+package totoon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithColumnMaxWidth_TruncatesStrings(t *testing.T) {
+	data := map[string]interface{}{
+		"notes": []interface{}{
+			map[string]interface{}{"id": 1, "text": "short"},
+			map[string]interface{}{"id": 2, "text": "a much longer free-text comment"},
+		},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithColumnMaxWidth(map[string]int{"text": 8, "id": 1}))
+	expected := "notes[2]{id,text}:\n  1,short\n  2,a much …"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestWithColumnMaxWidth_NestedAndUnicode(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"meta": map[string]interface{}{"k": "vvvvvvvv"}, "name": "ééééé"},
+	}
+	result := ToToonWithOptions(data, withSortedKeys(), WithColumnMaxWidth(map[string]int{"meta": 5, "name": 3}))
+	if !strings.Contains(result, `"{k:v…",éé…`) {
+		t.Errorf("Expected truncated nested and unicode cells, got: %q", result)
+	}
+}
+
+func TestWithColumnMaxWidth_Warning(t *testing.T) {
+	data := []interface{}{map[string]interface{}{"text": "abcdef"}}
+	_, warnings := ToToonWithWarnings(data, WithColumnMaxWidth(map[string]int{"text": 3}))
+	if len(warnings) != 1 || warnings[0].Path != "[0].text" {
+		t.Errorf("Expected one truncation warning at [0].text, got: %v", warnings)
+	}
+}
+
+func TestWithColumnMaxWidth_Writer(t *testing.T) {
+	var buf strings.Builder
+	w := NewWriter(&buf, WithColumnMaxWidth(map[string]int{"b": 2}))
+	w.BeginTable([]string{"a", "b"})
+	w.Row("long", "long")
+	w.EndTable()
+	w.Flush()
+	if buf.String() != "[1]{a,b}:\n  long,l…" {
+		t.Errorf("Expected %q, got: %q", "[1]{a,b}:\n  long,l…", buf.String())
+	}
+}
@@ -0,0 +1,444 @@
+package totoon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writer emits TOON incrementally through explicit structural calls, for
+// serializers that walk their own data model (protobuf messages, database
+// rows, ...) and want to produce TOON without first building an
+// interface{} tree.
+//
+// Objects and lists follow the same layout as ToToon. Objects inside lists
+// are written in the expanded "- key: value" form used by
+// WithOneValuePerLine, since their shape is not known ahead of time. Table
+// rows are buffered until EndTable so the header can carry the row count.
+//
+// Output is buffered (see WithBufferSize); call Flush to push pending output,
+// and always once the document is complete. The first error is sticky and
+// returned by every later call.
+type Writer struct {
+	e       *encoder
+	w       *bufio.Writer
+	stack   []*writerFrame
+	table   *writerTable
+	started bool
+	done    bool
+	err     error
+}
+
+type writerFrameKind int
+
+const (
+	objectFrame writerFrameKind = iota
+	listFrame
+)
+
+type writerFrame struct {
+	kind    writerFrameKind
+	level   int    // indentation level of the frame's children
+	key     string // pending key in an object frame
+	hasKey  bool
+	entries int
+	header  string // container line written once the first child arrives
+	item    bool   // object written as a list item, first line on the dash
+}
+
+type writerTable struct {
+	header string // prefix and key that precede "[N]{fields}:"
+	fields []string
+	rows   []string
+	owner  *writerFrame
+}
+
+// NewWriter returns a Writer that writes TOON to w
+func NewWriter(w io.Writer, opts ...Option) *Writer {
+	e := newEncoder(opts)
+	return &Writer{e: e, w: e.newBufferedWriter(w)}
+}
+
+// BeginObject starts an object, either as the document root, as the value of
+// the pending key, or as the next list item
+func (w *Writer) BeginObject() error {
+	return w.beginContainer(objectFrame)
+}
+
+// EndObject closes the current object
+func (w *Writer) EndObject() error {
+	return w.endContainer(objectFrame)
+}
+
+// BeginList starts a list of scalars, objects or nested lists
+func (w *Writer) BeginList() error {
+	return w.beginContainer(listFrame)
+}
+
+// EndList closes the current list
+func (w *Writer) EndList() error {
+	return w.endContainer(listFrame)
+}
+
+// Key sets the key for the next value written into the current object
+func (w *Writer) Key(key string) error {
+	if w.err != nil {
+		return w.err
+	}
+	f := w.top()
+	if f == nil || f.kind != objectFrame || w.table != nil {
+		return w.fail(errors.New("totoon: Key called outside of an object"))
+	}
+	if f.hasKey {
+		return w.fail(fmt.Errorf("totoon: Key %q called before a value for key %q", key, f.key))
+	}
+	f.key, f.hasKey = key, true
+	return nil
+}
+
+// Scalar writes a scalar value: nil, a bool, a number or a string. Other
+// values are rendered the same way ToToon renders them inline.
+func (w *Writer) Scalar(v interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.table != nil {
+		return w.fail(errors.New("totoon: Scalar called inside a table, use Row"))
+	}
+
+	value := w.e.valueToToonInline(w.e.unorder(v))
+	w.e.orders = nil
+	f := w.top()
+	switch {
+	case f == nil:
+		if err := w.beginRoot(); err != nil {
+			return err
+		}
+		w.writeLine(nil, value)
+		w.done = true
+	case f.kind == objectFrame:
+		key, err := w.takeKey(f)
+		if err != nil {
+			return err
+		}
+		w.writeLine(f, fmt.Sprintf("%s%s: %s", w.prefix(f.level), key, value))
+	default:
+		w.writeLine(f, fmt.Sprintf("%s- %s", w.prefix(f.level), value))
+	}
+	return w.err
+}
+
+// BeginTable starts a table with the given columns, either as the document
+// root or as the value of the pending key. Rows are added with Row and the
+// table is written by EndTable.
+func (w *Writer) BeginTable(fields []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.table != nil {
+		return w.fail(errors.New("totoon: BeginTable called inside a table"))
+	}
+	if len(fields) == 0 {
+		return w.fail(errors.New("totoon: BeginTable needs at least one field"))
+	}
+
+	f := w.top()
+	t := &writerTable{fields: fields, owner: f}
+	switch {
+	case f == nil:
+		if err := w.beginRoot(); err != nil {
+			return err
+		}
+	case f.kind == objectFrame:
+		key, err := w.takeKey(f)
+		if err != nil {
+			return err
+		}
+		t.header = w.prefix(f.level) + key
+	default:
+		return w.fail(errors.New("totoon: tables cannot be list items"))
+	}
+	w.table = t
+	return nil
+}
+
+// Row adds a row to the current table; values are matched to the table
+// fields by position
+func (w *Writer) Row(values ...interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.table == nil {
+		return w.fail(errors.New("totoon: Row called outside of a table"))
+	}
+	if len(values) != len(w.table.fields) {
+		return w.fail(fmt.Errorf("totoon: row has %d values, table has %d fields", len(values), len(w.table.fields)))
+	}
+
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = w.e.columnCellToToon(w.table.fields[i], w.e.unorder(v))
+	}
+	w.e.orders = nil
+	w.table.rows = append(w.table.rows, strings.Join(cells, w.e.delimiterString()))
+	return nil
+}
+
+// EndTable writes the current table
+func (w *Writer) EndTable() error {
+	if w.err != nil {
+		return w.err
+	}
+	t := w.table
+	if t == nil {
+		return w.fail(errors.New("totoon: EndTable called outside of a table"))
+	}
+	w.table = nil
+
+	if len(t.rows) == 0 {
+		if t.owner == nil {
+			w.writeLine(nil, "[]")
+		} else {
+			w.writeLine(t.owner, t.header+": []")
+		}
+	} else {
+		w.writeLine(t.owner, w.e.tableHeader(t.header, len(t.rows), t.fields))
+		level := 0
+		if t.owner != nil {
+			level = t.owner.level
+		}
+		for _, row := range t.rows {
+			w.writeRaw(w.e.rowPrefix(level) + row)
+		}
+	}
+
+	if t.owner == nil {
+		w.done = true
+	}
+	return w.err
+}
+
+// Objects writes a list of objects, either as the document root or as the
+// value of the pending key, the way ToToon writes one: as a table whose
+// columns follow the order in which fields first appear, with the cells of
+// fields a row lacks written as WithMissingCell sets, or as list items when
+// the table thresholds (see WithTableMinRows) rule a table out. It suits
+// converters whose records do not all share the same fields.
+func (w *Writer) Objects(rows []*OrderedMap) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.table != nil {
+		return w.fail(errors.New("totoon: Objects called inside a table"))
+	}
+
+	f := w.top()
+	key, level := "", 0
+	switch {
+	case f == nil:
+		if err := w.beginRoot(); err != nil {
+			return err
+		}
+	case f.kind == objectFrame:
+		k, err := w.takeKey(f)
+		if err != nil {
+			return err
+		}
+		key, level = k, f.level
+	default:
+		return w.fail(errors.New("totoon: tables cannot be list items"))
+	}
+
+	if len(rows) == 0 {
+		if f == nil {
+			w.writeLine(nil, "[]")
+		} else {
+			w.writeLine(f, w.prefix(level)+key+": []")
+		}
+	} else {
+		list := make([]interface{}, len(rows))
+		for i, row := range rows {
+			list[i] = row
+		}
+		first := true
+		w.e.writeTable(key, w.e.unorder(list).([]interface{}), level, func(line string) {
+			if first {
+				first = false
+				w.writeLine(f, line)
+				return
+			}
+			w.writeRaw(line)
+		})
+		w.e.orders = nil
+	}
+
+	if f == nil {
+		w.done = true
+	}
+	return w.err
+}
+
+// Flush writes any buffered output to the underlying writer
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		return w.fail(err)
+	}
+	return nil
+}
+
+func (w *Writer) beginContainer(kind writerFrameKind) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.table != nil {
+		return w.fail(errors.New("totoon: containers cannot be started inside a table"))
+	}
+
+	parent := w.top()
+	f := &writerFrame{kind: kind}
+	switch {
+	case parent == nil:
+		if err := w.beginRoot(); err != nil {
+			return err
+		}
+	case parent.kind == objectFrame:
+		key, err := w.takeKey(parent)
+		if err != nil {
+			return err
+		}
+		w.flushHeader(parent)
+		f.level = parent.level + 1
+		f.header = w.prefix(parent.level) + key + ":"
+	default:
+		w.flushHeader(parent)
+		f.level = parent.level + 1
+		if kind == objectFrame {
+			// the item's first line carries the dash, so count it now
+			f.item = true
+			parent.entries++
+		} else {
+			f.header = w.prefix(parent.level) + "-"
+		}
+	}
+	w.stack = append(w.stack, f)
+	return w.err
+}
+
+func (w *Writer) endContainer(kind writerFrameKind) error {
+	if w.err != nil {
+		return w.err
+	}
+	f := w.top()
+	if f == nil || f.kind != kind || w.table != nil {
+		return w.fail(errors.New("totoon: mismatched end of object or list"))
+	}
+	if f.hasKey {
+		return w.fail(fmt.Errorf("totoon: key %q has no value", f.key))
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	parent := w.top()
+
+	if f.entries == 0 {
+		empty := "{}"
+		if kind == listFrame {
+			empty = "[]"
+		}
+		switch {
+		case f.item:
+			w.writeLine(parent, w.prefix(f.level-1)+"- "+empty)
+		case f.header != "":
+			w.writeLine(parent, f.header+" "+empty)
+		default:
+			w.writeLine(parent, empty)
+		}
+	}
+
+	if parent == nil {
+		w.done = true
+	}
+	return w.err
+}
+
+func (w *Writer) beginRoot() error {
+	if w.done {
+		return w.fail(errors.New("totoon: document already complete"))
+	}
+	return nil
+}
+
+func (w *Writer) takeKey(f *writerFrame) (string, error) {
+	if !f.hasKey {
+		return "", w.fail(errors.New("totoon: value written into an object without a Key"))
+	}
+	f.hasKey = false
+	return f.key, nil
+}
+
+// flushHeader writes the deferred container line of f, if any
+func (w *Writer) flushHeader(f *writerFrame) {
+	if f == nil || f.header == "" {
+		return
+	}
+	header := f.header
+	f.header = ""
+	w.writeLine(w.parentOf(f), header)
+}
+
+// writeLine writes a line that belongs to frame f, first emitting the
+// container line of f and moving the first line of a list-item object onto
+// its dash
+func (w *Writer) writeLine(f *writerFrame, line string) {
+	if f != nil {
+		w.flushHeader(f)
+		if f.item && f.entries == 0 {
+			line = w.prefix(f.level-1) + "- " + strings.TrimLeft(line, " ")
+		}
+		f.entries++
+	}
+	w.writeRaw(line)
+}
+
+func (w *Writer) writeRaw(line string) {
+	if w.err != nil {
+		return
+	}
+	if w.started {
+		line = "\n" + line
+	}
+	w.started = true
+	if _, err := w.w.WriteString(line); err != nil {
+		w.fail(err)
+	}
+}
+
+func (w *Writer) top() *writerFrame {
+	if len(w.stack) == 0 {
+		return nil
+	}
+	return w.stack[len(w.stack)-1]
+}
+
+func (w *Writer) parentOf(f *writerFrame) *writerFrame {
+	for i := len(w.stack) - 1; i > 0; i-- {
+		if w.stack[i] == f {
+			return w.stack[i-1]
+		}
+	}
+	return nil
+}
+
+func (w *Writer) prefix(level int) string {
+	return strings.Repeat(" ", w.e.indent*level)
+}
+
+func (w *Writer) fail(err error) error {
+	if w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
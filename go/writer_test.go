@@ -0,0 +1,171 @@
+package totoon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriter_MatchesToToon(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BeginObject()
+	w.Key("meta")
+	w.BeginObject()
+	w.Key("count")
+	w.Scalar(2)
+	w.EndObject()
+	w.Key("tags")
+	w.BeginList()
+	w.Scalar("a")
+	w.Scalar("b")
+	w.EndList()
+	w.Key("users")
+	w.BeginTable([]string{"id", "name"})
+	w.Row(1, "Alice")
+	w.Row(2, "Bob, Jr.")
+	w.EndTable()
+	w.EndObject()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := ToToonWithOptions(map[string]interface{}{
+		"meta": map[string]interface{}{"count": 2},
+		"tags": []interface{}{"a", "b"},
+		"users": []interface{}{
+			map[string]interface{}{"id": 1, "name": "Alice"},
+			map[string]interface{}{"id": 2, "name": "Bob, Jr."},
+		},
+	}, withSortedKeys())
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestWriter_ObjectsInList(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BeginList()
+	w.BeginObject()
+	w.Key("name")
+	w.Scalar("Alice")
+	w.Key("roles")
+	w.BeginList()
+	w.Scalar("admin")
+	w.EndList()
+	w.EndObject()
+	w.BeginObject()
+	w.EndObject()
+	w.EndList()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "- name: Alice\n  roles:\n    - admin\n- {}"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestWriter_EmptyContainers(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BeginObject()
+	w.Key("items")
+	w.BeginList()
+	w.EndList()
+	w.Key("extra")
+	w.BeginObject()
+	w.EndObject()
+	w.EndObject()
+	w.Flush()
+	if buf.String() != "items: []\nextra: {}" {
+		t.Errorf("Expected empty containers inline, got: %q", buf.String())
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BeginObject()
+	if err := w.Scalar(1); err == nil {
+		t.Errorf("Expected error for value without key")
+	}
+	if err := w.EndObject(); err == nil {
+		t.Errorf("Expected error to be sticky")
+	}
+
+	w = NewWriter(&buf)
+	w.BeginTable([]string{"a", "b"})
+	if err := w.Row(1); err == nil {
+		t.Errorf("Expected error for row with wrong number of values")
+	}
+}
+
+func TestWriter_BufferSize(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithBufferSize(16))
+	w.BeginList()
+	for i := 0; i < 10; i++ {
+		w.Scalar("item")
+	}
+	if buf.Len() == 0 {
+		t.Errorf("Expected a small buffer to spill before Flush")
+	}
+	w.EndList()
+	w.Flush()
+	if bytes.Count(buf.Bytes(), []byte("- item")) != 10 {
+		t.Errorf("Expected 10 items, got: %q", buf.String())
+	}
+}
+
+func TestWriter_ListOfObjectsOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BeginList()
+	w.BeginObject()
+	w.Key("a")
+	w.Scalar(1)
+	w.EndObject()
+	w.EndList()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buf.String() != "- a: 1" {
+		t.Errorf("Expected %q, got: %q", "- a: 1", buf.String())
+	}
+}
+
+func TestWriter_Objects(t *testing.T) {
+	first := NewOrderedMap()
+	first.Set("id", 1)
+	first.Set("name", "Alice")
+	second := NewOrderedMap()
+	second.Set("id", 2)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithMissingCell("-"))
+	w.BeginObject()
+	w.Key("users")
+	w.Objects([]*OrderedMap{first, second})
+	w.Key("none")
+	w.Objects(nil)
+	w.EndObject()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "users[2]{id,name}:\n  1,Alice\n  2,-\nnone: []"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, WithTableMinRows(3))
+	w.Objects([]*OrderedMap{first, second})
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = "- id: 1\n  name: Alice\n- id: 2"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}